@@ -0,0 +1,249 @@
+package agent_omni
+
+import (
+	"container/heap"
+	"context"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"sort"
+	"sync"
+	"time"
+)
+
+func (s *PtySession) SetupDiskUsage() {
+	scans := sync.Map{} // map[uint32]context.CancelFunc
+
+	// start disk usage scan
+	// request: [0x30] + uint32LE(reqId) + msgpack(DiskUsageRequest)
+	// response: [0x30] + uint32LE(reqId) + msgpack(DiskUsageReport), repeatedly until report.Done
+	s.Handlers[0x30] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid disk usage request")
+			return
+		}
+		idBytes := recv[1:5]
+		id := binary.LittleEndian.Uint32(idBytes)
+
+		send_report := func(report *biz.DiskUsageReport) {
+			reportBytes, _ := report.MarshalMsg(nil)
+			s.Write(utils.JoinBytes2(0x30, idBytes, reportBytes))
+		}
+
+		req := biz.DiskUsageRequest{}
+		if _, err := req.UnmarshalMsg(recv[5:]); err != nil {
+			send_report(&biz.DiskUsageReport{Done: true, Error: "bad request: " + err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithCancel(s.Ctx)
+		defer cancel()
+		if _, exists := scans.LoadOrStore(id, cancel); exists {
+			send_report(&biz.DiskUsageReport{Path: req.Path, Done: true, Error: "scan id already exists"})
+			return
+		}
+		defer scans.Delete(id)
+
+		disk_usage(ctx, &req, send_report)
+	}
+
+	// cancel disk usage scan
+	// request: [0x31] + uint32LE(reqId)
+	s.Handlers[0x31] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid disk usage request")
+			return
+		}
+		if cancel, ok := scans.Load(binary.LittleEndian.Uint32(recv[1:5])); ok {
+			cancel.(context.CancelFunc)()
+		}
+	}
+}
+
+// min-heap of files, by usage
+type du_top_heap []biz.DiskUsageEntry
+
+func (h du_top_heap) Len() int           { return len(h) }
+func (h du_top_heap) Less(i, j int) bool { return h[i].Usage < h[j].Usage }
+func (h du_top_heap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *du_top_heap) Push(x any)        { *h = append(*h, x.(biz.DiskUsageEntry)) }
+func (h *du_top_heap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+type du_scanner struct {
+	ctx    context.Context
+	req    *biz.DiskUsageRequest
+	report biz.DiskUsageReport
+	dirs   []*biz.DiskUsageEntry // Path and sub directories up to MaxDepth
+	top    du_top_heap
+	top_n  int
+	seen   map[[2]uint64]bool // hard links already counted. key: dev, inode
+	dev    uint64             // device of Path
+
+	on_progress   func(report *biz.DiskUsageReport)
+	interval      time.Duration
+	last_progress time.Time
+}
+
+// compute disk usage of req.Path. on_progress is called periodically with partial reports,
+// and at last with the final report (Done is true).
+func disk_usage(ctx context.Context, req *biz.DiskUsageRequest, on_progress func(report *biz.DiskUsageReport)) {
+	d := &du_scanner{
+		ctx:           ctx,
+		req:           req,
+		report:        biz.DiskUsageReport{Path: req.Path},
+		top_n:         int(req.TopN),
+		seen:          map[[2]uint64]bool{},
+		on_progress:   on_progress,
+		interval:      time.Duration(req.ProgressInterval) * time.Millisecond,
+		last_progress: time.Now(),
+	}
+	if d.top_n <= 0 {
+		d.top_n = 20
+	}
+	if d.interval <= 0 {
+		d.interval = time.Second
+	}
+
+	if fs, err := list_filesystems(); err == nil {
+		d.report.Filesystems = fs
+	}
+
+	defer func() {
+		d.report.Done = true
+		d.send_progress()
+	}()
+
+	info, err := os.Lstat(req.Path)
+	if err != nil {
+		d.report.Error = err.Error()
+		return
+	}
+
+	usage, dev, _, _ := file_usage(info)
+	d.dev = dev
+	root := &biz.DiskUsageEntry{Path: req.Path, IsDir: info.IsDir(), Size: info.Size(), Usage: usage}
+	d.dirs = append(d.dirs, root)
+
+	if !info.IsDir() {
+		d.report.ScannedFiles++
+		root.Files = 1
+		d.add_top(*root)
+		return
+	}
+
+	d.report.ScannedDirs++
+	d.send_progress() // let user know filesystems and the scan is started
+	d.report.Filesystems = nil
+	d.walk(req.Path, 0, []*biz.DiskUsageEntry{root})
+
+	if ctx.Err() != nil {
+		d.report.Error = "canceled"
+	}
+	if fs, err := list_filesystems(); err == nil {
+		d.report.Filesystems = fs
+	}
+}
+
+// walk a directory at depth. usage of every entry is added to all ancestors
+func (d *du_scanner) walk(path string, depth int, ancestors []*biz.DiskUsageEntry) {
+	dir, err := os.Open(path)
+	if err != nil {
+		d.report.Unreadable++
+		return
+	}
+	defer dir.Close()
+
+	for {
+		entries, err := dir.ReadDir(list_dir_batch_size)
+		for _, entry := range entries {
+			if d.ctx.Err() != nil {
+				return
+			}
+			if time.Since(d.last_progress) >= d.interval {
+				d.send_progress()
+			}
+
+			child_path := filepath.Join(path, entry.Name())
+			info, err := entry.Info()
+			if err != nil {
+				d.report.Unreadable++
+				continue
+			}
+
+			usage, dev, ino, nlink := file_usage(info)
+			if !info.IsDir() && nlink > 1 {
+				if d.seen[[2]uint64{dev, ino}] {
+					continue
+				}
+				d.seen[[2]uint64{dev, ino}] = true
+			}
+
+			for _, a := range ancestors {
+				a.Size += info.Size()
+				a.Usage += usage
+			}
+
+			if !info.IsDir() {
+				d.report.ScannedFiles++
+				for _, a := range ancestors {
+					a.Files++
+				}
+				d.add_top(biz.DiskUsageEntry{Path: child_path, Size: info.Size(), Usage: usage, Files: 1})
+				continue
+			}
+
+			d.report.ScannedDirs++
+			if d.req.OneFileSystem && dev != d.dev {
+				continue
+			}
+
+			child_ancestors := ancestors
+			if depth+1 <= int(d.req.MaxDepth) {
+				entry := &biz.DiskUsageEntry{Path: child_path, IsDir: true, Size: info.Size(), Usage: usage}
+				d.dirs = append(d.dirs, entry)
+				child_ancestors = append(ancestors[:len(ancestors):len(ancestors)], entry)
+			}
+			d.walk(child_path, depth+1, child_ancestors)
+		}
+
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			d.report.Unreadable++
+			return
+		}
+	}
+}
+
+func (d *du_scanner) add_top(entry biz.DiskUsageEntry) {
+	if len(d.top) < d.top_n {
+		heap.Push(&d.top, entry)
+	} else if entry.Usage > d.top[0].Usage {
+		d.top[0] = entry
+		heap.Fix(&d.top, 0)
+	}
+}
+
+func (d *du_scanner) send_progress() {
+	d.last_progress = time.Now()
+
+	d.report.Entries = make([]biz.DiskUsageEntry, 0, len(d.dirs))
+	for _, e := range d.dirs {
+		d.report.Entries = append(d.report.Entries, *e)
+	}
+	sort.Slice(d.report.Entries, func(i, j int) bool { return d.report.Entries[i].Path < d.report.Entries[j].Path })
+
+	d.report.Top = append(make([]biz.DiskUsageEntry, 0, len(d.top)), d.top...)
+	sort.Slice(d.report.Top, func(i, j int) bool { return d.report.Top[i].Usage > d.report.Top[j].Usage })
+
+	d.on_progress(&d.report)
+}
@@ -0,0 +1,85 @@
+package agent_omni
+
+import (
+	"bufio"
+	"os"
+	"remote-agent/biz"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// allocated size on disk, device, inode and link count of a file
+func file_usage(info os.FileInfo) (usage int64, dev uint64, ino uint64, nlink uint64) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.Size(), 0, 0, 1
+	}
+	return st.Blocks * 512, uint64(st.Dev), st.Ino, uint64(st.Nlink)
+}
+
+// statfs of every mounted filesystem. pseudo filesystems (zero blocks) are skipped
+func list_filesystems() ([]biz.FsStat, error) {
+	file, err := os.Open("/proc/self/mounts")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	ans := make([]biz.FsStat, 0)
+	index := map[string]int{} // mount point -> index in ans. later mounts hide earlier ones
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+
+		mount_point := unescape_mount_field(fields[1])
+		st := syscall.Statfs_t{}
+		if err := syscall.Statfs(mount_point, &st); err != nil || st.Blocks == 0 {
+			continue
+		}
+
+		bsize := uint64(st.Frsize)
+		if bsize == 0 {
+			bsize = uint64(st.Bsize)
+		}
+		fs := biz.FsStat{
+			Device:     unescape_mount_field(fields[0]),
+			MountPoint: mount_point,
+			FsType:     fields[2],
+			Total:      st.Blocks * bsize,
+			Free:       st.Bfree * bsize,
+			Avail:      st.Bavail * bsize,
+			Inodes:     st.Files,
+			InodesFree: st.Ffree,
+		}
+		if i, ok := index[mount_point]; ok {
+			ans[i] = fs
+		} else {
+			index[mount_point] = len(ans)
+			ans = append(ans, fs)
+		}
+	}
+	return ans, scanner.Err()
+}
+
+// /proc/self/mounts escapes space, tab, newline and backslash as octal, like "\040"
+func unescape_mount_field(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				sb.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
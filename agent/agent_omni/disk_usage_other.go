@@ -0,0 +1,17 @@
+//go:build !linux
+
+package agent_omni
+
+import (
+	"errors"
+	"os"
+	"remote-agent/biz"
+)
+
+func file_usage(info os.FileInfo) (usage int64, dev uint64, ino uint64, nlink uint64) {
+	return info.Size(), 0, 0, 1
+}
+
+func list_filesystems() ([]biz.FsStat, error) {
+	return nil, errors.New("filesystem stats are not supported on this platform")
+}
@@ -0,0 +1,66 @@
+package agent_omni_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"testing"
+)
+
+func TestDiskUsage(t *testing.T) {
+	dir := t.TempDir()
+	for _, err := range []error{
+		os.MkdirAll(filepath.Join(dir, "a", "deep", "deeper"), 0755),
+		os.MkdirAll(filepath.Join(dir, "b"), 0755),
+		os.WriteFile(filepath.Join(dir, "a", "deep", "deeper", "big"), make([]byte, 300000), 0644),
+		os.WriteFile(filepath.Join(dir, "a", "small"), make([]byte, 1000), 0644),
+		os.WriteFile(filepath.Join(dir, "b", "medium"), make([]byte, 50000), 0644),
+		os.Link(filepath.Join(dir, "b", "medium"), filepath.Join(dir, "b", "medium-link")),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	idBytes := []byte{0xde, 0xad, 0xbe, 0xef}
+	req := biz.DiskUsageRequest{Path: dir, MaxDepth: 1, TopN: 2}
+	reqBytes, _ := req.MarshalMsg(nil)
+	ts.ChToAgent <- utils.JoinBytes2(0x30, idBytes, reqBytes)
+
+	// partial reports come first, the last one is done
+	report := biz.DiskUsageReport{}
+	for !report.Done {
+		recv := readWithTimeout(ts.ChFromAgent)
+		if len(recv) < 5 || bytes2hex(recv[:5]) != "30deadbeef" {
+			t.Fatalf("did not recv disk usage report: %s", bytes2hex(recv))
+		}
+		report = biz.DiskUsageReport{}
+		if _, err := report.UnmarshalMsg(recv[5:]); err != nil {
+			t.Fatalf("failed to unmarshal disk usage report: %s", err.Error())
+		}
+	}
+
+	Assert(t, report.Error == "", "no error: "+report.Error)
+	Assert(t, report.ScannedFiles == 3, fmt.Sprintf("hard link counted once: %d", report.ScannedFiles))
+	Assert(t, report.ScannedDirs == 5, fmt.Sprintf("scanned dirs: %d", report.ScannedDirs))
+
+	sizes := map[string]int64{}
+	for _, e := range report.Entries {
+		rel, _ := filepath.Rel(dir, e.Path)
+		sizes[rel] = e.Size
+	}
+	Assert(t, len(sizes) == 3, fmt.Sprintf("root and 2 sub directories within depth 1: %v", sizes))
+	Assert(t, sizes["a"] >= 301000 && sizes["a"] < 320000, fmt.Sprintf("size of a: %d", sizes["a"]))
+	Assert(t, sizes["b"] >= 50000 && sizes["b"] < 60000, fmt.Sprintf("size of b: %d", sizes["b"]))
+	Assert(t, sizes["."] >= sizes["a"]+sizes["b"], "root includes sub directories")
+
+	Assert(t, len(report.Top) == 2, "top 2 files")
+	Assert(t, filepath.Base(report.Top[0].Path) == "big", "largest file first: "+report.Top[0].Path)
+	Assert(t, filepath.Base(report.Top[1].Path) != "small", "small file not in top 2")
+}
@@ -69,21 +69,39 @@ func (s *PtySession) SetupFileTransfer() {
 		var msgpData []byte
 		msgpData = msgp.AppendArrayHeader(msgpData, uint32(len(entries)))
 		for _, entry := range entries {
-			info, err := entry.Info()
-			if err != nil {
-				continue
-			}
-			item := biz.FileInfo{
-				Path:  filepath.Join(path, entry.Name()),
-				Size:  info.Size(),
-				Mode:  uint32(info.Mode()),
-				Mtime: info.ModTime().Unix(),
+			item := biz.FileInfo{Path: filepath.Join(path, entry.Name())}
+			if info, err := entry.Info(); err != nil {
+				item.Error = err.Error()
+			} else {
+				item = make_file_info(item.Path, info)
 			}
 			msgpData, _ = item.MarshalMsg(msgpData)
 		}
 		s.Write(utils.JoinBytes2(0x13, pathLen, pathBytes, msgpData))
 	}
 
+	// list directory, paginated and sorted
+	// request: [0x16] + uint32LE(reqId) + msgpack(ListDirRequest)
+	// response: [0x16] + uint32LE(reqId) + msgpack(ListDirResponse)
+	s.Handlers[0x16] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid list directory request")
+			return
+		}
+		idBytes := recv[1:5]
+
+		var res biz.ListDirResponse
+		req := biz.ListDirRequest{}
+		if _, err := req.UnmarshalMsg(recv[5:]); err != nil {
+			res.Error = "bad request: " + err.Error()
+		} else {
+			res = list_directory(&req)
+		}
+
+		resBytes, _ := res.MarshalMsg(nil)
+		s.Write(utils.JoinBytes2(0x16, idBytes, resBytes))
+	}
+
 	// delete file or directory
 	s.Handlers[0x14] = func(recv []byte) {
 		path := string(recv[1:])
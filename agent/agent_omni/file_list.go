@@ -0,0 +1,199 @@
+package agent_omni
+
+import (
+	"container/heap"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"remote-agent/biz"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	list_dir_default_limit = 1000
+	list_dir_batch_size    = 1024
+)
+
+// one directory entry, with the key it is sorted by
+type list_dir_item struct {
+	name string
+	key  int64 // size or mtime. always 0 when sorting by name
+	info biz.FileInfo
+}
+
+// keeps the first N items of the requested order. the top of heap is the "last" item
+type list_dir_heap struct {
+	items []list_dir_item
+	less  func(a, b *list_dir_item) bool
+}
+
+func (h *list_dir_heap) Len() int           { return len(h.items) }
+func (h *list_dir_heap) Less(i, j int) bool { return h.less(&h.items[j], &h.items[i]) }
+func (h *list_dir_heap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *list_dir_heap) Push(x any)         { h.items = append(h.items, x.(list_dir_item)) }
+func (h *list_dir_heap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+// cursor format: "<key>/<name>". names never contain "/"
+func encode_list_dir_cursor(item *list_dir_item) string {
+	return strconv.FormatInt(item.key, 10) + "/" + item.name
+}
+
+func decode_list_dir_cursor(cursor string) (*list_dir_item, error) {
+	key, name, ok := strings.Cut(cursor, "/")
+	if !ok {
+		return nil, fmt.Errorf("bad cursor: %q", cursor)
+	}
+	k, err := strconv.ParseInt(key, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("bad cursor: %q", cursor)
+	}
+	return &list_dir_item{name: name, key: k}, nil
+}
+
+func make_file_info(path string, info os.FileInfo) biz.FileInfo {
+	return biz.FileInfo{
+		Path:  path,
+		Size:  info.Size(),
+		Mode:  uint32(info.Mode()),
+		Mtime: info.ModTime().Unix(),
+	}
+}
+
+// list a directory page by page.
+//
+// the directory is scanned in batches, and only one page of entries is kept in memory,
+// so huge directories won't blow up the agent or the message size.
+// entries that cannot be stat-ed are reported with FileInfo.Error
+func list_directory(req *biz.ListDirRequest) (res biz.ListDirResponse) {
+	res.Path = req.Path
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = list_dir_default_limit
+	}
+
+	var key_of func(info os.FileInfo) int64
+	switch req.SortBy {
+	case "", "name":
+	case "size":
+		key_of = func(info os.FileInfo) int64 { return info.Size() }
+	case "mtime":
+		key_of = func(info os.FileInfo) int64 { return info.ModTime().Unix() }
+	default:
+		res.Error = "unknown sort_by: " + req.SortBy
+		return
+	}
+
+	less := func(a, b *list_dir_item) bool {
+		if req.Desc {
+			a, b = b, a
+		}
+		if a.key != b.key {
+			return a.key < b.key
+		}
+		return a.name < b.name
+	}
+
+	var after *list_dir_item
+	if req.Cursor != "" {
+		var err error
+		if after, err = decode_list_dir_cursor(req.Cursor); err != nil {
+			res.Error = err.Error()
+			return
+		}
+	}
+
+	dir, err := os.Open(req.Path)
+	if err != nil {
+		res.Error = err.Error()
+		return
+	}
+	defer dir.Close()
+
+	page := &list_dir_heap{less: less}
+	remaining := 0 // count of matched entries after the cursor
+
+	add := func(item list_dir_item) {
+		res.Total++
+		if req.CountOnly || (after != nil && !less(after, &item)) {
+			return
+		}
+		remaining++
+		if page.Len() < limit {
+			heap.Push(page, item)
+		} else if less(&item, &page.items[0]) {
+			page.items[0] = item
+			heap.Fix(page, 0)
+		}
+	}
+
+	for {
+		// sorting by name doesn't need stat, so only names are read while scanning
+		var names []string
+		var entries []os.DirEntry
+		if key_of == nil || req.CountOnly {
+			names, err = dir.Readdirnames(list_dir_batch_size)
+		} else {
+			entries, err = dir.ReadDir(list_dir_batch_size)
+		}
+
+		for _, name := range names {
+			if req.ShowHidden || !strings.HasPrefix(name, ".") {
+				add(list_dir_item{name: name})
+			}
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !req.ShowHidden && strings.HasPrefix(name, ".") {
+				continue
+			}
+			item := list_dir_item{name: name}
+			item.info.Path = filepath.Join(req.Path, name)
+			if info, stat_err := entry.Info(); stat_err != nil {
+				item.info.Error = stat_err.Error()
+			} else {
+				item.info = make_file_info(item.info.Path, info)
+				item.key = key_of(info)
+			}
+			add(item)
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			res.Error = err.Error()
+			return
+		}
+	}
+
+	items := page.items
+	sort.Slice(items, func(i, j int) bool { return less(&items[i], &items[j]) })
+
+	res.Entries = make([]biz.FileInfo, 0, len(items))
+	for i := range items {
+		info := items[i].info
+		if info.Path == "" {
+			// scanned by name only -- stat it now
+			info.Path = filepath.Join(req.Path, items[i].name)
+			if stat, err := os.Lstat(info.Path); err != nil {
+				info.Error = err.Error()
+			} else {
+				info = make_file_info(info.Path, stat)
+			}
+		}
+		res.Entries = append(res.Entries, info)
+	}
+
+	if remaining > len(items) {
+		res.NextCursor = encode_list_dir_cursor(&items[len(items)-1])
+	}
+	return
+}
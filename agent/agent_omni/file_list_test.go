@@ -0,0 +1,94 @@
+package agent_omni_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"testing"
+	"time"
+)
+
+func listDir(t *testing.T, ts *TestSession, req biz.ListDirRequest) biz.ListDirResponse {
+	idBytes := []byte{0xde, 0xad, 0xbe, 0xef}
+	reqBytes, _ := req.MarshalMsg(nil)
+	ts.ChToAgent <- utils.JoinBytes2(0x16, idBytes, reqBytes)
+
+	recv := readWithTimeout(ts.ChFromAgent)
+	if len(recv) < 5 || bytes2hex(recv[:5]) != "16deadbeef" {
+		t.Fatalf("did not recv list directory response: %s", bytes2hex(recv))
+	}
+	res := biz.ListDirResponse{}
+	if _, err := res.UnmarshalMsg(recv[5:]); err != nil {
+		t.Fatalf("failed to unmarshal list directory response: %s", err.Error())
+	}
+	return res
+}
+
+func names(entries []biz.FileInfo) (out []string) {
+	for _, e := range entries {
+		out = append(out, filepath.Base(e.Path))
+	}
+	return
+}
+
+func TestListDirPaginated(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 25; i++ {
+		name := filepath.Join(dir, fmt.Sprintf("f%02d", i))
+		if err := os.WriteFile(name, make([]byte, 100-i), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Unix(int64(1000000+i*10), 0)
+		if err := os.Chtimes(name, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".hidden"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	// 1. walk all pages sorted by name
+	all := []string{}
+	cursor := ""
+	pages := 0
+	for {
+		res := listDir(t, ts, biz.ListDirRequest{Path: dir, Limit: 10, Cursor: cursor})
+		Assert(t, res.Error == "", "no error: "+res.Error)
+		Assert(t, res.Total == 25, "total excludes hidden file")
+		all = append(all, names(res.Entries)...)
+		pages++
+		if res.NextCursor == "" {
+			break
+		}
+		cursor = res.NextCursor
+	}
+	Assert(t, pages == 3, "3 pages")
+	Assert(t, len(all) == 25 && all[0] == "f00" && all[24] == "f24", fmt.Sprintf("all entries in order: %v", all))
+
+	// 2. sorted by size, descending
+	res := listDir(t, ts, biz.ListDirRequest{Path: dir, Limit: 3, SortBy: "size", Desc: true})
+	Assert(t, fmt.Sprint(names(res.Entries)) == "[f00 f01 f02]", fmt.Sprintf("size desc: %v", names(res.Entries)))
+	res = listDir(t, ts, biz.ListDirRequest{Path: dir, Limit: 3, SortBy: "size", Desc: true, Cursor: res.NextCursor})
+	Assert(t, fmt.Sprint(names(res.Entries)) == "[f03 f04 f05]", fmt.Sprintf("size desc page 2: %v", names(res.Entries)))
+
+	// 3. sorted by mtime, with hidden files
+	res = listDir(t, ts, biz.ListDirRequest{Path: dir, Limit: 2, SortBy: "mtime", ShowHidden: true, Desc: true})
+	Assert(t, res.Total == 26, "total includes hidden file")
+	Assert(t, fmt.Sprint(names(res.Entries)) == "[.hidden f24]", fmt.Sprintf("mtime desc: %v", names(res.Entries)))
+
+	// 4. count only
+	res = listDir(t, ts, biz.ListDirRequest{Path: dir, CountOnly: true, ShowHidden: true})
+	Assert(t, res.Total == 26 && len(res.Entries) == 0 && res.NextCursor == "", "count only")
+
+	// 5. errors
+	res = listDir(t, ts, biz.ListDirRequest{Path: filepath.Join(dir, "not-exists")})
+	Assert(t, res.Error != "", "error for missing directory")
+	res = listDir(t, ts, biz.ListDirRequest{Path: dir, SortBy: "color"})
+	Assert(t, res.Error != "", "error for bad sort_by")
+}
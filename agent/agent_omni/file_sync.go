@@ -0,0 +1,185 @@
+package agent_omni
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"time"
+)
+
+const (
+	block_sum_min_size = 4 * 1024
+	block_sum_max_size = 16 * 1024 * 1024
+)
+
+// handlers for directory sync. see client/sync.go
+func (s *PtySession) SetupFileSync() {
+	// walk directory tree
+	// request: [0x17] + uint32LE(reqId) + msgpack(SyncTreeRequest)
+	// response: [0x17] + uint32LE(reqId) + msgpack(SyncTreeResponse)
+	s.Handlers[0x17] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid sync tree request")
+			return
+		}
+		idBytes := recv[1:5]
+
+		var res biz.SyncTreeResponse
+		req := biz.SyncTreeRequest{}
+		if _, err := req.UnmarshalMsg(recv[5:]); err != nil {
+			res.Error = "bad request: " + err.Error()
+		} else {
+			res = walk_sync_tree(req.Path)
+		}
+
+		resBytes, _ := res.MarshalMsg(nil)
+		s.Write(utils.JoinBytes2(0x17, idBytes, resBytes))
+	}
+
+	// checksum of each block of a file
+	// request: [0x18] + uint32LE(reqId) + msgpack(BlockSumRequest)
+	// response: [0x18] + uint32LE(reqId) + msgpack(BlockSumResponse)
+	s.Handlers[0x18] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid block checksum request")
+			return
+		}
+		idBytes := recv[1:5]
+
+		var res biz.BlockSumResponse
+		req := biz.BlockSumRequest{}
+		if _, err := req.UnmarshalMsg(recv[5:]); err != nil {
+			res.Error = "bad request: " + err.Error()
+		} else if req.BlockSize < block_sum_min_size || req.BlockSize > block_sum_max_size {
+			res.Error = "bad block size"
+		} else if size, sums, err := block_sums(req.Path, req.BlockSize); err != nil {
+			res.Error = err.Error()
+		} else {
+			res.Size = size
+			res.Sums = sums
+		}
+
+		resBytes, _ := res.MarshalMsg(nil)
+		s.Write(utils.JoinBytes2(0x18, idBytes, resBytes))
+	}
+
+	// set mode and mtime
+	// request: [0x19] + uint32LE(reqId) + msgpack(SetAttrRequest)
+	// response: [0x19] + uint32LE(reqId) + error message (empty if succeeded)
+	s.Handlers[0x19] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid set attributes request")
+			return
+		}
+		idBytes := recv[1:5]
+
+		req := biz.SetAttrRequest{}
+		var err error
+		if _, err = req.UnmarshalMsg(recv[5:]); err == nil {
+			err = set_file_attr(&req)
+		}
+
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		s.Write(utils.JoinBytes2(0x19, idBytes, []byte(msg)))
+	}
+}
+
+// list all entries under root, without following symlinks.
+// entries that cannot be read are reported with FileInfo.Error
+func walk_sync_tree(root string) (res biz.SyncTreeResponse) {
+	if info, err := os.Stat(root); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			res.NotExist = true
+		} else {
+			res.Error = err.Error()
+		}
+		return
+	} else if !info.IsDir() {
+		// otherwise it looks like an empty directory, and a sync with deletion would wipe the other side
+		res.Error = root + " is not a directory"
+		return
+	}
+	// a symlink root (like /srv/app/current) is walked into
+	if real, err := filepath.EvalSymlinks(root); err == nil {
+		root = real
+	}
+
+	res.Entries = []biz.FileInfo{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if path == root {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		item := biz.FileInfo{Path: filepath.ToSlash(rel)}
+
+		if err != nil {
+			// a directory that cannot be read. it's still reported, with the error
+			item.Error = err.Error()
+			if info, err := d.Info(); err == nil {
+				item.Mode = uint32(info.Mode())
+			}
+			res.Entries = append(res.Entries, item)
+			return nil
+		}
+
+		if info, err := d.Info(); err != nil {
+			item.Error = err.Error()
+		} else {
+			item = make_file_info(item.Path, info)
+		}
+		res.Entries = append(res.Entries, item)
+		return nil
+	})
+	if err != nil {
+		res.Error = err.Error()
+	}
+	return
+}
+
+func block_sums(path string, block_size int64) (int64, [][]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer file.Close()
+
+	sums := [][]byte{}
+	size := int64(0)
+	for {
+		h := sha256.New()
+		n, err := io.CopyN(h, file, block_size)
+		if n > 0 {
+			sums = append(sums, h.Sum(nil))
+			size += n
+		}
+		if err == io.EOF {
+			return size, sums, nil
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+}
+
+func set_file_attr(req *biz.SetAttrRequest) error {
+	if req.Mode != 0 {
+		if err := os.Chmod(req.Path, fs.FileMode(req.Mode).Perm()); err != nil {
+			return err
+		}
+	}
+	if req.Mtime != 0 {
+		mtime := time.Unix(req.Mtime, 0)
+		if err := os.Chtimes(req.Path, mtime, mtime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package agent_omni_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"testing"
+	"time"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// send a request with id, and return the response after the id
+func syncCall(t *testing.T, ts *TestSession, op byte, req msgp.Marshaler) []byte {
+	t.Helper()
+	idBytes := []byte{0x01, 0x02, 0x03, 0x04}
+	reqBytes, _ := req.MarshalMsg(nil)
+	ts.ChToAgent <- utils.JoinBytes2(op, idBytes, reqBytes)
+
+	recv := readWithTimeout(ts.ChFromAgent)
+	if len(recv) < 5 || recv[0] != op || !bytes.Equal(recv[1:5], idBytes) {
+		t.Fatalf("did not recv response of 0x%02x: %s", op, bytes2hex(recv))
+	}
+	return recv[5:]
+}
+
+func TestSyncTree(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "sub", "deep"), 0755)
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0644)
+	os.WriteFile(filepath.Join(dir, "sub", "deep", "b.bin"), make([]byte, 1000), 0600)
+	os.Symlink("a.txt", filepath.Join(dir, "link"))
+
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	res := biz.SyncTreeResponse{}
+	if _, err := res.UnmarshalMsg(syncCall(t, ts, 0x17, &biz.SyncTreeRequest{Path: dir})); err != nil {
+		t.Fatal(err)
+	}
+	if res.Error != "" || res.NotExist {
+		t.Fatalf("unexpected response: %+v", res)
+	}
+
+	got := map[string]biz.FileInfo{}
+	for _, e := range res.Entries {
+		got[e.Path] = e
+	}
+	if len(got) != 5 {
+		t.Errorf("expected 5 entries, got %+v", res.Entries)
+	}
+	if e := got["sub/deep/b.bin"]; e.Size != 1000 || fs.FileMode(e.Mode).Perm() != 0600 {
+		t.Errorf("bad entry of b.bin: %+v", e)
+	}
+	if e := got["sub/deep"]; !fs.FileMode(e.Mode).IsDir() {
+		t.Errorf("sub/deep should be a dir: %+v", e)
+	}
+	if e := got["link"]; fs.FileMode(e.Mode)&fs.ModeSymlink == 0 {
+		t.Errorf("link should not be followed: %+v", e)
+	}
+
+	// not exist
+	res = biz.SyncTreeResponse{}
+	res.UnmarshalMsg(syncCall(t, ts, 0x17, &biz.SyncTreeRequest{Path: filepath.Join(dir, "nope")}))
+	if !res.NotExist || res.Error != "" {
+		t.Errorf("expected NotExist: %+v", res)
+	}
+}
+
+func TestBlockSumAndSetAttr(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data")
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1000) // 16000 bytes
+	os.WriteFile(path, data, 0644)
+
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	res := biz.BlockSumResponse{}
+	res.UnmarshalMsg(syncCall(t, ts, 0x18, &biz.BlockSumRequest{Path: path, BlockSize: 4096}))
+	if res.Error != "" || res.Size != 16000 || len(res.Sums) != 4 {
+		t.Fatalf("unexpected response: size=%d sums=%d error=%q", res.Size, len(res.Sums), res.Error)
+	}
+	last := sha256.Sum256(data[3*4096:])
+	if !bytes.Equal(res.Sums[3], last[:]) {
+		t.Errorf("bad checksum of last block")
+	}
+
+	res = biz.BlockSumResponse{}
+	res.UnmarshalMsg(syncCall(t, ts, 0x18, &biz.BlockSumRequest{Path: path, BlockSize: 1}))
+	if res.Error == "" {
+		t.Errorf("expected error of bad block size")
+	}
+
+	// set attributes
+	mtime := time.Unix(1700000000, 0)
+	msg := syncCall(t, ts, 0x19, &biz.SetAttrRequest{Path: path, Mode: 0600, Mtime: mtime.Unix()})
+	if len(msg) != 0 {
+		t.Fatalf("set attributes failed: %s", msg)
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 || !info.ModTime().Equal(mtime) {
+		t.Errorf("attributes not set: %v %v", info.Mode(), info.ModTime())
+	}
+
+	msg = syncCall(t, ts, 0x19, &biz.SetAttrRequest{Path: filepath.Join(dir, "nope"), Mode: 0600})
+	if len(msg) == 0 {
+		t.Errorf("expected error for missing file")
+	}
+}
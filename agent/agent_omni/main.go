@@ -5,13 +5,33 @@ import (
 	"remote-agent/agent/agent_common"
 	"remote-agent/biz"
 	"remote-agent/utils"
+	"strings"
 )
 
+// features of this agent, reported in the hello package (0xfe).
+// a server shall not use a feature unless the agent reports it.
+var Features = []string{
+	"body_stream", // http request body streamed as 0x21 packages, ended by 0x24
+	"window",      // flow control of proxy channels (0x25), and 0x26 channel opening
+	"udp",         // "udp" network of 0x26. each 0x21 package is one datagram
+	"reverse",     // reverse forwarding: listen on agent side (0x28), accepted connections (0x29)
+	"sync",        // directory sync: tree walk (0x17), block checksums (0x18), set attributes (0x19)
+	"unix",        // "unix" network of 0x26 and 0x28: unix domain socket path as address
+	"metrics",     // host and agent metrics snapshot (0x32)
+	"process",     // process list (0x33) and signals (0x34)
+	"service",     // systemd units: list (0x35), actions (0x36) and journal (0x37)
+	"half_close",  // 0x24 on a tcp or unix stream channel closes the writing side of the connection
+}
+
 type PtySession struct {
 	Ctx context.Context
 	Ws  *utils.RWChan
 
 	Handlers []func(recv []byte) // length of 255
+
+	// handlers which run on the read loop, in the order of packages.
+	// they must not block -- start a goroutine for slow work
+	inline [256]bool
 }
 
 func (s *PtySession) WriteDebugMessage(data string) {
@@ -38,18 +58,42 @@ func Run(task *biz.AgentNotify) {
 		Handlers: make([]func(recv []byte), 256),
 	}
 
+	session.SetupCommon()
 	session.SetupPty()
 	session.SetupFileTransfer()
+	session.SetupFileSync()
 	session.SetupProxy()
+	session.SetupDiskUsage()
+	session.SetupMetrics()
+	session.SetupProcess()
+	session.SetupService()
 
 	session.Run()
 	cancel()
 }
 
+func (s *PtySession) SetupCommon() {
+	// listener: debug message
+	s.Handlers[0xff] = func(recv []byte) {
+		s.WriteDebugMessage(string(recv[1:]))
+	}
+
+	// listener: hello. reply with features
+	s.Handlers[0xfe] = func(recv []byte) {
+		s.Write(utils.PrependBytes([]byte{0xfe}, []byte(strings.Join(Features, ","))))
+	}
+	s.inline[0xfe] = true // so the reply is sent before any later package is handled
+}
+
 func (s *PtySession) Run() {
 	for recv := range s.Ws.Read {
 		handler := s.Handlers[recv[0]]
-		if handler != nil {
+		if handler == nil {
+			continue
+		}
+		if s.inline[recv[0]] {
+			handler(recv)
+		} else {
 			go handler(recv)
 		}
 	}
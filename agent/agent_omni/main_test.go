@@ -62,6 +62,14 @@ func makeTestSession() (ts *TestSession) {
 
 // usage: go ts.Run()
 func (ts *TestSession) Run() {
+	ts.Session.SetupCommon()
+	ts.Session.SetupPty()
+	ts.Session.SetupFileTransfer()
+	ts.Session.SetupFileSync()
 	ts.Session.SetupProxy()
+	ts.Session.SetupDiskUsage()
+	ts.Session.SetupMetrics()
+	ts.Session.SetupProcess()
+	ts.Session.SetupService()
 	ts.Session.Run()
 }
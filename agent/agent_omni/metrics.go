@@ -0,0 +1,155 @@
+package agent_omni
+
+import (
+	"os"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"runtime"
+	"sort"
+	"time"
+)
+
+var agent_start_time = time.Now()
+
+// limits of requests, so a client can't hold a handler for long, or ask for a huge list
+var (
+	maxSample = 5 * time.Second
+	maxTopN   = 100
+)
+
+func (s *PtySession) SetupMetrics() {
+	// metrics snapshot
+	// request: [0x32] + uint32LE(reqId) + msgpack(AgentMetricsRequest)
+	// response: [0x32] + uint32LE(reqId) + msgpack(AgentMetrics)
+	s.Handlers[0x32] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid metrics request")
+			return
+		}
+		idBytes := recv[1:5]
+
+		req := biz.AgentMetricsRequest{}
+		if _, err := req.UnmarshalMsg(recv[5:]); err != nil {
+			s.WriteDebugMessage("invalid metrics request: " + err.Error())
+			return
+		}
+
+		m := collect_metrics(&req)
+		resp, _ := m.MarshalMsg(nil)
+		s.Write(utils.JoinBytes2(0x32, idBytes, resp))
+	}
+}
+
+func collect_metrics(req *biz.AgentMetricsRequest) *biz.AgentMetrics {
+	top_n := int(req.TopN)
+	if top_n <= 0 {
+		top_n = 10
+	}
+	top_n = min(top_n, maxTopN)
+	sample := time.Duration(req.SampleMs) * time.Millisecond
+	if sample <= 0 {
+		sample = 250 * time.Millisecond
+	}
+	sample = min(sample, maxSample)
+
+	m := &biz.AgentMetrics{
+		OS:     runtime.GOOS,
+		Arch:   runtime.GOARCH,
+		NumCPU: int32(runtime.NumCPU()),
+	}
+	m.Hostname, _ = os.Hostname()
+
+	add_error := func(part string, err error) {
+		if err != nil {
+			m.Errors = append(m.Errors, part+": "+err.Error())
+		}
+	}
+
+	// CPU usage of host and processes, measured over the sample interval
+	cpu_before, err := read_cpu_times()
+	add_error("cpu", err)
+	procs_before, err := read_processes()
+	add_error("processes", err)
+	time.Sleep(sample)
+	cpu_after, _ := read_cpu_times()
+	procs_after, _ := read_processes()
+
+	if total := cpu_after.total - cpu_before.total; total > 0 {
+		m.CPUPercent = float64(total-(cpu_after.idle-cpu_before.idle)) / float64(total) * 100
+	}
+	m.Processes = top_processes(procs_before, procs_after, sample, top_n)
+
+	m.Load1, m.Load5, m.Load15, err = read_load_avg()
+	add_error("load", err)
+	add_error("memory", read_memory(m))
+	m.Uptime, err = read_uptime()
+	add_error("uptime", err)
+	m.Network, err = read_net_stats()
+	add_error("network", err)
+	m.Filesystems, err = list_filesystems()
+	add_error("filesystems", err)
+	if m.Filesystems == nil {
+		m.Filesystems = []biz.FsStat{}
+	}
+	if m.Network == nil {
+		m.Network = []biz.NetStat{}
+	}
+
+	mem := runtime.MemStats{}
+	runtime.ReadMemStats(&mem)
+	m.Agent = biz.AgentRuntime{
+		Version:    biz.Version,
+		Pid:        int32(os.Getpid()),
+		Uptime:     int64(time.Since(agent_start_time).Seconds()),
+		Goroutines: int32(runtime.NumGoroutine()),
+		HeapAlloc:  mem.HeapAlloc,
+		Sys:        mem.Sys,
+	}
+
+	m.Time = time.Now().UnixMilli()
+	return m
+}
+
+// busy and idle time of all CPUs, in clock ticks
+type cpu_times struct {
+	total uint64
+	idle  uint64
+}
+
+// a process sampled at some time
+type proc_sample struct {
+	name        string
+	ppid        int32
+	state       string
+	threads     int32
+	ticks       uint64 // user + system CPU time, in clock ticks
+	start_ticks uint64 // start time after boot, in clock ticks
+	rss         uint64 // in bytes
+}
+
+// processes with most CPU time between two samples
+func top_processes(before, after map[int32]proc_sample, interval time.Duration, top_n int) []biz.ProcessStat {
+	list := make([]biz.ProcessStat, 0, len(after))
+	for pid, p := range after {
+		ticks := uint64(0)
+		if b, ok := before[pid]; ok && p.ticks >= b.ticks {
+			ticks = p.ticks - b.ticks
+		}
+		list = append(list, biz.ProcessStat{
+			Pid:        pid,
+			Name:       p.name,
+			CPUPercent: float64(ticks) / clock_ticks_per_second / interval.Seconds() * 100,
+			RSS:        p.rss,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CPUPercent != list[j].CPUPercent {
+			return list[i].CPUPercent > list[j].CPUPercent
+		}
+		return list[i].RSS > list[j].RSS
+	})
+	if len(list) > top_n {
+		list = list[:top_n]
+	}
+	return list
+}
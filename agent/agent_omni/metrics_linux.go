@@ -0,0 +1,227 @@
+package agent_omni
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"remote-agent/biz"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// USER_HZ, the unit of CPU times in /proc. it's 100 on all Linux platforms
+const clock_ticks_per_second = 100
+
+// first line of /proc/stat: "cpu  user nice system idle iowait irq softirq steal guest guest_nice"
+func read_cpu_times() (cpu_times, error) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return cpu_times{}, err
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	fields := strings.Fields(line)
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return cpu_times{}, errors.New("bad /proc/stat")
+	}
+
+	ans := cpu_times{}
+	for i, f := range fields[1:] {
+		if i >= 8 {
+			break // guest time is already in user time
+		}
+		n, _ := strconv.ParseUint(f, 10, 64)
+		ans.total += n
+		if i == 3 || i == 4 { // idle, iowait
+			ans.idle += n
+		}
+	}
+	return ans, nil
+}
+
+// CPU times and memory of all processes, by pid
+func read_processes() (map[int32]proc_sample, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	page_size := uint64(os.Getpagesize())
+	ans := make(map[int32]proc_sample, len(entries))
+	for _, entry := range entries {
+		pid, err := strconv.ParseInt(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		if p, ok := read_process(int32(pid), page_size); ok {
+			ans[int32(pid)] = p
+		}
+	}
+	return ans, nil
+}
+
+// /proc/<pid>/stat: "pid (comm) state ppid ...". comm may contain spaces and parentheses
+func read_process(pid int32, page_size uint64) (proc_sample, bool) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/stat")
+	if err != nil {
+		return proc_sample{}, false // process has exited
+	}
+	s := string(data)
+	name_from, name_to := strings.IndexByte(s, '('), strings.LastIndexByte(s, ')')
+	if name_from < 0 || name_to < name_from {
+		return proc_sample{}, false
+	}
+	fields := strings.Fields(s[name_to+1:]) // fields[0] is state, the 3rd field of the line
+	if len(fields) < 22 {
+		return proc_sample{}, false
+	}
+
+	ppid, _ := strconv.ParseInt(fields[1], 10, 32)
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	threads, _ := strconv.ParseInt(fields[17], 10, 32)
+	start_ticks, _ := strconv.ParseUint(fields[19], 10, 64)
+	rss, _ := strconv.ParseInt(fields[21], 10, 64)
+	if rss < 0 {
+		rss = 0
+	}
+	return proc_sample{
+		name:        s[name_from+1 : name_to],
+		ppid:        int32(ppid),
+		state:       fields[0],
+		threads:     int32(threads),
+		ticks:       utime + stime,
+		start_ticks: start_ticks,
+		rss:         uint64(rss) * page_size,
+	}, true
+}
+
+func read_load_avg() (load1, load5, load15 float64, err error) {
+	data, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return 0, 0, 0, errors.New("bad /proc/loadavg")
+	}
+	load1, _ = strconv.ParseFloat(fields[0], 64)
+	load5, _ = strconv.ParseFloat(fields[1], 64)
+	load15, _ = strconv.ParseFloat(fields[2], 64)
+	return
+}
+
+// fill memory and swap from /proc/meminfo, like "MemTotal:       16318600 kB"
+func read_memory(m *biz.AgentMetrics) error {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fields := map[string]*uint64{
+		"MemTotal":     &m.MemTotal,
+		"MemAvailable": &m.MemAvailable,
+		"SwapTotal":    &m.SwapTotal,
+		"SwapFree":     &m.SwapFree,
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || fields[name] == nil {
+			continue
+		}
+		kb, _ := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+		*fields[name] = kb * 1024
+	}
+	return scanner.Err()
+}
+
+func read_uptime() (int64, error) {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 1 {
+		return 0, errors.New("bad /proc/uptime")
+	}
+	uptime, err := strconv.ParseFloat(fields[0], 64)
+	return int64(uptime), err
+}
+
+// /proc/net/dev, after 2 header lines:
+// "  eth0: rx_bytes rx_packets rx_errs rx_drop rx_fifo rx_frame rx_compressed rx_multicast tx_bytes tx_packets tx_errs ..."
+func read_net_stats() ([]biz.NetStat, error) {
+	file, err := os.Open("/proc/net/dev")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	ans := make([]biz.NetStat, 0)
+	scanner := bufio.NewScanner(file)
+	for line := 0; scanner.Scan(); line++ {
+		if line < 2 {
+			continue
+		}
+		name, counters, ok := strings.Cut(scanner.Text(), ":")
+		fields := strings.Fields(counters)
+		if !ok || len(fields) < 11 {
+			continue
+		}
+		n := make([]uint64, 11)
+		for i := range n {
+			n[i], _ = strconv.ParseUint(fields[i], 10, 64)
+		}
+		ans = append(ans, biz.NetStat{
+			Interface: strings.TrimSpace(name),
+			RxBytes:   n[0],
+			RxPackets: n[1],
+			RxErrors:  n[2],
+			TxBytes:   n[8],
+			TxPackets: n[9],
+			TxErrors:  n[10],
+		})
+	}
+	return ans, scanner.Err()
+}
+
+// owner uid and command line of a process. the command line is empty for kernel threads
+func read_process_owner(pid int32) (uint32, string, error) {
+	dir := "/proc/" + strconv.Itoa(int(pid))
+	info, err := os.Stat(dir)
+	if err != nil {
+		return 0, "", err
+	}
+	uid := uint32(0)
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		uid = st.Uid
+	}
+
+	data, err := os.ReadFile(dir + "/cmdline")
+	if err != nil {
+		return 0, "", err
+	}
+	cmdline := strings.TrimRight(string(data), "\x00")
+	return uid, strings.ReplaceAll(cmdline, "\x00", " "), nil
+}
+
+// "btime" line of /proc/stat
+func read_boot_time() (time.Time, error) {
+	file, err := os.Open("/proc/stat")
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "btime "); ok {
+			sec, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			return time.Unix(sec, 0), err
+		}
+	}
+	return time.Time{}, errors.New("btime not found in /proc/stat")
+}
@@ -0,0 +1,45 @@
+//go:build !linux
+
+package agent_omni
+
+import (
+	"errors"
+	"remote-agent/biz"
+	"time"
+)
+
+const clock_ticks_per_second = 100
+
+var errMetricsNotSupported = errors.New("not supported on this platform")
+
+func read_cpu_times() (cpu_times, error) {
+	return cpu_times{}, errMetricsNotSupported
+}
+
+func read_processes() (map[int32]proc_sample, error) {
+	return nil, errMetricsNotSupported
+}
+
+func read_load_avg() (load1, load5, load15 float64, err error) {
+	return 0, 0, 0, errMetricsNotSupported
+}
+
+func read_memory(m *biz.AgentMetrics) error {
+	return errMetricsNotSupported
+}
+
+func read_uptime() (int64, error) {
+	return 0, errMetricsNotSupported
+}
+
+func read_net_stats() ([]biz.NetStat, error) {
+	return nil, errMetricsNotSupported
+}
+
+func read_process_owner(pid int32) (uint32, string, error) {
+	return 0, "", errMetricsNotSupported
+}
+
+func read_boot_time() (time.Time, error) {
+	return time.Time{}, errMetricsNotSupported
+}
@@ -0,0 +1,47 @@
+package agent_omni_test
+
+import (
+	"fmt"
+	"os"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"runtime"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	idBytes := []byte{0x01, 0x02, 0x03, 0x04}
+	req := biz.AgentMetricsRequest{TopN: 3, SampleMs: 50}
+	reqBytes, _ := req.MarshalMsg(nil)
+	ts.ChToAgent <- utils.JoinBytes2(0x32, idBytes, reqBytes)
+
+	recv := readWithTimeout(ts.ChFromAgent)
+	if len(recv) < 5 || bytes2hex(recv[:5]) != "3201020304" {
+		t.Fatalf("did not recv metrics: %s", bytes2hex(recv))
+	}
+	m := biz.AgentMetrics{}
+	if _, err := m.UnmarshalMsg(recv[5:]); err != nil {
+		t.Fatalf("failed to unmarshal metrics: %s", err.Error())
+	}
+
+	Assert(t, m.NumCPU > 0, "num cpu")
+	Assert(t, m.Agent.Goroutines > 0 && m.Agent.HeapAlloc > 0, fmt.Sprintf("agent runtime: %+v", m.Agent))
+	Assert(t, m.Agent.Pid == int32(os.Getpid()), "agent pid")
+	if runtime.GOOS != "linux" {
+		return
+	}
+
+	Assert(t, len(m.Errors) == 0, fmt.Sprintf("no errors: %v", m.Errors))
+	Assert(t, m.MemTotal > 0 && m.MemAvailable <= m.MemTotal, fmt.Sprintf("memory: %d / %d", m.MemAvailable, m.MemTotal))
+	Assert(t, m.CPUPercent >= 0 && m.CPUPercent <= 100, fmt.Sprintf("cpu percent: %f", m.CPUPercent))
+	Assert(t, m.Uptime > 0, "uptime")
+	Assert(t, len(m.Network) > 0, "network interfaces")
+	Assert(t, len(m.Processes) > 0 && len(m.Processes) <= 3, fmt.Sprintf("top processes: %+v", m.Processes))
+	for _, p := range m.Processes {
+		Assert(t, p.Name != "" && p.Pid > 0, fmt.Sprintf("process: %+v", p))
+	}
+}
@@ -0,0 +1,160 @@
+package agent_omni
+
+import (
+	"errors"
+	"os/user"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"sort"
+	"strconv"
+	"time"
+)
+
+func (s *PtySession) SetupProcess() {
+	// list processes
+	// request: [0x33] + uint32LE(reqId) + msgpack(ProcessListRequest)
+	// response: [0x33] + uint32LE(reqId) + msgpack(ProcessListResponse)
+	s.Handlers[0x33] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid process list request")
+			return
+		}
+		idBytes := recv[1:5]
+
+		resp := biz.ProcessListResponse{}
+		req := biz.ProcessListRequest{}
+		if _, err := req.UnmarshalMsg(recv[5:]); err != nil {
+			resp.Error = "bad request: " + err.Error()
+		} else if list, err := list_processes(&req); err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Processes = list
+		}
+
+		respBytes, _ := resp.MarshalMsg(nil)
+		s.Write(utils.JoinBytes2(0x33, idBytes, respBytes))
+	}
+
+	// send a signal to a process
+	// request: [0x34] + uint32LE(reqId) + msgpack(ProcessSignalRequest)
+	// response: [0x34] + uint32LE(reqId) + msgpack(ProcessSignalResponse)
+	s.Handlers[0x34] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid process signal request")
+			return
+		}
+		idBytes := recv[1:5]
+
+		resp := biz.ProcessSignalResponse{}
+		req := biz.ProcessSignalRequest{}
+		if _, err := req.UnmarshalMsg(recv[5:]); err != nil {
+			resp.Error = "bad request: " + err.Error()
+		} else if req.Pid <= 0 {
+			// kill(0) and kill(-1) would signal process groups, or everything
+			resp.Error = "invalid pid: " + strconv.Itoa(int(req.Pid))
+		} else if err := signal_process(int(req.Pid), req.Signal); err != nil {
+			resp.Error = err.Error()
+		}
+
+		respBytes, _ := resp.MarshalMsg(nil)
+		s.Write(utils.JoinBytes2(0x34, idBytes, respBytes))
+	}
+}
+
+func list_processes(req *biz.ProcessListRequest) ([]biz.ProcessInfo, error) {
+	sample := time.Duration(req.SampleMs) * time.Millisecond
+	if sample <= 0 {
+		sample = 250 * time.Millisecond
+	}
+	sample = min(sample, maxSample)
+
+	before, err := read_processes()
+	if err != nil {
+		return nil, err
+	}
+	time.Sleep(sample)
+	after, err := read_processes()
+	if err != nil {
+		return nil, err
+	}
+	boot_time, err := read_boot_time()
+	if err != nil {
+		return nil, err
+	}
+
+	users := map[uint32]string{} // cache of uid -> user name
+	list := make([]biz.ProcessInfo, 0, len(after))
+	for pid, p := range after {
+		ticks := uint64(0)
+		if b, ok := before[pid]; ok && p.ticks >= b.ticks {
+			ticks = p.ticks - b.ticks
+		}
+		info := biz.ProcessInfo{
+			Pid:        pid,
+			Ppid:       p.ppid,
+			Name:       p.name,
+			State:      p.state,
+			Threads:    p.threads,
+			CPUPercent: float64(ticks) / clock_ticks_per_second / sample.Seconds() * 100,
+			RSS:        p.rss,
+			StartTime:  boot_time.Add(time.Duration(p.start_ticks) * time.Second / clock_ticks_per_second).UnixMilli(),
+		}
+
+		uid, cmdline, err := read_process_owner(pid)
+		if err != nil {
+			continue // process has exited
+		}
+		info.Cmdline = cmdline
+		name, ok := users[uid]
+		if !ok {
+			name = strconv.Itoa(int(uid))
+			if u, err := user.LookupId(name); err == nil {
+				name = u.Username
+			}
+			users[uid] = name
+		}
+		info.User = name
+
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Pid < list[j].Pid })
+
+	if req.Tree {
+		list = process_tree(list)
+	}
+	return list, nil
+}
+
+// nest processes under their parents. processes without a listed parent are roots.
+// list must be sorted by pid, and so are children
+func process_tree(list []biz.ProcessInfo) []biz.ProcessInfo {
+	index := make(map[int32]int, len(list))
+	for i, p := range list {
+		index[p.Pid] = i
+	}
+	children := map[int32][]int32{}
+	roots := []int32{}
+	for _, p := range list {
+		if _, ok := index[p.Ppid]; ok && p.Ppid != p.Pid {
+			children[p.Ppid] = append(children[p.Ppid], p.Pid)
+		} else {
+			roots = append(roots, p.Pid)
+		}
+	}
+
+	var build func(pid int32) biz.ProcessInfo
+	build = func(pid int32) biz.ProcessInfo {
+		p := list[index[pid]]
+		for _, child := range children[pid] {
+			p.Children = append(p.Children, build(child))
+		}
+		return p
+	}
+	ans := make([]biz.ProcessInfo, 0, len(roots))
+	for _, pid := range roots {
+		ans = append(ans, build(pid))
+	}
+	return ans
+}
+
+var errUnknownSignal = errors.New("unknown signal")
@@ -0,0 +1,36 @@
+//go:build !windows
+
+package agent_omni
+
+import (
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+var signal_names = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"TERM": syscall.SIGTERM,
+	"CONT": syscall.SIGCONT,
+	"STOP": syscall.SIGSTOP,
+}
+
+// signal is like "TERM", "SIGKILL" or "9". empty means TERM
+func signal_process(pid int, signal string) error {
+	sig := syscall.SIGTERM
+	if signal != "" {
+		if n, err := strconv.Atoi(signal); err == nil && n > 0 {
+			sig = syscall.Signal(n)
+		} else if s, ok := signal_names[strings.TrimPrefix(strings.ToUpper(signal), "SIG")]; ok {
+			sig = s
+		} else {
+			return errUnknownSignal
+		}
+	}
+	return syscall.Kill(pid, sig)
+}
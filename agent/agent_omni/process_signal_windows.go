@@ -0,0 +1,23 @@
+package agent_omni
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// only KILL is supported on windows
+func signal_process(pid int, signal string) error {
+	switch strings.TrimPrefix(strings.ToUpper(signal), "SIG") {
+	case "KILL", "9":
+	case "", "TERM", "INT", "15", "2":
+		return errors.New("only KILL is supported on windows")
+	default:
+		return errUnknownSignal
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
@@ -0,0 +1,120 @@
+package agent_omni_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProcessList(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process list is only supported on linux")
+	}
+
+	child := exec.Command("sleep", "30")
+	if err := child.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer child.Process.Kill()
+
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	list := func(tree bool) []biz.ProcessInfo {
+		req := biz.ProcessListRequest{Tree: tree, SampleMs: 20}
+		reqBytes, _ := req.MarshalMsg(nil)
+		ts.ChToAgent <- utils.JoinBytes2(0x33, []byte{1, 0, 0, 0}, reqBytes)
+
+		recv := readWithTimeout(ts.ChFromAgent)
+		if len(recv) < 5 || bytes2hex(recv[:5]) != "3301000000" {
+			t.Fatalf("did not recv process list: %s", bytes2hex(recv))
+		}
+		resp := biz.ProcessListResponse{}
+		if _, err := resp.UnmarshalMsg(recv[5:]); err != nil {
+			t.Fatalf("failed to unmarshal process list: %s", err.Error())
+		}
+		Assert(t, resp.Error == "", "no error: "+resp.Error)
+		return resp.Processes
+	}
+
+	// flat list has this test process and the child
+	var self, sleep *biz.ProcessInfo
+	processes := list(false)
+	for i := range processes {
+		switch processes[i].Pid {
+		case int32(os.Getpid()):
+			self = &processes[i]
+		case int32(child.Process.Pid):
+			sleep = &processes[i]
+		}
+	}
+	Assert(t, self != nil && sleep != nil, "found self and child")
+	Assert(t, sleep.Ppid == self.Pid, fmt.Sprintf("ppid of child: %d", sleep.Ppid))
+	Assert(t, sleep.Cmdline == "sleep 30", "cmdline of child: "+sleep.Cmdline)
+	Assert(t, sleep.Name == "sleep" && sleep.User != "" && sleep.RSS > 0, fmt.Sprintf("child: %+v", sleep))
+	started := time.UnixMilli(sleep.StartTime)
+	Assert(t, time.Since(started) < time.Minute && time.Since(started) > -time.Minute, "start time of child: "+started.String())
+
+	// tree has the child under this process
+	var find func(list []biz.ProcessInfo, pid int32) *biz.ProcessInfo
+	find = func(list []biz.ProcessInfo, pid int32) *biz.ProcessInfo {
+		for i := range list {
+			if list[i].Pid == pid {
+				return &list[i]
+			}
+			if p := find(list[i].Children, pid); p != nil {
+				return p
+			}
+		}
+		return nil
+	}
+	tree := list(true)
+	Assert(t, len(tree) < len(processes), "tree has fewer roots")
+	self = find(tree, int32(os.Getpid()))
+	Assert(t, self != nil && find(self.Children, int32(child.Process.Pid)) != nil, "child is under self in tree")
+}
+
+func TestProcessSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals are not supported on windows")
+	}
+
+	child := exec.Command("sleep", "30")
+	if err := child.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer child.Process.Kill()
+
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	signal := func(pid int, sig string) string {
+		req := biz.ProcessSignalRequest{Pid: int32(pid), Signal: sig}
+		reqBytes, _ := req.MarshalMsg(nil)
+		ts.ChToAgent <- utils.JoinBytes2(0x34, []byte{2, 0, 0, 0}, reqBytes)
+
+		recv := readWithTimeout(ts.ChFromAgent)
+		if len(recv) < 5 || bytes2hex(recv[:5]) != "3402000000" {
+			t.Fatalf("did not recv signal response: %s", bytes2hex(recv))
+		}
+		resp := biz.ProcessSignalResponse{}
+		resp.UnmarshalMsg(recv[5:])
+		return resp.Error
+	}
+
+	Assert(t, strings.Contains(signal(0, "TERM"), "invalid pid"), "pid 0 is rejected")
+	Assert(t, strings.Contains(signal(-1, "KILL"), "invalid pid"), "pid -1 is rejected")
+	Assert(t, signal(child.Process.Pid, "BOGUS") == "unknown signal", "unknown signal")
+	Assert(t, signal(child.Process.Pid, "sigterm") == "", "signal sent")
+
+	err := child.Wait()
+	Assert(t, err != nil && strings.Contains(err.Error(), "terminated"), fmt.Sprintf("child terminated: %v", err))
+}
@@ -5,58 +5,201 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"remote-agent/biz"
 	"remote-agent/utils"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
 
+// http client of proxy for requests with NoFollowRedirects. redirects are passed to user, not followed
+var noRedirectHttpClient = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// a udp channel is closed if no datagram in either direction for this long
+const udpIdleTimeout = 2 * time.Minute
+
 func (s *PtySession) SetupProxy() {
 	type ProxyChannel struct {
-		fromUser  chan []byte
-		closeOnce sync.Once
+		idBytes  []byte
+		fromUser *utils.ByteQueue // data from user. an empty slice marks the end of http request body
+		datagram bool             // udp channel. data beyond the queue limit is dropped, instead of closing the channel
+
+		// flow control. nil if the user doesn't use it
+		sendWindow *utils.SendWindow
+		recvWindow *utils.RecvWindow
 	}
-	newProxyChannel := func() *ProxyChannel {
-		return &ProxyChannel{fromUser: make(chan []byte, 5)}
+	newProxyChannel := func(idBytes []byte, window uint32, datagram bool) *ProxyChannel {
+		p := &ProxyChannel{idBytes: idBytes, fromUser: utils.NewByteQueue(utils.StreamQueueLimit), datagram: datagram}
+		if datagram {
+			p.fromUser = utils.NewByteQueue(utils.DatagramQueueLimit)
+		}
+		if window > 0 {
+			p.sendWindow = utils.NewSendWindow(window)
+			p.recvWindow = utils.NewRecvWindow(window)
+		}
+		return p
 	}
-	// Send data into the channel. Safe to call from multiple goroutines concurrently.
-	// Returns silently if the channel is closed or the session context is done.
+	// Send data into the channel. Data is dropped if the channel is closed.
+	// Without flow control, it blocks while the queue is full, like older agents.
+	// Otherwise it never blocks: if the queue is full, a stream channel is closed, and a datagram is dropped
 	proxySend := func(p *ProxyChannel, data []byte) {
-		defer func() { recover() }() // guard against send-on-closed-channel
-		select {
-		case p.fromUser <- data:
-		case <-s.Ctx.Done():
+		if p.recvWindow == nil && !p.datagram {
+			p.fromUser.PushWait(s.Ctx, data)
+			return
+		}
+		if err := p.fromUser.Push(data); err == utils.ErrQueueFull && !p.datagram {
+			p.fromUser.Close()
+			s.WriteDebugMessage(fmt.Sprintf("proxy 0x%x closed: user sent too much data without waiting", binary.LittleEndian.Uint32(p.idBytes)))
 		}
 	}
-	// Close the channel exactly once. Safe to call from multiple goroutines.
+	// Close the channel. Safe to call from multiple goroutines, multiple times.
 	proxyClose := func(p *ProxyChannel) {
-		p.closeOnce.Do(func() { close(p.fromUser) })
+		p.fromUser.Close()
+	}
+	// Read data from user until the channel is closed or ctx is done.
+	// It returns true if reading was stopped by context.
+	// Once callback returns, data is consumed and the window is granted back to user.
+	proxyRead := func(ctx context.Context, p *ProxyChannel, callback func(data []byte)) (stopped_by_ctx bool) {
+		for {
+			data, ok := p.fromUser.Pop(ctx)
+			if !ok {
+				return ctx.Err() != nil
+			}
+			callback(data)
+			if grant := p.recvWindow.Consumed(len(data)); grant > 0 {
+				s.Write(utils.JoinBytes2(0x25, p.idBytes, binary.LittleEndian.AppendUint32(nil, grant)))
+			}
+		}
+	}
+	// Send a 0x21 data package to user, waiting for the window.
+	// Returns false if ctx is done.
+	proxyWrite := func(ctx context.Context, p *ProxyChannel, data ...[]byte) bool {
+		n := 0
+		for _, d := range data {
+			n += len(d)
+		}
+		if !p.sendWindow.Acquire(ctx, n) {
+			return false
+		}
+		s.Write(utils.JoinBytes2(0x21, append([][]byte{p.idBytes}, data...)...))
+		return true
 	}
 
 	channels := sync.Map{} // map[uint32]*ProxyChannel
 
-	// open tcp proxy channel
-	s.Handlers[0x20] = func(recv []byte) {
-		if len(recv) < 7 {
-			s.WriteDebugMessage("invalid tcp proxy channel request")
-			return
-		}
+	// data packages of a channel must be handled in order,
+	// and the channel must be registered before its data come
+	for _, b := range []byte{0x20, 0x21, 0x22, 0x23, 0x24, 0x25, 0x26, 0x28, 0x2a} {
+		s.inline[b] = true
+	}
 
-		idBytes := recv[1:5]
+	// transfer data between conn and the channel, until either side closes, then send 0x22 to user.
+	// onReady is called before reading from conn. it may be nil
+	serveStream := func(channel *ProxyChannel, conn net.Conn, isUdp bool, onReady func()) {
+		defer conn.Close()
+		defer s.Write(utils.JoinBytes2(0x22, channel.idBytes)) // close connection
+
+		wg := sync.WaitGroup{}
+		defer wg.Wait()
+
+		ctx, stop := context.WithCancel(s.Ctx)
+
+		lastActive := atomic.Int64{} // unix nano, for udp idle timeout
+		lastActive.Store(time.Now().UnixNano())
+
+		wg.Add(1) // handle data from user
+		go func() {
+			defer wg.Done()
+			defer stop()
+			defer conn.Close() // if session end, or user close connection, close tcp connection
+
+			stopped_by_ctx := proxyRead(ctx, channel, func(data []byte) {
+				lastActive.Store(time.Now().UnixNano())
+				if len(data) == 0 {
+					// 0x24: user won't send more. half-close, and keep sending data from remote
+					if cw, ok := conn.(interface{ CloseWrite() error }); ok && !isUdp {
+						cw.CloseWrite()
+					}
+					return
+				}
+				conn.Write(data)
+			})
+			if stopped_by_ctx {
+				proxyClose(channel)
+			}
+		}()
+
+		wg.Add(1) // handle data from remote
+		go func() {
+			defer wg.Done()
+			defer stop()
+
+			// ---- ready for data transfer
+			if onReady != nil {
+				onReady()
+			}
+
+			// ---- continuous send to user
+			bufSize := 1024
+			if isUdp {
+				bufSize = 65536 // a whole datagram
+			}
+			for {
+				if isUdp {
+					conn.SetReadDeadline(time.Unix(0, lastActive.Load()).Add(udpIdleTimeout))
+				}
+				data := make([]byte, bufSize)
+				n, err := conn.Read(data)
+				if n > 0 && !proxyWrite(ctx, channel, data[:n]) {
+					return
+				}
+				if isUdp && n > 0 {
+					lastActive.Store(time.Now().UnixNano())
+				}
+				if err != nil {
+					var netErr net.Error
+					if isUdp && errors.As(err, &netErr) && netErr.Timeout() &&
+						time.Since(time.Unix(0, lastActive.Load())) < udpIdleTimeout {
+						continue // user sent something meanwhile
+					}
+					if isUdp && errors.Is(err, syscall.ECONNREFUSED) {
+						continue // ICMP port unreachable. the target may come up later
+					}
+					return
+				}
+			}
+		}()
+	}
+
+	// open a stream channel (tcp, udp or unix socket), and reply a 0x20 dial result.
+	// for udp, each 0x21 package is one datagram, and the channel is closed after udpIdleTimeout
+	openStream := func(idBytes []byte, network, address string, window uint32) {
 		id := binary.LittleEndian.Uint32(idBytes)
-		port := binary.LittleEndian.Uint16(recv[5:])
-		addr := string(recv[7:])
 
 		send_dial_result := func(err_code byte, msg string) {
 			s.Write(utils.JoinBytes2(0x20, idBytes, []byte{err_code}, []byte(msg)))
 		}
 
-		channel := newProxyChannel()
+		if network != "tcp" && network != "udp" && network != "unix" {
+			send_dial_result(0x01, "unsupported network: "+network)
+			return
+		}
+
+		channel := newProxyChannel(idBytes, window, network == "udp")
 		if _, exists := channels.LoadOrStore(id, channel); exists {
 			send_dial_result(0x01, "connection id already exists")
 			s.WriteDebugMessage(fmt.Sprintf("tcp proxy 0x%x already opened", id))
@@ -66,59 +209,139 @@ func (s *PtySession) SetupProxy() {
 		go func() {
 			defer channels.CompareAndDelete(id, channel)
 
-			// tcp proxy
-			conn, err := net.Dial("tcp", fmt.Sprintf("%s:%d", addr, port))
+			conn, err := net.Dial(network, address)
 			if err != nil {
 				send_dial_result(0x01, "dial error: "+err.Error())
 				proxyClose(channel)
 				return
 			}
 
-			// ---- connection ok.
-			defer conn.Close()
-			defer s.Write(utils.JoinBytes2(0x22, idBytes)) // close connection
+			serveStream(channel, conn, network == "udp", func() {
+				send_dial_result(0x00, conn.LocalAddr().String())
+			})
+		}()
+	}
 
-			wg := sync.WaitGroup{}
-			defer wg.Wait()
+	// open tcp proxy channel
+	s.Handlers[0x20] = func(recv []byte) {
+		if len(recv) < 7 {
+			s.WriteDebugMessage("invalid tcp proxy channel request")
+			return
+		}
 
-			ctx, stop := context.WithCancel(s.Ctx)
+		port := binary.LittleEndian.Uint16(recv[5:])
+		addr := string(recv[7:])
+		openStream(recv[1:5], "tcp", fmt.Sprintf("%s:%d", addr, port), 0)
+	}
 
-			wg.Add(1) // handle data from user
-			go func() {
-				defer wg.Done()
-				defer conn.Close() // if session end, or user close connection, close tcp connection
+	// open proxy channel, with options
+	s.Handlers[0x26] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid proxy channel request")
+			return
+		}
 
-				stopped_by_ctx := utils.ReadChanUnderContext(ctx, channel.fromUser, func(data []byte) {
-					conn.Write(data)
-				})
-				if stopped_by_ctx {
-					proxyClose(channel)
-				}
-			}()
+		idBytes := recv[1:5]
+		req := biz.ProxyOpenRequest{}
+		if _, err := req.UnmarshalMsg(recv[5:]); err != nil {
+			s.Write(utils.JoinBytes2(0x20, idBytes, []byte{0x01}, []byte("bad request: "+err.Error())))
+			return
+		}
+		openStream(idBytes, utils.Defaults(req.Network, "tcp"), req.Address, req.Window)
+	}
 
-			wg.Add(1) // handle data from remote
-			go func() {
-				defer wg.Done()
-				defer stop()
+	listeners := sync.Map{}           // map[uint32]net.Listener, for reverse forwarding
+	reverseCounter := atomic.Uint32{} // channels of accepted connections have the highest bit set, so they never collide with user's
 
-				// ---- ready for data transfer
-				send_dial_result(0x00, conn.LocalAddr().String())
+	// listen on agent side (reverse forwarding). reply a 0x28 listen result, and each accepted connection is a new channel (0x29)
+	s.Handlers[0x28] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid listen request")
+			return
+		}
 
-				// ---- continuous send to user
-				for {
-					data := make([]byte, 1024)
-					n, err := conn.Read(data)
-					if n > 0 {
-						s.Write(utils.JoinBytes2(0x21, idBytes, data[:n]))
-					}
-					if err != nil {
-						return
+		idBytes := recv[1:5]
+		id := binary.LittleEndian.Uint32(idBytes)
+		send_listen_result := func(err_code byte, msg string) {
+			s.Write(utils.JoinBytes2(0x28, idBytes, []byte{err_code}, []byte(msg)))
+		}
+
+		req := biz.ProxyOpenRequest{}
+		if _, err := req.UnmarshalMsg(recv[5:]); err != nil {
+			send_listen_result(0x01, "bad request: "+err.Error())
+			return
+		}
+		network := utils.Defaults(req.Network, "tcp")
+		if network != "tcp" && network != "unix" {
+			send_listen_result(0x01, "unsupported network: "+network)
+			return
+		}
+
+		ln, err := net.Listen(network, req.Address) // a unix socket file is removed when closed
+		if err != nil {
+			send_listen_result(0x01, "listen error: "+err.Error())
+			return
+		}
+		if _, exists := listeners.LoadOrStore(id, ln); exists {
+			ln.Close()
+			send_listen_result(0x01, "listener id already exists")
+			return
+		}
+		send_listen_result(0x00, ln.Addr().String())
+
+		go func() {
+			defer listeners.CompareAndDelete(id, ln)
+			stop := context.AfterFunc(s.Ctx, func() { ln.Close() }) // torn down when session ends
+			defer stop()
+
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					if _, ok := listeners.Load(id); ok && s.Ctx.Err() == nil {
+						s.Write(utils.JoinBytes2(0x2a, idBytes, []byte(err.Error())))
 					}
+					return
 				}
-			}()
+
+				chIdBytes := binary.LittleEndian.AppendUint32(nil, 0x80000000|reverseCounter.Add(1))
+				channel := newProxyChannel(chIdBytes, req.Window, false)
+				channels.Store(binary.LittleEndian.Uint32(chIdBytes), channel)
+				s.Write(utils.JoinBytes2(0x29, idBytes, chIdBytes, []byte(conn.RemoteAddr().String())))
+
+				go func() {
+					defer channels.CompareAndDelete(binary.LittleEndian.Uint32(chIdBytes), channel)
+					serveStream(channel, conn, false, nil)
+				}()
+			}
 		}()
 	}
 
+	// close listener of reverse forwarding. accepted connections are not affected
+	s.Handlers[0x2a] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid listener close request")
+			return
+		}
+
+		id := binary.LittleEndian.Uint32(recv[1:5])
+		if val, ok := listeners.LoadAndDelete(id); ok {
+			val.(net.Listener).Close()
+		}
+	}
+
+	// window update: user consumed some data
+	s.Handlers[0x25] = func(recv []byte) {
+		if len(recv) < 9 {
+			s.WriteDebugMessage("invalid proxy window update")
+			return
+		}
+
+		id := binary.LittleEndian.Uint32(recv[1:5])
+		if val, ok := channels.Load(id); ok {
+			val.(*ProxyChannel).sendWindow.Grant(binary.LittleEndian.Uint32(recv[5:9]))
+		}
+	}
+
 	// write tcp proxy channel
 	s.Handlers[0x21] = func(recv []byte) {
 		if len(recv) <= 5 {
@@ -136,6 +359,22 @@ func (s *PtySession) SetupProxy() {
 		}
 	}
 
+	// end of http request body, or half-close of a stream channel
+	s.Handlers[0x24] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid http proxy channel request")
+			return
+		}
+
+		id := binary.LittleEndian.Uint32(recv[1:5])
+
+		if val, ok := channels.Load(id); ok {
+			proxySend(val.(*ProxyChannel), []byte{})
+		} else {
+			s.WriteDebugMessage(fmt.Sprintf("http proxy 0x%x not found", id))
+		}
+	}
+
 	// close tcp proxy channel
 	s.Handlers[0x22] = func(recv []byte) {
 		if len(recv) < 5 {
@@ -154,9 +393,13 @@ func (s *PtySession) SetupProxy() {
 
 	// create http proxy channel
 	s.Handlers[0x23] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid http proxy channel request")
+			return
+		}
+
 		idBytes := recv[1:5]
 		id := binary.LittleEndian.Uint32(idBytes)
-		reqBytes := recv[5:]
 
 		dial_result := &biz.ProxyHttpResponse{}
 		send_dial_result := func() {
@@ -164,7 +407,14 @@ func (s *PtySession) SetupProxy() {
 			s.Write(utils.JoinBytes2(0x23, idBytes, resBytes))
 		}
 
-		channel := newProxyChannel()
+		req := &biz.ProxyHttpRequest{}
+		if _, err := req.UnmarshalMsg(recv[5:]); err != nil {
+			dial_result.ConnectionError = "bad request: " + err.Error()
+			send_dial_result()
+			return
+		}
+
+		channel := newProxyChannel(idBytes, req.Window, false)
 		if _, exists := channels.LoadOrStore(id, channel); exists {
 			dial_result.ConnectionError = "connection id already exists"
 			send_dial_result()
@@ -179,14 +429,7 @@ func (s *PtySession) SetupProxy() {
 
 			// prepare req
 			is_websocket := false
-			req := &biz.ProxyHttpRequest{}
 			{
-				if _, err := req.UnmarshalMsg(reqBytes); err != nil {
-					dial_result.ConnectionError = "bad request: " + err.Error()
-					send_dial_result()
-					return
-				}
-
 				url_parsed, err := url.Parse(req.URL)
 				if err != nil {
 					dial_result.ConnectionError = "bad url: " + err.Error()
@@ -229,24 +472,25 @@ func (s *PtySession) SetupProxy() {
 				wg := sync.WaitGroup{}
 				defer wg.Wait()
 
+				// ---- continuous read data
+				ctx, stop := context.WithCancel(s.Ctx)
+
 				conn.SetPingHandler(func(appData string) error {
-					s.Write(utils.JoinBytes2(0x21, idBytes, []byte{0x09}, []byte(appData)))
+					proxyWrite(ctx, channel, []byte{0x09}, []byte(appData))
 					return nil
 				})
 				conn.SetPongHandler(func(appData string) error {
-					s.Write(utils.JoinBytes2(0x21, idBytes, []byte{0x0a}, []byte(appData)))
+					proxyWrite(ctx, channel, []byte{0x0a}, []byte(appData))
 					return nil
 				})
 
-				// ---- continuous read data
-				ctx, stop := context.WithCancel(s.Ctx)
-
 				wg.Add(1) // handle data from user
 				go func() {
 					defer wg.Done()
+					defer stop()
 					defer conn.Close() // if session end, or user close connection, close ws connection
 
-					stopped_by_ctx := utils.ReadChanUnderContext(ctx, channel.fromUser, func(data []byte) {
+					stopped_by_ctx := proxyRead(ctx, channel, func(data []byte) {
 						if len(data) >= 1 {
 							messageType := int(data[0])
 							conn.WriteMessage(messageType, data[1:])
@@ -275,20 +519,23 @@ func (s *PtySession) SetupProxy() {
 							return
 						}
 
-						s.Write(utils.JoinBytes2(0x21, idBytes, []byte{uint8(messageType)}, data))
+						if !proxyWrite(ctx, channel, []byte{uint8(messageType)}, data) {
+							return
+						}
 					}
 				}()
 			}
 
 			// regular http request
 			if !is_websocket {
-				if req.Body == nil {
-					req.Body = make([]byte, 0)
+				var httpReqBody io.Reader = bytes.NewReader(req.Body)
+				var httpReqBodyWriter *io.PipeWriter // if body is streamed, write data from user to this pipe
+				if req.BodyStream {
+					httpReqBody, httpReqBodyWriter = io.Pipe()
 				}
 
-				httpReqBodyReader := bytes.NewReader(req.Body)
 				httpCtx, cancelHttpCtx := context.WithCancel(s.Ctx)
-				httpReq, err := http.NewRequestWithContext(httpCtx, req.Method, req.URL, httpReqBodyReader)
+				httpReq, err := http.NewRequestWithContext(httpCtx, req.Method, req.URL, httpReqBody)
 
 				if err != nil {
 					dial_result.ConnectionError = "bad request: " + err.Error()
@@ -302,14 +549,24 @@ func (s *PtySession) SetupProxy() {
 				wg := sync.WaitGroup{}
 				defer wg.Wait()
 
-				{ // handle data from user -- wait for "0x22" close-connection package
+				{ // handle data from user -- request body, until "0x22" close-connection package
 					wg.Add(1)
 					go func() {
 						defer wg.Done()
 
-						stopped_by_ctx := utils.ReadChanUnderContext(httpCtx, channel.fromUser, func(data []byte) {
-							// nothing to do -- http request is not duplex
+						stopped_by_ctx := proxyRead(httpCtx, channel, func(data []byte) {
+							if httpReqBodyWriter == nil {
+								return // body is not streamed
+							}
+							if len(data) == 0 {
+								httpReqBodyWriter.Close() // end of body
+							} else {
+								httpReqBodyWriter.Write(data)
+							}
 						})
+						if httpReqBodyWriter != nil {
+							httpReqBodyWriter.CloseWithError(errors.New("request aborted"))
+						}
 						if stopped_by_ctx {
 							proxyClose(channel)
 						} else {
@@ -320,10 +577,22 @@ func (s *PtySession) SetupProxy() {
 
 				httpReq.Host = req.Host
 				httpReq.Header = biz.ToHttpRequestHeaders(req.Headers)
-				httpRes, err := http.DefaultClient.Do(httpReq)
+				if req.BodyStream {
+					// go http client ignores Content-Length header. without this, body is sent chunked
+					httpReq.ContentLength = -1
+					if n, err := strconv.ParseInt(httpReq.Header.Get("Content-Length"), 10, 64); err == nil && n >= 0 {
+						httpReq.ContentLength = n
+					}
+				}
+				client := http.DefaultClient
+				if req.NoFollowRedirects {
+					client = noRedirectHttpClient
+				}
+				httpRes, err := client.Do(httpReq)
 				if err != nil {
 					dial_result.ConnectionError = "connect error: " + err.Error()
 					send_dial_result()
+					cancelHttpCtx()
 					return
 				}
 
@@ -348,8 +617,8 @@ func (s *PtySession) SetupProxy() {
 					for {
 						data := make([]byte, 1024)
 						n, err := httpResBody.Read(data)
-						if n > 0 {
-							s.Write(utils.JoinBytes2(0x21, idBytes, data[:n]))
+						if n > 0 && !proxyWrite(httpCtx, channel, data[:n]) {
+							return
 						}
 						if err != nil {
 							return
@@ -200,6 +200,88 @@ func TestProxyHttpPOST(t *testing.T) {
 	}
 }
 
+// with "body_stream" feature, POST data is sent via 0x21 packages after 0x23, and ended by 0x24
+func TestProxyHttpPOSTStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read body: %s", err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "length: %d\n", r.ContentLength)
+		fmt.Fprintf(w, "body: %s\n", string(body))
+	}))
+	defer srv.Close()
+
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	// 0. agent reports the feature
+	ts.ChToAgent <- []byte{0xfe}
+	if recv := readWithTimeout(ts.ChFromAgent); len(recv) < 1 || recv[0] != 0xfe || !strings.Contains(string(recv[1:]), "body_stream") {
+		t.Fatalf("bad hello response: %s", bytes2hex(recv))
+	}
+
+	idBytes := []byte{0xde, 0xad, 0xbe, 0xef}
+
+	conn_req := biz.ProxyHttpRequest{
+		Method: "POST",
+		URL:    srv.URL,
+		Headers: []biz.ProxyHttpHeader{
+			{Name: "Content-Length", Value: "22"},
+		},
+		BodyStream: true,
+	}
+	conn_req_bytes, _ := conn_req.MarshalMsg(nil)
+
+	// -------------------------------------
+	// 1. send http request, and body chunks right after it
+	ts.ChToAgent <- utils.JoinBytes2(0x23, idBytes, conn_req_bytes)
+	for i := 0; i < 10; i++ {
+		ts.ChToAgent <- utils.JoinBytes2(0x21, idBytes, []byte(fmt.Sprintf("%d,", i)))
+	}
+	ts.ChToAgent <- utils.JoinBytes2(0x21, idBytes, []byte("ok"))
+	ts.ChToAgent <- utils.JoinBytes2(0x24, idBytes)
+
+	if recv := readWithTimeout(ts.ChFromAgent); bytes2hex(recv[:5]) != "23deadbeef" {
+		t.Fatalf("did not recv http dial result: %s", bytes2hex(recv))
+	} else {
+		dial_result := biz.ProxyHttpResponse{}
+		if _, err := dial_result.UnmarshalMsg(recv[5:]); err != nil {
+			t.Fatalf("failed to unmarshal http dial result: %s", err.Error())
+		}
+		Assert(t, dial_result.ConnectionError == "", "no connection error")
+		Assert(t, dial_result.StatusCode == 200, "http status code 200")
+	}
+
+	// -------------------------------------
+	// 2. recv http response data
+	recv_buf := make([]byte, 0)
+	for {
+		chunk := readWithTimeout(ts.ChFromAgent)
+		if len(chunk) < 5 {
+			t.Fatalf("recv data timeout. no 0x22 package")
+		}
+		if bytes2hex(chunk[:5]) == "22deadbeef" {
+			break
+		}
+		if bytes2hex(chunk[:5]) == "21deadbeef" {
+			recv_buf = append(recv_buf, chunk[5:]...)
+			continue
+		}
+		t.Fatalf("unknown package %s", bytes2hex(chunk))
+	}
+
+	expected_data := "length: 22\nbody: 0,1,2,3,4,5,6,7,8,9,ok\n"
+	if string(recv_buf) != expected_data {
+		t.Fatalf("http response not matched:\n%s", string(recv_buf))
+	}
+}
+
 // server may send 0x22 package to stop a http response.
 // it works for SSE very well
 func TestProxyHttpAbort(t *testing.T) {
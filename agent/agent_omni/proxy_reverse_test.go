@@ -0,0 +1,96 @@
+package agent_omni_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"testing"
+	"time"
+)
+
+func TestProxyReverse(t *testing.T) {
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	// -------------------------------------
+	// 1. listen on agent side
+
+	listenerId := []byte{0x01, 0x00, 0x00, 0x00}
+	req := biz.ProxyOpenRequest{Address: "127.0.0.1:0", Window: 256 * 1024}
+	reqBytes, _ := req.MarshalMsg(nil)
+	ts.ChToAgent <- utils.JoinBytes2(0x28, listenerId, reqBytes)
+
+	recv := readWithTimeout(ts.ChFromAgent)
+	if bytes2hex(recv[:6]) != "280100000000" {
+		t.Fatalf("failed to listen: %s", bytes2hex(recv))
+	}
+	listenAddr := string(recv[6:])
+
+	// -------------------------------------
+	// 2. accepted connection becomes a channel
+
+	conn, err := net.Dial("tcp", listenAddr)
+	if err != nil {
+		t.Fatalf("failed to dial listener: %v", err)
+	}
+	defer conn.Close()
+
+	recv = readWithTimeout(ts.ChFromAgent)
+	if len(recv) < 9 || recv[0] != 0x29 || !bytes.Equal(recv[1:5], listenerId) {
+		t.Fatalf("expect 0x29 accepted: %s", bytes2hex(recv))
+	}
+	chId := recv[5:9]
+	Assert(t, chId[3]&0x80 != 0, "agent channel id has highest bit set")
+	Assert(t, string(recv[9:]) == conn.LocalAddr().String(), "remote address of accepted connection")
+
+	// user -> accepted connection
+	ts.ChToAgent <- utils.JoinBytes2(0x21, chId, []byte("hello"))
+	buf := make([]byte, 5)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = io.ReadFull(conn, buf)
+	Assert(t, err == nil && string(buf) == "hello", "data to accepted connection")
+
+	// accepted connection -> user
+	conn.Write([]byte("world"))
+	recv = readWithTimeout(ts.ChFromAgent)
+	Assert(t, bytes.Equal(recv, utils.JoinBytes2(0x21, chId, []byte("world"))), "data from accepted connection")
+
+	// -------------------------------------
+	// 3. close listener. accepted connection still works
+
+	ts.ChToAgent <- utils.JoinBytes2(0x2a, listenerId)
+	time.Sleep(100 * time.Millisecond)
+	if c, err := net.Dial("tcp", listenAddr); err == nil {
+		c.Close()
+		t.Fatalf("listener still open")
+	}
+
+	conn.Write([]byte("!"))
+	recv = readWithTimeout(ts.ChFromAgent)
+	Assert(t, bytes.Equal(recv, utils.JoinBytes2(0x21, chId, []byte("!"))), "accepted connection survives")
+
+	// close by remote
+	conn.Close()
+	recv = readWithTimeout(ts.ChFromAgent)
+	Assert(t, bytes.Equal(recv, utils.JoinBytes2(0x22, chId)), "accepted connection closed")
+
+	// -------------------------------------
+	// 4. listeners are torn down when session ends
+
+	ts.ChToAgent <- utils.JoinBytes2(0x28, []byte{0x02, 0x00, 0x00, 0x00}, reqBytes)
+	recv = readWithTimeout(ts.ChFromAgent)
+	if bytes2hex(recv[:6]) != "280200000000" {
+		t.Fatalf("failed to listen: %s", bytes2hex(recv))
+	}
+	listenAddr = string(recv[6:])
+
+	ts.TerminateSession()
+	time.Sleep(100 * time.Millisecond)
+	if c, err := net.Dial("tcp", listenAddr); err == nil {
+		c.Close()
+		t.Fatalf("listener not closed after session ends")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"fmt"
 	"io"
 	"net"
 	"remote-agent/utils"
@@ -83,6 +84,65 @@ func TestProxyTcp(t *testing.T) {
 	}
 }
 
+// a channel without flow control is slowed down by a slow target, instead of closed when the queue is full
+func TestProxyTcpNoWindowBackpressure(t *testing.T) {
+	total := 3 * utils.StreamQueueLimit
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer l.Close()
+	received := make(chan int64, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			received <- 0
+			return
+		}
+		defer conn.Close()
+		time.Sleep(500 * time.Millisecond) // let the queue fill up
+		n, _ := io.CopyN(io.Discard, conn, int64(total))
+		received <- n
+	}()
+
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	idBytes := []byte{0xde, 0xad, 0xbe, 0xef}
+	ts.ChToAgent <- utils.JoinBytes2(
+		0x20,
+		idBytes,
+		binary.LittleEndian.AppendUint16(nil, uint16(l.Addr().(*net.TCPAddr).Port)),
+		[]byte("127.0.0.1"),
+	) // legacy open, without a window
+	if recv := readWithTimeout(ts.ChFromAgent); bytes2hex(recv[:6]) != "20deadbeef00" {
+		t.Fatalf("failed to connect: %s", bytes2hex(recv))
+	}
+
+	go func() {
+		chunk := bytes.Repeat([]byte{'x'}, 32*1024)
+		for sent := 0; sent < total; sent += len(chunk) {
+			ts.ChToAgent <- utils.JoinBytes2(0x21, idBytes, chunk)
+		}
+	}()
+
+	for {
+		select {
+		case recv := <-ts.ChFromAgent:
+			if bytes.Equal(recv, utils.JoinBytes2(0x22, idBytes)) {
+				t.Fatalf("channel closed by agent")
+			}
+		case n := <-received:
+			Assert(t, n == int64(total), fmt.Sprintf("target received all data, got %d of %d", n, total))
+			return
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timeout")
+		}
+	}
+}
+
 type MockServer struct {
 	Port           int
 	Stop           context.CancelFunc
@@ -0,0 +1,63 @@
+package agent_omni_test
+
+import (
+	"bytes"
+	"net"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"testing"
+)
+
+func TestProxyUdp(t *testing.T) {
+	// echo server, replies each datagram with a "re:" prefix
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen udp: %v", err)
+	}
+	defer pc.Close()
+	go func() {
+		buf := make([]byte, 65536)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			pc.WriteTo(append([]byte("re:"), buf[:n]...), addr)
+		}
+	}()
+
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	idBytes := []byte{0xde, 0xad, 0xbe, 0xef}
+	req := biz.ProxyOpenRequest{Network: "udp", Address: pc.LocalAddr().String(), Window: 256 * 1024}
+	reqBytes, _ := req.MarshalMsg(nil)
+	ts.ChToAgent <- utils.JoinBytes2(0x26, idBytes, reqBytes)
+	if recv := readWithTimeout(ts.ChFromAgent); bytes2hex(recv[:6]) != "20deadbeef00" {
+		t.Fatalf("failed to open udp: %s", bytes2hex(recv))
+	}
+
+	// datagram boundaries are kept
+	big := bytes.Repeat([]byte("x"), 8000)
+	for _, payload := range [][]byte{[]byte("a"), []byte("bc"), big} {
+		ts.ChToAgent <- utils.JoinBytes2(0x21, idBytes, payload)
+		recv := readWithTimeout(ts.ChFromAgent)
+		want := utils.JoinBytes2(0x21, idBytes, []byte("re:"), payload)
+		Assert(t, bytes.Equal(recv, want), "echo datagram "+string(payload[:1]))
+	}
+
+	// close by user
+	ts.ChToAgent <- utils.JoinBytes2(0x22, idBytes)
+	if recv := readWithTimeout(ts.ChFromAgent); bytes2hex(recv) != "22deadbeef" {
+		t.Fatalf("failed to close: %s", bytes2hex(recv))
+	}
+
+	// unknown network
+	req.Network = "sctp"
+	reqBytes, _ = req.MarshalMsg(nil)
+	ts.ChToAgent <- utils.JoinBytes2(0x26, idBytes, reqBytes)
+	if recv := readWithTimeout(ts.ChFromAgent); bytes2hex(recv[:6]) != "20deadbeef01" {
+		t.Fatalf("unknown network shall fail: %s", bytes2hex(recv))
+	}
+}
@@ -0,0 +1,102 @@
+package agent_omni_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"testing"
+	"time"
+)
+
+func TestProxyUnix(t *testing.T) {
+	dir := t.TempDir()
+
+	// an echo server on unix socket
+	target := filepath.Join(dir, "echo.sock")
+	ln, err := net.Listen("unix", target)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	// -------------------------------------
+	// 1. dial a unix socket
+
+	chId := []byte{0x01, 0x00, 0x00, 0x00}
+	req := biz.ProxyOpenRequest{Network: "unix", Address: target, Window: 256 * 1024}
+	reqBytes, _ := req.MarshalMsg(nil)
+	ts.ChToAgent <- utils.JoinBytes2(0x26, chId, reqBytes)
+
+	recv := readWithTimeout(ts.ChFromAgent)
+	if bytes2hex(recv[:6]) != "200100000000" {
+		t.Fatalf("failed to dial: %s", bytes2hex(recv))
+	}
+
+	ts.ChToAgent <- utils.JoinBytes2(0x21, chId, []byte("ping"))
+	recv = readWithTimeout(ts.ChFromAgent)
+	Assert(t, bytes.Equal(recv, utils.JoinBytes2(0x21, chId, []byte("ping"))), "echo through unix socket")
+
+	ts.ChToAgent <- utils.JoinBytes2(0x22, chId)
+	recv = readWithTimeout(ts.ChFromAgent)
+	Assert(t, bytes.Equal(recv, utils.JoinBytes2(0x22, chId)), "channel closed")
+
+	// -------------------------------------
+	// 2. listen on a unix socket (reverse forwarding)
+
+	listenerId := []byte{0x02, 0x00, 0x00, 0x00}
+	listenPath := filepath.Join(dir, "reverse.sock")
+	req = biz.ProxyOpenRequest{Network: "unix", Address: listenPath, Window: 256 * 1024}
+	reqBytes, _ = req.MarshalMsg(nil)
+	ts.ChToAgent <- utils.JoinBytes2(0x28, listenerId, reqBytes)
+
+	recv = readWithTimeout(ts.ChFromAgent)
+	if bytes2hex(recv[:6]) != "280200000000" {
+		t.Fatalf("failed to listen: %s", bytes2hex(recv))
+	}
+	Assert(t, string(recv[6:]) == listenPath, "listen address is the socket path")
+
+	conn, err := net.Dial("unix", listenPath)
+	if err != nil {
+		t.Fatalf("failed to dial listener: %v", err)
+	}
+
+	recv = readWithTimeout(ts.ChFromAgent)
+	if len(recv) < 9 || recv[0] != 0x29 || !bytes.Equal(recv[1:5], listenerId) {
+		t.Fatalf("expect 0x29 accepted: %s", bytes2hex(recv))
+	}
+	acceptedId := recv[5:9]
+
+	conn.Write([]byte("hello"))
+	recv = readWithTimeout(ts.ChFromAgent)
+	Assert(t, bytes.Equal(recv, utils.JoinBytes2(0x21, acceptedId, []byte("hello"))), "data from accepted connection")
+
+	// socket file is removed when listener closes
+	ts.ChToAgent <- utils.JoinBytes2(0x2a, listenerId)
+	time.Sleep(100 * time.Millisecond)
+	_, err = os.Stat(listenPath)
+	Assert(t, os.IsNotExist(err), "socket file removed")
+
+	conn.Close()
+	recv = readWithTimeout(ts.ChFromAgent)
+	Assert(t, bytes.Equal(recv, utils.JoinBytes2(0x22, acceptedId)), "accepted connection closed")
+}
@@ -0,0 +1,111 @@
+package agent_omni_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"testing"
+	"time"
+)
+
+// a channel without window credit stops sending, but other channels keep flowing
+func TestProxyWindow(t *testing.T) {
+	const window = 64 * 1024
+	big := bytes.Repeat([]byte("0123456789abcdef"), 1024*1024/16)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/big" {
+			w.Write(big)
+			return
+		}
+		w.Write([]byte("small"))
+	}))
+	defer srv.Close()
+
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	open := func(idBytes []byte, path string) {
+		req := biz.ProxyHttpRequest{Method: "GET", URL: srv.URL + path, Window: window}
+		reqBytes, _ := req.MarshalMsg(nil)
+		ts.ChToAgent <- utils.JoinBytes2(0x23, idBytes, reqBytes)
+	}
+	bigId := []byte{0x01, 0x00, 0x00, 0x00}
+	smallId := []byte{0x02, 0x00, 0x00, 0x00}
+
+	// -------------------------------------
+	// 1. big download stalls at the window size, because nobody grants credit
+
+	open(bigId, "/big")
+	bigReceived := 0
+	for stalled := false; !stalled; {
+		select {
+		case recv := <-ts.ChFromAgent:
+			if bytes.Equal(recv[:5], utils.JoinBytes2(0x21, bigId)) {
+				bigReceived += len(recv) - 5
+			}
+		case <-time.After(300 * time.Millisecond):
+			stalled = true
+		}
+	}
+	Assert(t, bigReceived >= window, "big download sent a whole window")
+	Assert(t, bigReceived < window+4096, "big download stopped at window")
+
+	// -------------------------------------
+	// 2. small request on the same session is not blocked
+
+	open(smallId, "/small")
+	smallBody := []byte{}
+	for done := false; !done; {
+		recv := readWithTimeout(ts.ChFromAgent)
+		switch {
+		case len(recv) == 0:
+			t.Fatalf("small request timeout")
+		case bytes.Equal(recv[:5], utils.JoinBytes2(0x21, smallId)):
+			smallBody = append(smallBody, recv[5:]...)
+		case bytes.Equal(recv[:5], utils.JoinBytes2(0x22, smallId)):
+			done = true
+		case bytes.Equal(recv[:5], utils.JoinBytes2(0x21, bigId)):
+			t.Fatalf("big download sent data without credit")
+		}
+	}
+	Assert(t, string(smallBody) == "small", "small response body")
+
+	// -------------------------------------
+	// 3. granting credit resumes the big download, until the end
+
+	grant := func(n int) {
+		ts.ChToAgent <- utils.JoinBytes2(0x25, bigId, binary.LittleEndian.AppendUint32(nil, uint32(n)))
+	}
+	grant(bigReceived)
+	for done := false; !done; {
+		recv := readWithTimeout(ts.ChFromAgent)
+		switch {
+		case len(recv) == 0:
+			t.Fatalf("big download timeout, received %d bytes", bigReceived)
+		case bytes.Equal(recv[:5], utils.JoinBytes2(0x21, bigId)):
+			bigReceived += len(recv) - 5
+			grant(len(recv) - 5)
+		case bytes.Equal(recv[:5], utils.JoinBytes2(0x22, bigId)):
+			done = true
+		}
+	}
+	Assert(t, bigReceived == len(big), "big download completed")
+
+	// the session reads packages in order. once hello is replied, no grant is in flight,
+	// and terminating the session won't race with the sender
+	ts.ChToAgent <- []byte{0xfe}
+	for {
+		recv := readWithTimeout(ts.ChFromAgent)
+		if len(recv) == 0 {
+			t.Fatalf("hello timeout")
+		}
+		if recv[0] == 0xfe {
+			break
+		}
+	}
+}
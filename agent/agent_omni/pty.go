@@ -13,21 +13,17 @@ import (
 
 func (s *PtySession) SetupPty() {
 	var pty *os.File
-
-	// listener: debug message
-	s.Handlers[0xff] = func(recv []byte) {
-		s.WriteDebugMessage(string(recv[1:]))
-	}
+	handlers := [4]func(recv []byte){}
 
 	// listener: pty data write
-	s.Handlers[0x00] = func(recv []byte) {
+	handlers[0x00] = func(recv []byte) {
 		if pty != nil {
 			pty.Write(recv[1:])
 		}
 	}
 
 	// listener: start pty
-	s.Handlers[0x01] = func(recv []byte) {
+	handlers[0x01] = func(recv []byte) {
 		if pty != nil {
 			s.WriteDebugMessage("pty already opened")
 		} else {
@@ -65,14 +61,29 @@ func (s *PtySession) SetupPty() {
 				}()
 
 				go func() {
+					exited := false
 					select {
 					case <-pty_closed: // pty closed
+						exited = true
 					case <-s.Ctx.Done(): // session end
 					}
 
 					pty.Close()
 					pty = nil
-					s.Write([]byte{0x02}) // pty closed
+					if !exited {
+						go c.Wait()           // reap it once it exits
+						s.Write([]byte{0x02}) // pty closed
+						return
+					}
+
+					// the output ended, usually because the process exited
+					code := int32(-1)
+					if err := c.Wait(); err == nil {
+						code = 0
+					} else if exitErr, ok := err.(*exec.ExitError); ok {
+						code = int32(exitErr.ExitCode())
+					}
+					s.Write(binary.LittleEndian.AppendUint32([]byte{0x02}, uint32(code))) // pty closed
 				}()
 
 				for {
@@ -93,7 +104,7 @@ func (s *PtySession) SetupPty() {
 	}
 
 	// listener: close pty
-	s.Handlers[0x02] = func(recv []byte) {
+	handlers[0x02] = func(recv []byte) {
 		if pty != nil {
 			if err := pty.Close(); err != nil {
 				s.WriteDebugMessage(err.Error())
@@ -102,7 +113,7 @@ func (s *PtySession) SetupPty() {
 	}
 
 	// listener: resize pty
-	s.Handlers[0x03] = func(recv []byte) {
+	handlers[0x03] = func(recv []byte) {
 		if pty != nil {
 			cols := uint16(binary.LittleEndian.Uint16(recv[1:]))
 			rows := uint16(binary.LittleEndian.Uint16(recv[3:]))
@@ -119,4 +130,26 @@ func (s *PtySession) SetupPty() {
 			}
 		}
 	}
+
+	// pty packages are handled one by one in a goroutine, so input stays in order and comes after the pty is started,
+	// while a slow pty doesn't hold up the read loop
+	queue := utils.NewByteQueue(utils.StreamQueueLimit)
+	go func() {
+		defer queue.Close()
+		for {
+			recv, ok := queue.Pop(s.Ctx)
+			if !ok {
+				return
+			}
+			handlers[recv[0]](recv)
+		}
+	}()
+	for b := 0x00; b <= 0x03; b++ {
+		s.Handlers[b] = func(recv []byte) {
+			if err := queue.Push(recv); err == utils.ErrQueueFull {
+				s.WriteDebugMessage("pty input dropped: too much data queued")
+			}
+		}
+		s.inline[b] = true // only queued on the read loop
+	}
 }
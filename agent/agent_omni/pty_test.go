@@ -0,0 +1,83 @@
+package agent_omni_test
+
+import (
+	"fmt"
+	"remote-agent/biz"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// input sent right after starting the pty arrives in order
+func TestPtyInputOrder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no pty on windows")
+	}
+
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	req := biz.StartPtyRequest{Cmd: "sh", Args: []string{"-c", "cat > /dev/null"}}
+	reqBytes, _ := req.MarshalMsg(nil)
+	ts.ChToAgent <- append([]byte{0x01}, reqBytes...)
+
+	want := ""
+	for i := 0; i < 200; i++ {
+		line := fmt.Sprintf("line %04d", i)
+		ts.ChToAgent <- []byte("\x00" + line + "\n")
+		want += line + "\r\n" // echoed by the terminal
+	}
+
+	output := ""
+	deadline := time.After(10 * time.Second)
+	for len(output) < len(want) {
+		select {
+		case recv := <-ts.ChFromAgent:
+			if recv[0] == 0x00 {
+				output += string(recv[1:])
+			}
+		case <-deadline:
+			t.Fatalf("timeout, got %d of %d bytes", len(output), len(want))
+		}
+	}
+	Assert(t, strings.HasPrefix(output, want), "input in order, got "+output[:min(len(output), 100)])
+
+	ts.ChToAgent <- []byte("\x00\x04") // ctrl-d, so cat exits and the pty closes
+	for {
+		recv := readWithTimeout(ts.ChFromAgent)
+		if len(recv) == 0 {
+			t.Fatal("pty not closed")
+		}
+		if recv[0] == 0x02 {
+			break
+		}
+	}
+}
+
+// pty closed frame carries the exit code of the process
+func TestPtyExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no pty on windows")
+	}
+
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	req := biz.StartPtyRequest{Cmd: "sh", Args: []string{"-c", "exit 3"}}
+	reqBytes, _ := req.MarshalMsg(nil)
+	ts.ChToAgent <- append([]byte{0x01}, reqBytes...)
+
+	for {
+		recv := readWithTimeout(ts.ChFromAgent)
+		if len(recv) == 0 {
+			t.Fatal("pty not closed")
+		}
+		if recv[0] == 0x02 {
+			Assert(t, bytes2hex(recv) == "0203000000", "exit code 3, got "+bytes2hex(recv))
+			return
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package agent_omni
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	serviceListTimeout   = 30 * time.Second
+	serviceActionTimeout = 2 * time.Minute // stopping a unit may wait for its TimeoutStopSec
+	serviceMaxLines      = 10000
+)
+
+func (s *PtySession) SetupService() {
+	// list systemd units
+	// request: [0x35] + uint32LE(reqId) + msgpack(ServiceListRequest)
+	// response: [0x35] + uint32LE(reqId) + msgpack(ServiceListResponse)
+	s.Handlers[0x35] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid service list request")
+			return
+		}
+		idBytes := recv[1:5]
+
+		resp := biz.ServiceListResponse{}
+		req := biz.ServiceListRequest{}
+		if _, err := req.UnmarshalMsg(recv[5:]); err != nil {
+			resp.Error = "bad request: " + err.Error()
+		} else if units, err := list_units(s.Ctx, &req); err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Units = units
+		}
+
+		respBytes, _ := resp.MarshalMsg(nil)
+		s.Write(utils.JoinBytes2(0x35, idBytes, respBytes))
+	}
+
+	// start, stop, restart or reload a unit
+	// request: [0x36] + uint32LE(reqId) + msgpack(ServiceActionRequest)
+	// response: [0x36] + uint32LE(reqId) + msgpack(ServiceActionResponse)
+	s.Handlers[0x36] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid service action request")
+			return
+		}
+		idBytes := recv[1:5]
+
+		resp := biz.ServiceActionResponse{}
+		req := biz.ServiceActionRequest{}
+		if _, err := req.UnmarshalMsg(recv[5:]); err != nil {
+			resp.Error = "bad request: " + err.Error()
+		} else if err := check_unit_name(req.Unit); err != nil {
+			resp.Error = err.Error()
+		} else if !is_service_action(req.Action) {
+			resp.Error = "unknown action: " + req.Action
+		} else {
+			ctx, cancel := context.WithTimeout(s.Ctx, serviceActionTimeout)
+			_, err := run_systemd_tool(ctx, "systemctl", req.Action, "--no-ask-password", "--", req.Unit)
+			cancel()
+			if err != nil {
+				resp.Error = err.Error()
+			}
+			// report the state even if action failed, like a unit which failed to start
+			if unit, err := show_unit(s.Ctx, req.Unit); err == nil {
+				resp.Unit = unit
+			} else if resp.Error == "" {
+				resp.Error = err.Error()
+			}
+		}
+
+		respBytes, _ := resp.MarshalMsg(nil)
+		s.Write(utils.JoinBytes2(0x36, idBytes, respBytes))
+	}
+
+	// recent journal lines of a unit
+	// request: [0x37] + uint32LE(reqId) + msgpack(ServiceJournalRequest)
+	// response: [0x37] + uint32LE(reqId) + msgpack(ServiceJournalResponse)
+	s.Handlers[0x37] = func(recv []byte) {
+		if len(recv) < 5 {
+			s.WriteDebugMessage("invalid service journal request")
+			return
+		}
+		idBytes := recv[1:5]
+
+		resp := biz.ServiceJournalResponse{}
+		req := biz.ServiceJournalRequest{}
+		if _, err := req.UnmarshalMsg(recv[5:]); err != nil {
+			resp.Error = "bad request: " + err.Error()
+		} else if err := check_unit_name(req.Unit); err != nil {
+			resp.Error = err.Error()
+		} else if lines, err := read_journal(s.Ctx, &req); err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Lines = lines
+		}
+
+		respBytes, _ := resp.MarshalMsg(nil)
+		s.Write(utils.JoinBytes2(0x37, idBytes, respBytes))
+	}
+}
+
+func is_service_action(action string) bool {
+	switch action {
+	case "start", "stop", "restart", "reload":
+		return true
+	}
+	return false
+}
+
+// unit names are passed to systemctl as arguments, so reject anything looks like an option
+func check_unit_name(unit string) error {
+	if unit == "" || len(unit) > 256 || strings.HasPrefix(unit, "-") || strings.ContainsFunc(unit, func(r rune) bool { return r <= ' ' || r == 0x7f }) {
+		return fmt.Errorf("invalid unit name: %q", unit)
+	}
+	return nil
+}
+
+// run systemctl or journalctl, and returns stdout.
+// on failure, the error has stderr (or stdout) of the tool
+func run_systemd_tool(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(cmd.Environ(), "LC_ALL=C", "SYSTEMD_COLORS=0", "SYSTEMD_PAGER=")
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	if err == nil {
+		return stdout.Bytes(), nil
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return nil, err // not found, or killed by timeout
+	}
+	msg := strings.TrimSpace(stderr.String())
+	if msg == "" {
+		msg = strings.TrimSpace(stdout.String())
+	}
+	if msg == "" {
+		msg = err.Error()
+	}
+	return nil, fmt.Errorf("%s: %s", name, msg)
+}
+
+func list_units(ctx context.Context, req *biz.ServiceListRequest) ([]biz.ServiceUnit, error) {
+	ctx, cancel := context.WithTimeout(ctx, serviceListTimeout)
+	defer cancel()
+
+	args := []string{"list-units", "--no-legend", "--no-pager", "--plain", "--full"}
+	switch req.Type {
+	case "":
+		args = append(args, "--type=service")
+	case "all":
+	default:
+		if strings.HasPrefix(req.Type, "-") {
+			return nil, fmt.Errorf("invalid unit type: %q", req.Type)
+		}
+		args = append(args, "--type="+req.Type)
+	}
+	if req.All {
+		args = append(args, "--all")
+	}
+
+	out, err := run_systemd_tool(ctx, "systemctl", args...)
+	if err != nil {
+		return nil, err
+	}
+	return parse_unit_list(out), nil
+}
+
+// parse lines of `systemctl list-units --plain --no-legend`, like
+// "nginx.service loaded active running A high performance web server"
+func parse_unit_list(out []byte) []biz.ServiceUnit {
+	units := []biz.ServiceUnit{}
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		// older systemd marks failed units with a bullet, even with --plain
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(line, "●"), "*"))
+
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		unit := biz.ServiceUnit{Unit: fields[0], Load: fields[1], Active: fields[2], Sub: fields[3]}
+		if len(fields) > 4 {
+			// keep spaces in description
+			rest := line
+			for _, f := range fields[:4] {
+				rest = strings.TrimSpace(rest[strings.Index(rest, f)+len(f):])
+			}
+			unit.Description = rest
+		}
+		units = append(units, unit)
+	}
+	return units
+}
+
+// state of one unit, via `systemctl show`
+func show_unit(ctx context.Context, unit string) (biz.ServiceUnit, error) {
+	ctx, cancel := context.WithTimeout(ctx, serviceListTimeout)
+	defer cancel()
+
+	out, err := run_systemd_tool(ctx, "systemctl", "show", "--property=Id,LoadState,ActiveState,SubState,Description", "--", unit)
+	if err != nil {
+		return biz.ServiceUnit{}, err
+	}
+
+	ans := biz.ServiceUnit{Unit: unit}
+	for _, line := range strings.Split(string(out), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Id":
+			ans.Unit = utils.Defaults(value, unit)
+		case "LoadState":
+			ans.Load = value
+		case "ActiveState":
+			ans.Active = value
+		case "SubState":
+			ans.Sub = value
+		case "Description":
+			ans.Description = value
+		}
+	}
+	return ans, nil
+}
+
+func read_journal(ctx context.Context, req *biz.ServiceJournalRequest) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, serviceListTimeout)
+	defer cancel()
+
+	lines := int(req.Lines)
+	if lines <= 0 {
+		lines = 100
+	}
+	lines = min(lines, serviceMaxLines)
+
+	args := []string{"--no-pager", "--output=short-iso", "--lines=" + strconv.Itoa(lines), "--unit=" + req.Unit}
+	if req.Since != "" {
+		args = append(args, "--since="+req.Since)
+	}
+	out, err := run_systemd_tool(ctx, "journalctl", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	ans := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	if len(ans) == 1 && ans[0] == "" {
+		ans = []string{}
+	}
+	return ans, nil
+}
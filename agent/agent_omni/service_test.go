@@ -0,0 +1,148 @@
+package agent_omni_test
+
+import (
+	"os"
+	"path/filepath"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// stub systemctl and journalctl on PATH. each call is appended to calls.log
+func setupServiceStubs(t *testing.T) (calls func() []string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("stubs are shell scripts")
+	}
+
+	dir := t.TempDir()
+	log := filepath.Join(dir, "calls.log")
+	stubs := map[string]string{
+		"systemctl": `#!/bin/sh
+echo "systemctl $*" >> "` + log + `"
+case "$1" in
+list-units)
+	echo "cron.service    loaded active running Regular background program processing daemon"
+	echo "● nginx.service loaded failed failed  A high performance web server"
+	;;
+show)
+	echo "Id=nginx.service"
+	echo "LoadState=loaded"
+	echo "ActiveState=active"
+	echo "SubState=running"
+	echo "Description=A high performance web server"
+	;;
+start)
+	echo "Job for nginx.service failed because the control process exited with error code." >&2
+	exit 1
+	;;
+esac
+`,
+		"journalctl": `#!/bin/sh
+echo "journalctl $*" >> "` + log + `"
+echo "2024-01-02T03:04:05+0000 host nginx[1]: started"
+echo "2024-01-02T03:04:06+0000 host nginx[1]: listening on :80"
+`,
+	}
+	for name, content := range stubs {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return func() []string {
+		data, _ := os.ReadFile(log)
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+}
+
+func TestServiceList(t *testing.T) {
+	calls := setupServiceStubs(t)
+
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	req := biz.ServiceListRequest{All: true}
+	reqBytes, _ := req.MarshalMsg(nil)
+	ts.ChToAgent <- utils.JoinBytes2(0x35, []byte{1, 0, 0, 0}, reqBytes)
+
+	recv := readWithTimeout(ts.ChFromAgent)
+	if len(recv) < 5 || bytes2hex(recv[:5]) != "3501000000" {
+		t.Fatalf("did not recv service list: %s", bytes2hex(recv))
+	}
+	resp := biz.ServiceListResponse{}
+	if _, err := resp.UnmarshalMsg(recv[5:]); err != nil {
+		t.Fatalf("failed to unmarshal service list: %s", err.Error())
+	}
+	Assert(t, resp.Error == "", "no error: "+resp.Error)
+	Assert(t, len(resp.Units) == 2, "2 units")
+	Assert(t, resp.Units[0] == biz.ServiceUnit{Unit: "cron.service", Load: "loaded", Active: "active", Sub: "running", Description: "Regular background program processing daemon"}, "cron unit")
+	Assert(t, resp.Units[1].Unit == "nginx.service" && resp.Units[1].Active == "failed", "failed unit without bullet")
+	Assert(t, calls()[0] == "systemctl list-units --no-legend --no-pager --plain --full --type=service --all", "args: "+calls()[0])
+}
+
+func TestServiceAction(t *testing.T) {
+	calls := setupServiceStubs(t)
+
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	action := func(unit, action string) biz.ServiceActionResponse {
+		req := biz.ServiceActionRequest{Unit: unit, Action: action}
+		reqBytes, _ := req.MarshalMsg(nil)
+		ts.ChToAgent <- utils.JoinBytes2(0x36, []byte{2, 0, 0, 0}, reqBytes)
+
+		recv := readWithTimeout(ts.ChFromAgent)
+		if len(recv) < 5 || bytes2hex(recv[:5]) != "3602000000" {
+			t.Fatalf("did not recv service action response: %s", bytes2hex(recv))
+		}
+		resp := biz.ServiceActionResponse{}
+		resp.UnmarshalMsg(recv[5:])
+		return resp
+	}
+
+	resp := action("nginx.service", "restart")
+	Assert(t, resp.Error == "", "no error: "+resp.Error)
+	Assert(t, resp.Unit.Active == "active" && resp.Unit.Sub == "running", "state after restart")
+
+	resp = action("nginx.service", "start")
+	Assert(t, strings.Contains(resp.Error, "control process exited"), "error has stderr: "+resp.Error)
+	Assert(t, resp.Unit.Unit == "nginx.service", "state is reported on failure")
+
+	Assert(t, strings.Contains(action("nginx.service", "mask").Error, "unknown action"), "unknown action")
+	Assert(t, strings.Contains(action("--all", "stop").Error, "invalid unit name"), "option as unit")
+	Assert(t, strings.Contains(action("a b", "stop").Error, "invalid unit name"), "space in unit")
+
+	list := calls()
+	Assert(t, len(list) == 4, "rejected requests did not run systemctl")
+	Assert(t, list[0] == "systemctl restart --no-ask-password -- nginx.service", "args: "+list[0])
+	Assert(t, list[1] == "systemctl show --property=Id,LoadState,ActiveState,SubState,Description -- nginx.service", "args: "+list[1])
+}
+
+func TestServiceJournal(t *testing.T) {
+	calls := setupServiceStubs(t)
+
+	ts := makeTestSession()
+	defer ts.TerminateSession()
+	go ts.Run()
+
+	req := biz.ServiceJournalRequest{Unit: "nginx.service", Lines: 20, Since: "1 hour ago"}
+	reqBytes, _ := req.MarshalMsg(nil)
+	ts.ChToAgent <- utils.JoinBytes2(0x37, []byte{3, 0, 0, 0}, reqBytes)
+
+	recv := readWithTimeout(ts.ChFromAgent)
+	if len(recv) < 5 || bytes2hex(recv[:5]) != "3703000000" {
+		t.Fatalf("did not recv journal: %s", bytes2hex(recv))
+	}
+	resp := biz.ServiceJournalResponse{}
+	if _, err := resp.UnmarshalMsg(recv[5:]); err != nil {
+		t.Fatalf("failed to unmarshal journal: %s", err.Error())
+	}
+	Assert(t, resp.Error == "", "no error: "+resp.Error)
+	Assert(t, len(resp.Lines) == 2 && strings.HasSuffix(resp.Lines[1], "listening on :80"), "journal lines")
+	Assert(t, calls()[0] == "journalctl --no-pager --output=short-iso --lines=20 --unit=nginx.service --since=1 hour ago", "args: "+calls()[0])
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"remote-agent/agent/agent_common"
 	"remote-agent/agent/agent_omni"
 	"remote-agent/agent/agent_upgrade"
@@ -32,6 +33,13 @@ func listen() {
 			return err
 		}
 		req.Header.Set("User-Agent", biz.UserAgent)
+		if len(biz.Config.Labels) > 0 {
+			labels := neturl.Values{}
+			for k, v := range biz.Config.Labels {
+				labels.Set(k, v)
+			}
+			req.Header.Set("X-Agent-Labels", labels.Encode())
+		}
 		req = req.WithContext(ctx)
 
 		client := agent_common.MakeHttpClient()
@@ -2,18 +2,38 @@ package agent
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"os/exec"
 	"remote-agent/agent/agent_common"
 	"remote-agent/biz"
 	"remote-agent/utils"
-	"sync"
 	"syscall"
+	"time"
 )
 
+// how long to wait for output after the command exits
+const outputWaitDelay = 5 * time.Second
+
+// output of command, sent to upstream in packages of [prefix]+data. discarded if not enabled
+type outputWriter struct {
+	ws      *utils.RWChan
+	prefix  byte
+	enabled bool
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	if !w.enabled {
+		return len(p), nil
+	}
+	for i := 0; i < len(p); i += 1024 {
+		w.ws.Write(utils.PrependBytes([]byte{w.prefix}, p[i:min(i+1024, len(p))]))
+	}
+	return len(p), nil
+}
+
 func run_shell(task *biz.AgentNotify) {
 	c, err := agent_common.MakeWsConn(task.Id)
 	if err != nil {
@@ -22,8 +42,6 @@ func run_shell(task *biz.AgentNotify) {
 	ws := utils.MakeRWChanFromWebSocket(c)
 	defer ws.Close()
 
-	wg := sync.WaitGroup{}
-
 	// ---- setup process
 
 	cmd := exec.Command("sh", "-c", task.Cmd)
@@ -36,48 +54,19 @@ func run_shell(task *biz.AgentNotify) {
 
 	// pipes
 
-	stdin, err0 := cmd.StdinPipe()
-	stdout, err1 := cmd.StdoutPipe()
-	stderr, err2 := cmd.StderrPipe()
-
-	if err0 != nil {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
 		print_error_message(fmt.Sprintln("failed to get stdin pipe:", err))
 		return
 	}
-	if err1 != nil {
-		print_error_message(fmt.Sprintln("failed to get stdout pipe:", err1))
-		return
-	}
-	if err2 != nil {
-		print_error_message(fmt.Sprintln("failed to get stderr pipe:", err2))
-		return
-	}
 
 	// -- setup stdout/stderr
 
-	pipeOutputToUpstream := func(prefix byte, enabled bool, r io.ReadCloser) {
-		defer wg.Done()
-
-		defer r.Close()
-
-		for {
-			data := make([]byte, 1025)
-			data[0] = prefix
-			n, err := r.Read(data[1:])
-			if err != nil {
-				break
-			}
-
-			// discard if not enabled
-			if enabled {
-				ws.Write(data[:n+1])
-			}
-		}
-	}
-
-	wg.Add(2)
-	go pipeOutputToUpstream(0x01, task.NeedStdout, stdout)
-	go pipeOutputToUpstream(0x02, task.NeedStderr, stderr)
+	// copied by exec, so Wait returns after all output is sent.
+	// a background child may keep the pipes open, so stop waiting for them shortly after the command exits
+	cmd.Stdout = &outputWriter{ws: ws, prefix: 0x01, enabled: task.NeedStdout}
+	cmd.Stderr = &outputWriter{ws: ws, prefix: 0x02, enabled: task.NeedStderr}
+	cmd.WaitDelay = outputWaitDelay
 
 	// -- start
 
@@ -87,12 +76,9 @@ func run_shell(task *biz.AgentNotify) {
 	}
 	log.Println("start command:", task.Cmd, "pid:", cmd.Process.Pid)
 
-	// handle data from client
+	// handle data from client. it ends when connection is closed
 
-	wg.Add(1)
 	go func() {
-		defer wg.Done()
-
 		// note: remote will close stdin.
 		// has_stdin := task.HasStdin
 		// if !has_stdin {
@@ -146,7 +132,11 @@ func run_shell(task *biz.AgentNotify) {
 	}()
 
 	if err := cmd.Wait(); err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
+		if errors.Is(err, exec.ErrWaitDelay) {
+			// exited, but output is still held open by another process
+			log.Println("shell: output not closed in", outputWaitDelay, "after exit")
+			code = int32(cmd.ProcessState.ExitCode())
+		} else if exiterr, ok := err.(*exec.ExitError); ok {
 			code = int32(exiterr.ExitCode())
 		} else {
 			print_error_message(fmt.Sprintln("failed to wait command:", err))
@@ -162,6 +152,4 @@ func run_shell(task *biz.AgentNotify) {
 	data := []byte{0x00, 0xff, 0xff, 0xff, 0xff}
 	binary.LittleEndian.PutUint32(data[1:], uint32(code))
 	ws.Write(data)
-
-	wg.Wait()
 }
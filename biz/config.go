@@ -1,9 +1,14 @@
 package biz
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -19,16 +24,124 @@ type AgentConfig struct {
 	APIKey          string             `yaml:"api_key"`           // API key for client API. If set, must provided via `X-API-Key` header or `Authorization: Bearer <api_key>` header
 	ProxyServerHost string             `yaml:"proxy_server_host"` // like `foo-*.your-domain.com`. must contain `*`
 	ProxyServices   []SavedProxyConfig `yaml:"proxy_services"`
+	TcpServices     []SavedTcpConfig   `yaml:"tcp_services"`
+	ForwardProxy    string             `yaml:"forward_proxy"`   // optional. listen address of HTTP forward proxy (CONNECT), like "127.0.0.1:3128"
+	Registry        string             `yaml:"registry"`        // optional. JSON file of known agents, defaults to "agents.json". "-" to keep in memory only
+	Webhooks        []WebhookConfig    `yaml:"webhooks"`        // optional. server events are posted to them
+	MetricsToken    string             `yaml:"metrics_token"`   // optional. bearer token for /metrics, accepted besides API key
+	TrustedProxies  []string           `yaml:"trusted_proxies"` // optional. IPs or CIDRs of reverse proxies in front of server, whose X-Real-IP and X-Forwarded-* are trusted
 
 	// for agent
 	BaseUrl  string `yaml:"base_url"` // base url, including protocol and port, without `/api`
 	Insecure bool
+	Labels   map[string]string `yaml:"labels"` // optional. reported to server, like {env: prod}
 
 	// for client (port forwarding CLI)
 	AsClient       bool     `yaml:"as_client"`
 	ClientForwards []string `yaml:"-"` // command-line only: localPort:remoteAddr:remotePort
+	ClientUdp      []string `yaml:"-"` // command-line only: localPort:remoteAddr:remotePort, for udp
+	ClientSocks    []string `yaml:"-"` // command-line only: local ports of SOCKS5 proxy
+	ClientReverse  []string `yaml:"-"` // command-line only: remotePort:localAddr:localPort, listened by agent
+	ClientArgs     []string `yaml:"-"` // command-line only: subcommand and its args, like exec, shell, cp, sync, list
+	SocksAuth      string   `yaml:"-"` // command-line only: user:password of SOCKS5 proxy. optional
+	CACert         string   `yaml:"-"` // from client profile: PEM file of CA to trust. optional
+	Profile        string   `yaml:"-"` // name of the client profile in use. empty if none
 }
 
+// client config file, with profiles of servers. see LoadClientProfile
+type ClientConfig struct {
+	DefaultProfile string                    `yaml:"default_profile"`
+	Profiles       map[string]*ClientProfile `yaml:"profiles"`
+}
+
+type ClientProfile struct {
+	BaseUrl  string                 `yaml:"base_url"`
+	APIKey   string                 `yaml:"api_key"` // supports $ENV
+	Insecure bool                   `yaml:"insecure"`
+	CACert   string                 `yaml:"ca_cert"`  // optional. PEM file of CA to trust, like a self-signed server
+	Agent    string                 `yaml:"agent"`    // default agent name
+	Forwards map[string]*ForwardSet `yaml:"forwards"` // named sets of forwards, launched by `forward NAME...`
+}
+
+// a saved set of forwards, in the format of the flags
+type ForwardSet struct {
+	Agent   string   `yaml:"agent"`   // optional. defaults to the profile's
+	Local   []string `yaml:"local"`   // like -L
+	Udp     []string `yaml:"udp"`     // like -U
+	Reverse []string `yaml:"reverse"` // like -R
+	Socks   []string `yaml:"socks"`   // like -D
+}
+
+// merge named forward sets into one. they must not target different agents
+func (p *ClientProfile) MergeForwardSets(names []string) (*ForwardSet, error) {
+	out := &ForwardSet{}
+	for _, name := range names {
+		set, ok := p.Forwards[name]
+		if !ok {
+			available := make([]string, 0, len(p.Forwards))
+			for k := range p.Forwards {
+				available = append(available, k)
+			}
+			sort.Strings(available)
+			return nil, fmt.Errorf("forward set %q not found. available: %s", name, strings.Join(available, ", "))
+		}
+		if set == nil {
+			continue // a key without value, like "db:" in yaml
+		}
+		if set.Agent != "" && out.Agent != "" && set.Agent != out.Agent {
+			return nil, fmt.Errorf("forward sets target different agents: %s and %s", out.Agent, set.Agent)
+		}
+		if set.Agent != "" {
+			out.Agent = set.Agent
+		}
+		out.Local = append(out.Local, set.Local...)
+		out.Udp = append(out.Udp, set.Udp...)
+		out.Reverse = append(out.Reverse, set.Reverse...)
+		out.Socks = append(out.Socks, set.Socks...)
+	}
+	return out, nil
+}
+
+// DefaultClientConfigPath is like ~/.config/remote-agent/client.yaml
+func DefaultClientConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "client.yaml"
+	}
+	return filepath.Join(dir, "remote-agent", "client.yaml")
+}
+
+// load a client profile by name, or the default profile of the file.
+// returns nil if no profile is chosen, or the file doesn't exist when name is not given
+func LoadClientProfile(path, name string) (*ClientConfig, *ClientProfile, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && name == "" {
+			return nil, nil, "", nil
+		}
+		return nil, nil, "", err
+	}
+
+	cfg := &ClientConfig{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, nil, "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if name == "" {
+		name = cfg.DefaultProfile
+	}
+	if name == "" {
+		return cfg, nil, "", nil
+	}
+	profile, ok := cfg.Profiles[name]
+	if !ok || profile == nil {
+		return nil, nil, "", fmt.Errorf("profile %q not found in %s", name, path)
+	}
+	return cfg, profile, name, nil
+}
+
+// loaded client config file. nil if not exist
+var ClientProfiles *ClientConfig
+
 // multiFlag allows a flag to be specified multiple times
 type MultiFlag []string
 
@@ -41,6 +154,89 @@ type SavedProxyConfig struct {
 	// AgentId   string `yaml:"agent_id"`		// not supported -- id may change
 	Target      string `yaml:"target"`
 	ReplaceHost string `yaml:"replace_host"`
+	PoolSize    int    `yaml:"pool_size"` // connections to agent instances. defaults to 1
+	Balance     string `yaml:"balance"`   // "round_robin" (default) or "least_inflight"
+
+	Routes []ProxyRoute `yaml:"routes"` // optional. checked in order, before the default Target
+
+	Auth      string   `yaml:"auth"`       // "none" (default), "basic" or "api_key" (login with server API key)
+	BasicAuth []string `yaml:"basic_auth"` // for "basic" auth. like "user:password"
+	AllowIPs  []string `yaml:"allow_ips"`  // optional. IPs or CIDRs allowed to access
+
+	Rewrite ProxyRewrite `yaml:"rewrite"` // optional
+
+	AccessLog     string `yaml:"access_log"`      // "off" (default), "combined" or "json"
+	AccessLogFile string `yaml:"access_log_file"` // optional. defaults to server log
+}
+
+// a server port forwarded to agent, like teammates reaching agent's Postgres
+type SavedTcpConfig struct {
+	Listen    string `yaml:"listen"` // like ":15432" or "127.0.0.1:15432"
+	AgentName string `yaml:"agent_name"`
+	// AgentId   string `yaml:"agent_id"`		// not supported -- id may change
+	Target   string   `yaml:"target"`    // dialed by agent, like "127.0.0.1:5432"
+	PoolSize int      `yaml:"pool_size"` // connections to agent instances. defaults to 1
+	Balance  string   `yaml:"balance"`   // "round_robin" (default) or "least_inflight"
+	AllowIPs []string `yaml:"allow_ips"` // optional. IPs or CIDRs allowed to connect
+}
+
+// rewrite rules of proxy service
+type ProxyRewrite struct {
+	RequestHeaders  HeaderRules `yaml:"request_headers" json:"request_headers"`
+	ResponseHeaders HeaderRules `yaml:"response_headers" json:"response_headers"`
+
+	ForwardedHeaders bool `yaml:"forwarded_headers" json:"forwarded_headers"` // add X-Forwarded-For, X-Forwarded-Host and X-Forwarded-Proto
+	Location         bool `yaml:"location" json:"location"`                   // rewrite absolute Location pointing at target (or replace_host) to the public host
+	CookieDomain     bool `yaml:"cookie_domain" json:"cookie_domain"`         // rewrite Domain of Set-Cookie to the public host
+	PassRedirects    bool `yaml:"pass_redirects" json:"pass_redirects"`       // pass redirects of target to user, instead of following them on agent. implied by Location
+
+	// string substitution in text bodies (text/*, json, javascript, xml).
+	// the whole body is buffered, up to BodyLimit (defaults to 8MB). bigger bodies are sent as-is
+	Body      []BodyReplace `yaml:"body" json:"body"`
+	BodyLimit int64         `yaml:"body_limit" json:"body_limit"`
+}
+
+type WebhookConfig struct {
+	URL    string   `yaml:"url"`
+	Secret string   `yaml:"secret,omitempty"` // optional. signs body with HMAC-SHA256, in X-Signature-256 header. supports $ENV
+	Events []string `yaml:"events,omitempty"` // optional. event types like "agent.leave" or "agent.*". defaults to all
+}
+
+// secret with $ENV resolved
+func (h WebhookConfig) SecretValue() string {
+	return maybeEnv(h.Secret)
+}
+
+// applied in order: Remove, Set, Add
+type HeaderRules struct {
+	Remove []string          `yaml:"remove,omitempty" json:"remove,omitempty"`
+	Set    map[string]string `yaml:"set,omitempty" json:"set,omitempty"`
+	Add    map[string]string `yaml:"add,omitempty" json:"add,omitempty"`
+}
+
+type BodyReplace struct {
+	From string `yaml:"from" json:"from"`
+	To   string `yaml:"to" json:"to"`
+}
+
+// a routing rule of proxy service. the first matched route handles the request
+type ProxyRoute struct {
+	// match by path prefix (like "/api/") or regex. one of them is required
+	Path      string `yaml:"path,omitempty" json:"path,omitempty"`
+	PathRegex string `yaml:"path_regex,omitempty" json:"path_regex,omitempty"`
+
+	Methods []string          `yaml:"methods,omitempty" json:"methods,omitempty"` // optional. like ["GET", "POST"]
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"` // optional. header must equal the value. empty value means header must exist
+
+	AgentName string `yaml:"agent_name,omitempty" json:"agent_name,omitempty"` // optional. defaults to the service's
+	AgentId   string `yaml:"-" json:"agent_id,omitempty"`                      // optional. id may change, so not saved
+	Target    string `yaml:"target" json:"target"`                             // like "http://127.0.0.1:8080"
+
+	// rewrite the path before appending to Target.
+	// StripPrefix removes the matched Path.
+	// Rewrite replaces the matched Path, or for PathRegex, it's the replacement template (like "/v2/$1")
+	StripPrefix bool   `yaml:"strip_prefix,omitempty" json:"strip_prefix,omitempty"`
+	Rewrite     string `yaml:"rewrite,omitempty" json:"rewrite,omitempty"`
 }
 
 var Config AgentConfig
@@ -70,7 +266,7 @@ func WriteConfigFile() error {
 func InitConfig() {
 	configPath := flag.String("c", "config.yaml", "Config path")
 	asAgent := flag.Bool("a", false, "Set agent mode")
-	asClient := flag.Bool("client", false, "Set client mode (TCP port forwarding)")
+	asClient := flag.Bool("client", false, "Set client mode (port forwarding, or a command: list, exec, shell, cp, sync, forward, profiles)")
 	name := flag.String("n", "", "Agent name")
 	baseUrl := flag.String("b", "", "Base URL (for agent or client)")
 	insecure := flag.Bool("i", false, "Insecure TLS (for agent or client)")
@@ -78,6 +274,15 @@ func InitConfig() {
 	proxy_server_host := flag.String("psh", "", "Proxy server host (only for server, must contains *)")
 	var clientForwards MultiFlag
 	flag.Var(&clientForwards, "L", "Port forward (client mode): localPort:remoteAddr:remotePort (repeatable)")
+	var clientUdp MultiFlag
+	flag.Var(&clientUdp, "U", "UDP port forward (client mode): localPort:remoteAddr:remotePort (repeatable)")
+	var clientReverse MultiFlag
+	flag.Var(&clientReverse, "R", "Reverse port forward (client mode): remotePort:localAddr:localPort (repeatable)")
+	var clientSocks MultiFlag
+	flag.Var(&clientSocks, "D", "SOCKS5 proxy (client mode): localPort (repeatable)")
+	socksAuth := flag.String("socks_auth", "", "user:password required by SOCKS5 proxy (client mode)")
+	profileName := flag.String("profile", "", "Client profile name (client mode). defaults to default_profile of the client config")
+	clientConfigPath := flag.String("client_config", DefaultClientConfigPath(), "Client config path, with profiles (client mode)")
 	flag.Parse()
 
 	if data, err := os.ReadFile(maybeEnv(*configPath)); err == nil {
@@ -107,23 +312,84 @@ func InitConfig() {
 	if len(clientForwards) > 0 {
 		Config.ClientForwards = clientForwards
 	}
+	if len(clientUdp) > 0 {
+		Config.ClientUdp = clientUdp
+	}
+	if len(clientSocks) > 0 {
+		Config.ClientSocks = clientSocks
+	}
+	if len(clientReverse) > 0 {
+		Config.ClientReverse = clientReverse
+	}
+	if *socksAuth != "" {
+		Config.SocksAuth = maybeEnv(*socksAuth)
+	}
+	Config.ClientArgs = flag.Args()
 
 	// defaults
 	if Config.AsClient {
+		profiles, profile, profileName, err := LoadClientProfile(maybeEnv(*clientConfigPath), *profileName)
+		if err != nil {
+			log.Fatalf("Failed to load client profile: %v", err)
+		}
+		ClientProfiles = profiles
+		Config.Profile = profileName
+		if profile != nil {
+			// flags win over the profile
+			if *name == "" && profile.Agent != "" {
+				Config.Name = maybeEnv(profile.Agent)
+			}
+			if *api_key == "" && profile.APIKey != "" {
+				Config.APIKey = maybeEnv(profile.APIKey)
+			}
+			if profile.BaseUrl != "" {
+				Config.BaseUrl = maybeEnv(profile.BaseUrl)
+			}
+			Config.Insecure = Config.Insecure || profile.Insecure
+			Config.CACert = maybeEnv(profile.CACert)
+		}
+
+		// `forward NAME...` launches saved forward sets, along with forwards in flags
+		if len(Config.ClientArgs) > 0 && Config.ClientArgs[0] == "forward" {
+			if profile == nil {
+				log.Fatalf("forward: no client profile in use (-profile, or default_profile in %s)", *clientConfigPath)
+			}
+			if len(Config.ClientArgs) < 2 {
+				log.Fatalf("forward: name of forward set is required")
+			}
+			set, err := profile.MergeForwardSets(Config.ClientArgs[1:])
+			if err != nil {
+				log.Fatalf("forward: %v", err)
+			}
+			if *name == "" && set.Agent != "" {
+				Config.Name = maybeEnv(set.Agent)
+			}
+			Config.ClientForwards = append(Config.ClientForwards, set.Local...)
+			Config.ClientUdp = append(Config.ClientUdp, set.Udp...)
+			Config.ClientReverse = append(Config.ClientReverse, set.Reverse...)
+			Config.ClientSocks = append(Config.ClientSocks, set.Socks...)
+			Config.ClientArgs = nil
+		}
+
 		if *baseUrl != "" {
 			Config.BaseUrl = maybeEnv(*baseUrl)
 		}
 		if *insecure {
 			Config.Insecure = true
 		}
-		if Config.Name == "" {
+		hasCommand := len(Config.ClientArgs) > 0
+		command := ""
+		if hasCommand {
+			command = Config.ClientArgs[0]
+		}
+		if Config.Name == "" && command != "list" && command != "profiles" {
 			log.Fatalf("Agent name (-n) is required for client mode")
 		}
-		if Config.BaseUrl == "" {
+		if Config.BaseUrl == "" && command != "profiles" {
 			log.Fatalf("Server URL (-b) is required for client mode")
 		}
-		if len(Config.ClientForwards) == 0 {
-			log.Fatalf("At least one port forward (-L localPort:remoteAddr:remotePort) is required")
+		if !hasCommand && len(Config.ClientForwards) == 0 && len(Config.ClientUdp) == 0 && len(Config.ClientSocks) == 0 && len(Config.ClientReverse) == 0 {
+			log.Fatalf("At least one port forward (-L, -U, -R or -D) or a command (list, exec, shell, cp, sync, forward, profiles) is required")
 		}
 	} else if Config.AsAgent {
 		if *baseUrl != "" {
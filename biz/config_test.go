@@ -0,0 +1,89 @@
+package biz
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testClientConfig = `
+default_profile: prod
+profiles:
+  prod:
+    base_url: https://ra.example.com
+    agent: db-host
+    forwards:
+      db:
+        local: ["5432:localhost:5432"]
+      web:
+        agent: web-host
+        local: ["8080:localhost:80"]
+        socks: ["1080"]
+      other:
+        agent: other-host
+        reverse: ["9000:localhost:3000"]
+      empty:
+  staging:
+    base_url: http://10.0.0.5:8080
+    insecure: true
+`
+
+func TestLoadClientProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client.yaml")
+	if err := os.WriteFile(path, []byte(testClientConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, p, name, err := LoadClientProfile(path, "")
+	if err != nil || name != "prod" || p.BaseUrl != "https://ra.example.com" || p.Agent != "db-host" {
+		t.Errorf("default profile: %q %+v %v", name, p, err)
+	}
+	_, p, name, err = LoadClientProfile(path, "staging")
+	if err != nil || name != "staging" || !p.Insecure {
+		t.Errorf("named profile: %q %+v %v", name, p, err)
+	}
+	if _, _, _, err = LoadClientProfile(path, "nope"); err == nil {
+		t.Errorf("expected error for unknown profile")
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+	cfg, p, _, err := LoadClientProfile(missing, "")
+	if err != nil || cfg != nil || p != nil {
+		t.Errorf("missing file without profile: %v %v %v", cfg, p, err)
+	}
+	if _, _, _, err = LoadClientProfile(missing, "prod"); err == nil {
+		t.Errorf("expected error for missing file with profile")
+	}
+}
+
+func TestMergeForwardSets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client.yaml")
+	os.WriteFile(path, []byte(testClientConfig), 0644)
+	_, p, _, _ := LoadClientProfile(path, "prod")
+
+	set, err := p.MergeForwardSets([]string{"db", "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ForwardSet{
+		Agent: "web-host",
+		Local: []string{"5432:localhost:5432", "8080:localhost:80"},
+		Socks: []string{"1080"},
+	}
+	if !reflect.DeepEqual(set, want) {
+		t.Errorf("got %+v, want %+v", set, want)
+	}
+
+	set, err = p.MergeForwardSets([]string{"empty", "db"})
+	if err != nil || !reflect.DeepEqual(set.Local, []string{"5432:localhost:5432"}) {
+		t.Errorf("set without value: %+v %v", set, err)
+	}
+
+	if _, err := p.MergeForwardSets([]string{"web", "other"}); err == nil {
+		t.Errorf("expected error for sets of different agents")
+	}
+	if _, err := p.MergeForwardSets([]string{"nope"}); err == nil {
+		t.Errorf("expected error for unknown set")
+	}
+}
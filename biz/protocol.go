@@ -20,6 +20,52 @@ type FileInfo struct {
 	Size  int64  `msg:"size"`
 	Mode  uint32 `msg:"mode"`
 	Mtime int64  `msg:"mtime"`
+	Error string `msg:"error"` // non-empty if the entry exists but cannot be stat-ed
+}
+
+type ListDirRequest struct {
+	Path       string `msg:"path"`
+	Cursor     string `msg:"cursor"`      // NextCursor of previous page. empty for the first page
+	Limit      int32  `msg:"limit"`       // max entries per page. 0 = 1000
+	SortBy     string `msg:"sort_by"`     // name (default), size, mtime
+	Desc       bool   `msg:"desc"`        // descending order
+	ShowHidden bool   `msg:"show_hidden"` // include entries starting with "."
+	CountOnly  bool   `msg:"count_only"`  // only fill Total, no Entries
+}
+
+type ListDirResponse struct {
+	Path       string     `msg:"path"`
+	Entries    []FileInfo `msg:"entries"`
+	Total      int64      `msg:"total"`       // count of all entries matching the filter, not only this page
+	NextCursor string     `msg:"next_cursor"` // empty if this is the last page
+	Error      string     `msg:"error"`
+}
+
+type SyncTreeRequest struct {
+	Path string `msg:"path"`
+}
+
+type SyncTreeResponse struct {
+	Entries  []FileInfo `msg:"entries"`   // all entries under Path, recursively. FileInfo.Path is relative and slash separated
+	NotExist bool       `msg:"not_exist"` // Path doesn't exist. Error is empty in this case
+	Error    string     `msg:"error"`
+}
+
+type BlockSumRequest struct {
+	Path      string `msg:"path"`
+	BlockSize int64  `msg:"block_size"`
+}
+
+type BlockSumResponse struct {
+	Size  int64    `msg:"size"`
+	Sums  [][]byte `msg:"sums"` // sha256 of each block
+	Error string   `msg:"error"`
+}
+
+type SetAttrRequest struct {
+	Path  string `msg:"path"`
+	Mode  uint32 `msg:"mode"`  // permission bits. 0 = unchanged
+	Mtime int64  `msg:"mtime"` // unix seconds. 0 = unchanged
 }
 
 type StartPtyRequest struct {
@@ -35,6 +81,24 @@ type ProxyHttpRequest struct {
 	Headers []ProxyHttpHeader `msg:"headers"`
 	Host    string            `msg:"host"` // golang http client will use this field to replace Host header
 	Body    []byte            `msg:"body"` // beware: disallowed for ws:// or wss://
+
+	// if true, Body is empty, and the body is sent in 0x21 packages after this request, ended with a 0x24 package.
+	// only for agents with "body_stream" feature
+	BodyStream bool `msg:"body_stream"`
+
+	// flow control window of each direction, in bytes. 0 = no flow control.
+	// only for agents with "window" feature
+	Window uint32 `msg:"window"`
+
+	// if true, redirects of target are returned as-is, not followed. older agents ignore it and follow them
+	NoFollowRedirects bool `msg:"no_follow_redirects"`
+}
+
+// open a proxy channel (0x26)
+type ProxyOpenRequest struct {
+	Network string `msg:"network"` // "tcp" (default)
+	Address string `msg:"address"` // like "127.0.0.1:80"
+	Window  uint32 `msg:"window"`  // flow control window of each direction, in bytes. 0 = no flow control
 }
 
 type ProxyHttpResponse struct {
@@ -69,3 +133,175 @@ func ToHttpRequestHeaders(v []ProxyHttpHeader) map[string][]string {
 	}
 	return ans
 }
+
+type DiskUsageRequest struct {
+	Path             string `msg:"path"`
+	MaxDepth         int32  `msg:"max_depth"`            // directories deeper than this are summed into their parents. 0 = only Path itself
+	TopN             int32  `msg:"top_n"`                // number of largest files reported in Top. 0 = 20
+	OneFileSystem    bool   `msg:"one_file_system"`      // do not cross mount points, like `du -x`
+	ProgressInterval int32  `msg:"progress_interval_ms"` // interval of partial reports. 0 = 1000ms
+}
+
+type DiskUsageEntry struct {
+	Path  string `msg:"path" json:"path"`
+	IsDir bool   `msg:"is_dir" json:"is_dir"`
+	Size  int64  `msg:"size" json:"size"`   // apparent size in bytes
+	Usage int64  `msg:"usage" json:"usage"` // allocated size on disk in bytes
+	Files int64  `msg:"files" json:"files"` // count of files inside, for directories
+}
+
+type FsStat struct {
+	Device     string `msg:"device" json:"device"`
+	MountPoint string `msg:"mount_point" json:"mount_point"`
+	FsType     string `msg:"fs_type" json:"fs_type"`
+	Total      uint64 `msg:"total" json:"total"`             // in bytes
+	Free       uint64 `msg:"free" json:"free"`               // free bytes, including reserved blocks
+	Avail      uint64 `msg:"avail" json:"avail"`             // free bytes for unprivileged users
+	Inodes     uint64 `msg:"inodes" json:"inodes"`           // total inodes
+	InodesFree uint64 `msg:"inodes_free" json:"inodes_free"` // free inodes
+}
+
+// disk usage report. partial reports are sent while scanning, and the last one has Done set
+type DiskUsageReport struct {
+	Path         string           `msg:"path" json:"path"`
+	Done         bool             `msg:"done" json:"done"`
+	Error        string           `msg:"error" json:"error,omitempty"`
+	ScannedDirs  int64            `msg:"scanned_dirs" json:"scanned_dirs"`
+	ScannedFiles int64            `msg:"scanned_files" json:"scanned_files"`
+	Unreadable   int64            `msg:"unreadable" json:"unreadable"` // count of entries that cannot be read
+	Entries      []DiskUsageEntry `msg:"entries" json:"entries"`       // Path and its sub directories up to MaxDepth, sorted by path
+	Top          []DiskUsageEntry `msg:"top" json:"top"`               // largest files, sorted by usage
+	Filesystems  []FsStat         `msg:"filesystems" json:"filesystems,omitempty"`
+}
+
+type AgentMetricsRequest struct {
+	TopN     int32 `msg:"top_n"`     // number of top processes, by CPU. 0 = 10
+	SampleMs int32 `msg:"sample_ms"` // CPU usage is measured over this interval. 0 = 250ms
+}
+
+// a snapshot of agent's host. fields not supported by the platform are zero
+type AgentMetrics struct {
+	Time     int64  `msg:"time" json:"time"` // unix milliseconds
+	Hostname string `msg:"hostname" json:"hostname"`
+	OS       string `msg:"os" json:"os"`
+	Arch     string `msg:"arch" json:"arch"`
+	Uptime   int64  `msg:"uptime" json:"uptime"` // seconds since boot
+
+	NumCPU     int32   `msg:"num_cpu" json:"num_cpu"`
+	CPUPercent float64 `msg:"cpu_percent" json:"cpu_percent"` // busy time of all CPUs during the sample, 0-100
+	Load1      float64 `msg:"load1" json:"load1"`
+	Load5      float64 `msg:"load5" json:"load5"`
+	Load15     float64 `msg:"load15" json:"load15"`
+
+	MemTotal     uint64 `msg:"mem_total" json:"mem_total"` // in bytes
+	MemAvailable uint64 `msg:"mem_available" json:"mem_available"`
+	SwapTotal    uint64 `msg:"swap_total" json:"swap_total"`
+	SwapFree     uint64 `msg:"swap_free" json:"swap_free"`
+
+	Filesystems []FsStat      `msg:"filesystems" json:"filesystems"`
+	Network     []NetStat     `msg:"network" json:"network"`
+	Processes   []ProcessStat `msg:"processes" json:"processes"` // top processes by CPU
+	Agent       AgentRuntime  `msg:"agent" json:"agent"`
+	Errors      []string      `msg:"errors" json:"errors,omitempty"` // parts failed to collect
+}
+
+// counters of a network interface, since boot
+type NetStat struct {
+	Interface string `msg:"interface" json:"interface"`
+	RxBytes   uint64 `msg:"rx_bytes" json:"rx_bytes"`
+	TxBytes   uint64 `msg:"tx_bytes" json:"tx_bytes"`
+	RxPackets uint64 `msg:"rx_packets" json:"rx_packets"`
+	TxPackets uint64 `msg:"tx_packets" json:"tx_packets"`
+	RxErrors  uint64 `msg:"rx_errors" json:"rx_errors"`
+	TxErrors  uint64 `msg:"tx_errors" json:"tx_errors"`
+}
+
+type ProcessStat struct {
+	Pid        int32   `msg:"pid" json:"pid"`
+	Name       string  `msg:"name" json:"name"`
+	CPUPercent float64 `msg:"cpu_percent" json:"cpu_percent"` // of one CPU, may exceed 100
+	RSS        uint64  `msg:"rss" json:"rss"`                 // resident memory in bytes
+}
+
+// the agent process itself
+type AgentRuntime struct {
+	Version    string `msg:"version" json:"version"`
+	Pid        int32  `msg:"pid" json:"pid"`
+	Uptime     int64  `msg:"uptime" json:"uptime"` // seconds since agent started
+	Goroutines int32  `msg:"goroutines" json:"goroutines"`
+	HeapAlloc  uint64 `msg:"heap_alloc" json:"heap_alloc"` // bytes of allocated heap objects
+	Sys        uint64 `msg:"sys" json:"sys"`               // bytes obtained from OS
+}
+
+type ProcessListRequest struct {
+	Tree     bool  `msg:"tree"`      // nest processes under their parents
+	SampleMs int32 `msg:"sample_ms"` // CPU usage is measured over this interval. 0 = 250ms
+}
+
+type ProcessInfo struct {
+	Pid        int32         `msg:"pid" json:"pid"`
+	Ppid       int32         `msg:"ppid" json:"ppid"`
+	User       string        `msg:"user" json:"user"` // user name, or uid if unknown
+	Name       string        `msg:"name" json:"name"`
+	Cmdline    string        `msg:"cmdline" json:"cmdline"` // arguments joined by space. empty for kernel threads
+	State      string        `msg:"state" json:"state"`     // like "R", "S", "Z"
+	Threads    int32         `msg:"threads" json:"threads"`
+	CPUPercent float64       `msg:"cpu_percent" json:"cpu_percent"`     // of one CPU during the sample, may exceed 100
+	RSS        uint64        `msg:"rss" json:"rss"`                     // resident memory in bytes
+	StartTime  int64         `msg:"start_time" json:"start_time"`       // unix milliseconds
+	Children   []ProcessInfo `msg:"children" json:"children,omitempty"` // only in tree mode
+}
+
+type ProcessListResponse struct {
+	Processes []ProcessInfo `msg:"processes" json:"processes"` // sorted by pid. in tree mode, only roots
+	Error     string        `msg:"error" json:"error,omitempty"`
+}
+
+type ProcessSignalRequest struct {
+	Pid    int32  `msg:"pid"`
+	Signal string `msg:"signal"` // like "TERM", "SIGKILL" or "9". empty = TERM
+}
+
+type ProcessSignalResponse struct {
+	Error string `msg:"error" json:"error,omitempty"`
+}
+
+// systemd units on agent, via systemctl
+type ServiceListRequest struct {
+	Type string `msg:"type"` // unit type like "service", "timer". empty = "service", "all" for every type
+	All  bool   `msg:"all"`  // include inactive units
+}
+
+type ServiceUnit struct {
+	Unit        string `msg:"unit" json:"unit"`     // like "nginx.service"
+	Load        string `msg:"load" json:"load"`     // like "loaded", "not-found"
+	Active      string `msg:"active" json:"active"` // like "active", "inactive", "failed"
+	Sub         string `msg:"sub" json:"sub"`       // like "running", "exited", "dead"
+	Description string `msg:"description" json:"description"`
+}
+
+type ServiceListResponse struct {
+	Units []ServiceUnit `msg:"units" json:"units"`
+	Error string        `msg:"error" json:"error,omitempty"`
+}
+
+type ServiceActionRequest struct {
+	Unit   string `msg:"unit"`
+	Action string `msg:"action"` // "start", "stop", "restart" or "reload"
+}
+
+type ServiceActionResponse struct {
+	Unit  ServiceUnit `msg:"unit" json:"unit"`             // state after the action
+	Error string      `msg:"error" json:"error,omitempty"` // with output of systemctl
+}
+
+type ServiceJournalRequest struct {
+	Unit  string `msg:"unit"`
+	Lines int32  `msg:"lines"` // 0 = 100
+	Since string `msg:"since"` // passed to journalctl --since, like "1 hour ago". optional
+}
+
+type ServiceJournalResponse struct {
+	Lines []string `msg:"lines" json:"lines"`
+	Error string   `msg:"error" json:"error,omitempty"`
+}
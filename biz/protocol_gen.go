@@ -7,7 +7,7 @@ import (
 )
 
 // DecodeMsg implements msgp.Decodable
-func (z *AgentNotify) DecodeMsg(dc *msgp.Reader) (err error) {
+func (z *AgentMetrics) DecodeMsg(dc *msgp.Reader) (err error) {
 	var field []byte
 	_ = field
 	var zb0001 uint32
@@ -24,42 +24,172 @@ func (z *AgentNotify) DecodeMsg(dc *msgp.Reader) (err error) {
 			return
 		}
 		switch msgp.UnsafeString(field) {
-		case "type":
-			z.Type, err = dc.ReadString()
+		case "time":
+			z.Time, err = dc.ReadInt64()
 			if err != nil {
-				err = msgp.WrapError(err, "Type")
+				err = msgp.WrapError(err, "Time")
 				return
 			}
-		case "id":
-			z.Id, err = dc.ReadString()
+		case "hostname":
+			z.Hostname, err = dc.ReadString()
 			if err != nil {
-				err = msgp.WrapError(err, "Id")
+				err = msgp.WrapError(err, "Hostname")
 				return
 			}
-		case "cmd":
-			z.Cmd, err = dc.ReadString()
+		case "os":
+			z.OS, err = dc.ReadString()
 			if err != nil {
-				err = msgp.WrapError(err, "Cmd")
+				err = msgp.WrapError(err, "OS")
 				return
 			}
-		case "has_stdin":
-			z.HasStdin, err = dc.ReadBool()
+		case "arch":
+			z.Arch, err = dc.ReadString()
 			if err != nil {
-				err = msgp.WrapError(err, "HasStdin")
+				err = msgp.WrapError(err, "Arch")
 				return
 			}
-		case "need_stdout":
-			z.NeedStdout, err = dc.ReadBool()
+		case "uptime":
+			z.Uptime, err = dc.ReadInt64()
 			if err != nil {
-				err = msgp.WrapError(err, "NeedStdout")
+				err = msgp.WrapError(err, "Uptime")
 				return
 			}
-		case "need_stderr":
-			z.NeedStderr, err = dc.ReadBool()
+		case "num_cpu":
+			z.NumCPU, err = dc.ReadInt32()
 			if err != nil {
-				err = msgp.WrapError(err, "NeedStderr")
+				err = msgp.WrapError(err, "NumCPU")
+				return
+			}
+		case "cpu_percent":
+			z.CPUPercent, err = dc.ReadFloat64()
+			if err != nil {
+				err = msgp.WrapError(err, "CPUPercent")
+				return
+			}
+		case "load1":
+			z.Load1, err = dc.ReadFloat64()
+			if err != nil {
+				err = msgp.WrapError(err, "Load1")
+				return
+			}
+		case "load5":
+			z.Load5, err = dc.ReadFloat64()
+			if err != nil {
+				err = msgp.WrapError(err, "Load5")
+				return
+			}
+		case "load15":
+			z.Load15, err = dc.ReadFloat64()
+			if err != nil {
+				err = msgp.WrapError(err, "Load15")
+				return
+			}
+		case "mem_total":
+			z.MemTotal, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "MemTotal")
+				return
+			}
+		case "mem_available":
+			z.MemAvailable, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "MemAvailable")
+				return
+			}
+		case "swap_total":
+			z.SwapTotal, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "SwapTotal")
+				return
+			}
+		case "swap_free":
+			z.SwapFree, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "SwapFree")
+				return
+			}
+		case "filesystems":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Filesystems")
+				return
+			}
+			if cap(z.Filesystems) >= int(zb0002) {
+				z.Filesystems = (z.Filesystems)[:zb0002]
+			} else {
+				z.Filesystems = make([]FsStat, zb0002)
+			}
+			for za0001 := range z.Filesystems {
+				err = z.Filesystems[za0001].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Filesystems", za0001)
+					return
+				}
+			}
+		case "network":
+			var zb0003 uint32
+			zb0003, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Network")
+				return
+			}
+			if cap(z.Network) >= int(zb0003) {
+				z.Network = (z.Network)[:zb0003]
+			} else {
+				z.Network = make([]NetStat, zb0003)
+			}
+			for za0002 := range z.Network {
+				err = z.Network[za0002].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Network", za0002)
+					return
+				}
+			}
+		case "processes":
+			var zb0004 uint32
+			zb0004, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Processes")
+				return
+			}
+			if cap(z.Processes) >= int(zb0004) {
+				z.Processes = (z.Processes)[:zb0004]
+			} else {
+				z.Processes = make([]ProcessStat, zb0004)
+			}
+			for za0003 := range z.Processes {
+				err = z.Processes[za0003].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Processes", za0003)
+					return
+				}
+			}
+		case "agent":
+			err = z.Agent.DecodeMsg(dc)
+			if err != nil {
+				err = msgp.WrapError(err, "Agent")
+				return
+			}
+		case "errors":
+			var zb0005 uint32
+			zb0005, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Errors")
 				return
 			}
+			if cap(z.Errors) >= int(zb0005) {
+				z.Errors = (z.Errors)[:zb0005]
+			} else {
+				z.Errors = make([]string, zb0005)
+			}
+			for za0004 := range z.Errors {
+				z.Errors[za0004], err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "Errors", za0004)
+					return
+				}
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -72,98 +202,323 @@ func (z *AgentNotify) DecodeMsg(dc *msgp.Reader) (err error) {
 }
 
 // EncodeMsg implements msgp.Encodable
-func (z *AgentNotify) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 6
-	// write "type"
-	err = en.Append(0x86, 0xa4, 0x74, 0x79, 0x70, 0x65)
+func (z *AgentMetrics) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 19
+	// write "time"
+	err = en.Append(0xde, 0x0, 0x13, 0xa4, 0x74, 0x69, 0x6d, 0x65)
 	if err != nil {
 		return
 	}
-	err = en.WriteString(z.Type)
+	err = en.WriteInt64(z.Time)
 	if err != nil {
-		err = msgp.WrapError(err, "Type")
+		err = msgp.WrapError(err, "Time")
 		return
 	}
-	// write "id"
-	err = en.Append(0xa2, 0x69, 0x64)
+	// write "hostname"
+	err = en.Append(0xa8, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65)
 	if err != nil {
 		return
 	}
-	err = en.WriteString(z.Id)
+	err = en.WriteString(z.Hostname)
 	if err != nil {
-		err = msgp.WrapError(err, "Id")
+		err = msgp.WrapError(err, "Hostname")
 		return
 	}
-	// write "cmd"
-	err = en.Append(0xa3, 0x63, 0x6d, 0x64)
+	// write "os"
+	err = en.Append(0xa2, 0x6f, 0x73)
 	if err != nil {
 		return
 	}
-	err = en.WriteString(z.Cmd)
+	err = en.WriteString(z.OS)
 	if err != nil {
-		err = msgp.WrapError(err, "Cmd")
+		err = msgp.WrapError(err, "OS")
 		return
 	}
-	// write "has_stdin"
-	err = en.Append(0xa9, 0x68, 0x61, 0x73, 0x5f, 0x73, 0x74, 0x64, 0x69, 0x6e)
+	// write "arch"
+	err = en.Append(0xa4, 0x61, 0x72, 0x63, 0x68)
 	if err != nil {
 		return
 	}
-	err = en.WriteBool(z.HasStdin)
+	err = en.WriteString(z.Arch)
 	if err != nil {
-		err = msgp.WrapError(err, "HasStdin")
+		err = msgp.WrapError(err, "Arch")
 		return
 	}
-	// write "need_stdout"
-	err = en.Append(0xab, 0x6e, 0x65, 0x65, 0x64, 0x5f, 0x73, 0x74, 0x64, 0x6f, 0x75, 0x74)
+	// write "uptime"
+	err = en.Append(0xa6, 0x75, 0x70, 0x74, 0x69, 0x6d, 0x65)
 	if err != nil {
 		return
 	}
-	err = en.WriteBool(z.NeedStdout)
+	err = en.WriteInt64(z.Uptime)
 	if err != nil {
-		err = msgp.WrapError(err, "NeedStdout")
+		err = msgp.WrapError(err, "Uptime")
 		return
 	}
-	// write "need_stderr"
-	err = en.Append(0xab, 0x6e, 0x65, 0x65, 0x64, 0x5f, 0x73, 0x74, 0x64, 0x65, 0x72, 0x72)
+	// write "num_cpu"
+	err = en.Append(0xa7, 0x6e, 0x75, 0x6d, 0x5f, 0x63, 0x70, 0x75)
 	if err != nil {
 		return
 	}
-	err = en.WriteBool(z.NeedStderr)
+	err = en.WriteInt32(z.NumCPU)
 	if err != nil {
-		err = msgp.WrapError(err, "NeedStderr")
+		err = msgp.WrapError(err, "NumCPU")
+		return
+	}
+	// write "cpu_percent"
+	err = en.Append(0xab, 0x63, 0x70, 0x75, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteFloat64(z.CPUPercent)
+	if err != nil {
+		err = msgp.WrapError(err, "CPUPercent")
+		return
+	}
+	// write "load1"
+	err = en.Append(0xa5, 0x6c, 0x6f, 0x61, 0x64, 0x31)
+	if err != nil {
+		return
+	}
+	err = en.WriteFloat64(z.Load1)
+	if err != nil {
+		err = msgp.WrapError(err, "Load1")
+		return
+	}
+	// write "load5"
+	err = en.Append(0xa5, 0x6c, 0x6f, 0x61, 0x64, 0x35)
+	if err != nil {
+		return
+	}
+	err = en.WriteFloat64(z.Load5)
+	if err != nil {
+		err = msgp.WrapError(err, "Load5")
+		return
+	}
+	// write "load15"
+	err = en.Append(0xa6, 0x6c, 0x6f, 0x61, 0x64, 0x31, 0x35)
+	if err != nil {
+		return
+	}
+	err = en.WriteFloat64(z.Load15)
+	if err != nil {
+		err = msgp.WrapError(err, "Load15")
+		return
+	}
+	// write "mem_total"
+	err = en.Append(0xa9, 0x6d, 0x65, 0x6d, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.MemTotal)
+	if err != nil {
+		err = msgp.WrapError(err, "MemTotal")
+		return
+	}
+	// write "mem_available"
+	err = en.Append(0xad, 0x6d, 0x65, 0x6d, 0x5f, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.MemAvailable)
+	if err != nil {
+		err = msgp.WrapError(err, "MemAvailable")
+		return
+	}
+	// write "swap_total"
+	err = en.Append(0xaa, 0x73, 0x77, 0x61, 0x70, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.SwapTotal)
+	if err != nil {
+		err = msgp.WrapError(err, "SwapTotal")
+		return
+	}
+	// write "swap_free"
+	err = en.Append(0xa9, 0x73, 0x77, 0x61, 0x70, 0x5f, 0x66, 0x72, 0x65, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.SwapFree)
+	if err != nil {
+		err = msgp.WrapError(err, "SwapFree")
+		return
+	}
+	// write "filesystems"
+	err = en.Append(0xab, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Filesystems)))
+	if err != nil {
+		err = msgp.WrapError(err, "Filesystems")
+		return
+	}
+	for za0001 := range z.Filesystems {
+		err = z.Filesystems[za0001].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Filesystems", za0001)
+			return
+		}
+	}
+	// write "network"
+	err = en.Append(0xa7, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Network)))
+	if err != nil {
+		err = msgp.WrapError(err, "Network")
+		return
+	}
+	for za0002 := range z.Network {
+		err = z.Network[za0002].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Network", za0002)
+			return
+		}
+	}
+	// write "processes"
+	err = en.Append(0xa9, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Processes)))
+	if err != nil {
+		err = msgp.WrapError(err, "Processes")
+		return
+	}
+	for za0003 := range z.Processes {
+		err = z.Processes[za0003].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Processes", za0003)
+			return
+		}
+	}
+	// write "agent"
+	err = en.Append(0xa5, 0x61, 0x67, 0x65, 0x6e, 0x74)
+	if err != nil {
+		return
+	}
+	err = z.Agent.EncodeMsg(en)
+	if err != nil {
+		err = msgp.WrapError(err, "Agent")
+		return
+	}
+	// write "errors"
+	err = en.Append(0xa6, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Errors)))
+	if err != nil {
+		err = msgp.WrapError(err, "Errors")
 		return
 	}
+	for za0004 := range z.Errors {
+		err = en.WriteString(z.Errors[za0004])
+		if err != nil {
+			err = msgp.WrapError(err, "Errors", za0004)
+			return
+		}
+	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
-func (z *AgentNotify) MarshalMsg(b []byte) (o []byte, err error) {
+func (z *AgentMetrics) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 6
-	// string "type"
-	o = append(o, 0x86, 0xa4, 0x74, 0x79, 0x70, 0x65)
-	o = msgp.AppendString(o, z.Type)
-	// string "id"
-	o = append(o, 0xa2, 0x69, 0x64)
-	o = msgp.AppendString(o, z.Id)
-	// string "cmd"
-	o = append(o, 0xa3, 0x63, 0x6d, 0x64)
-	o = msgp.AppendString(o, z.Cmd)
-	// string "has_stdin"
-	o = append(o, 0xa9, 0x68, 0x61, 0x73, 0x5f, 0x73, 0x74, 0x64, 0x69, 0x6e)
-	o = msgp.AppendBool(o, z.HasStdin)
-	// string "need_stdout"
-	o = append(o, 0xab, 0x6e, 0x65, 0x65, 0x64, 0x5f, 0x73, 0x74, 0x64, 0x6f, 0x75, 0x74)
-	o = msgp.AppendBool(o, z.NeedStdout)
-	// string "need_stderr"
-	o = append(o, 0xab, 0x6e, 0x65, 0x65, 0x64, 0x5f, 0x73, 0x74, 0x64, 0x65, 0x72, 0x72)
-	o = msgp.AppendBool(o, z.NeedStderr)
+	// map header, size 19
+	// string "time"
+	o = append(o, 0xde, 0x0, 0x13, 0xa4, 0x74, 0x69, 0x6d, 0x65)
+	o = msgp.AppendInt64(o, z.Time)
+	// string "hostname"
+	o = append(o, 0xa8, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65)
+	o = msgp.AppendString(o, z.Hostname)
+	// string "os"
+	o = append(o, 0xa2, 0x6f, 0x73)
+	o = msgp.AppendString(o, z.OS)
+	// string "arch"
+	o = append(o, 0xa4, 0x61, 0x72, 0x63, 0x68)
+	o = msgp.AppendString(o, z.Arch)
+	// string "uptime"
+	o = append(o, 0xa6, 0x75, 0x70, 0x74, 0x69, 0x6d, 0x65)
+	o = msgp.AppendInt64(o, z.Uptime)
+	// string "num_cpu"
+	o = append(o, 0xa7, 0x6e, 0x75, 0x6d, 0x5f, 0x63, 0x70, 0x75)
+	o = msgp.AppendInt32(o, z.NumCPU)
+	// string "cpu_percent"
+	o = append(o, 0xab, 0x63, 0x70, 0x75, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74)
+	o = msgp.AppendFloat64(o, z.CPUPercent)
+	// string "load1"
+	o = append(o, 0xa5, 0x6c, 0x6f, 0x61, 0x64, 0x31)
+	o = msgp.AppendFloat64(o, z.Load1)
+	// string "load5"
+	o = append(o, 0xa5, 0x6c, 0x6f, 0x61, 0x64, 0x35)
+	o = msgp.AppendFloat64(o, z.Load5)
+	// string "load15"
+	o = append(o, 0xa6, 0x6c, 0x6f, 0x61, 0x64, 0x31, 0x35)
+	o = msgp.AppendFloat64(o, z.Load15)
+	// string "mem_total"
+	o = append(o, 0xa9, 0x6d, 0x65, 0x6d, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c)
+	o = msgp.AppendUint64(o, z.MemTotal)
+	// string "mem_available"
+	o = append(o, 0xad, 0x6d, 0x65, 0x6d, 0x5f, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65)
+	o = msgp.AppendUint64(o, z.MemAvailable)
+	// string "swap_total"
+	o = append(o, 0xaa, 0x73, 0x77, 0x61, 0x70, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c)
+	o = msgp.AppendUint64(o, z.SwapTotal)
+	// string "swap_free"
+	o = append(o, 0xa9, 0x73, 0x77, 0x61, 0x70, 0x5f, 0x66, 0x72, 0x65, 0x65)
+	o = msgp.AppendUint64(o, z.SwapFree)
+	// string "filesystems"
+	o = append(o, 0xab, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Filesystems)))
+	for za0001 := range z.Filesystems {
+		o, err = z.Filesystems[za0001].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Filesystems", za0001)
+			return
+		}
+	}
+	// string "network"
+	o = append(o, 0xa7, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Network)))
+	for za0002 := range z.Network {
+		o, err = z.Network[za0002].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Network", za0002)
+			return
+		}
+	}
+	// string "processes"
+	o = append(o, 0xa9, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Processes)))
+	for za0003 := range z.Processes {
+		o, err = z.Processes[za0003].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Processes", za0003)
+			return
+		}
+	}
+	// string "agent"
+	o = append(o, 0xa5, 0x61, 0x67, 0x65, 0x6e, 0x74)
+	o, err = z.Agent.MarshalMsg(o)
+	if err != nil {
+		err = msgp.WrapError(err, "Agent")
+		return
+	}
+	// string "errors"
+	o = append(o, 0xa6, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Errors)))
+	for za0004 := range z.Errors {
+		o = msgp.AppendString(o, z.Errors[za0004])
+	}
 	return
 }
 
 // UnmarshalMsg implements msgp.Unmarshaler
-func (z *AgentNotify) UnmarshalMsg(bts []byte) (o []byte, err error) {
+func (z *AgentMetrics) UnmarshalMsg(bts []byte) (o []byte, err error) {
 	var field []byte
 	_ = field
 	var zb0001 uint32
@@ -180,220 +535,172 @@ func (z *AgentNotify) UnmarshalMsg(bts []byte) (o []byte, err error) {
 			return
 		}
 		switch msgp.UnsafeString(field) {
-		case "type":
-			z.Type, bts, err = msgp.ReadStringBytes(bts)
+		case "time":
+			z.Time, bts, err = msgp.ReadInt64Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Type")
+				err = msgp.WrapError(err, "Time")
 				return
 			}
-		case "id":
-			z.Id, bts, err = msgp.ReadStringBytes(bts)
+		case "hostname":
+			z.Hostname, bts, err = msgp.ReadStringBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Id")
+				err = msgp.WrapError(err, "Hostname")
 				return
 			}
-		case "cmd":
-			z.Cmd, bts, err = msgp.ReadStringBytes(bts)
+		case "os":
+			z.OS, bts, err = msgp.ReadStringBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Cmd")
+				err = msgp.WrapError(err, "OS")
 				return
 			}
-		case "has_stdin":
-			z.HasStdin, bts, err = msgp.ReadBoolBytes(bts)
+		case "arch":
+			z.Arch, bts, err = msgp.ReadStringBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "HasStdin")
+				err = msgp.WrapError(err, "Arch")
 				return
 			}
-		case "need_stdout":
-			z.NeedStdout, bts, err = msgp.ReadBoolBytes(bts)
+		case "uptime":
+			z.Uptime, bts, err = msgp.ReadInt64Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "NeedStdout")
+				err = msgp.WrapError(err, "Uptime")
 				return
 			}
-		case "need_stderr":
-			z.NeedStderr, bts, err = msgp.ReadBoolBytes(bts)
+		case "num_cpu":
+			z.NumCPU, bts, err = msgp.ReadInt32Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "NeedStderr")
+				err = msgp.WrapError(err, "NumCPU")
 				return
 			}
-		default:
-			bts, err = msgp.Skip(bts)
+		case "cpu_percent":
+			z.CPUPercent, bts, err = msgp.ReadFloat64Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err)
+				err = msgp.WrapError(err, "CPUPercent")
 				return
 			}
-		}
-	}
-	o = bts
-	return
-}
-
-// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
-func (z *AgentNotify) Msgsize() (s int) {
-	s = 1 + 5 + msgp.StringPrefixSize + len(z.Type) + 3 + msgp.StringPrefixSize + len(z.Id) + 4 + msgp.StringPrefixSize + len(z.Cmd) + 10 + msgp.BoolSize + 12 + msgp.BoolSize + 12 + msgp.BoolSize
-	return
-}
-
-// DecodeMsg implements msgp.Decodable
-func (z *FileInfo) DecodeMsg(dc *msgp.Reader) (err error) {
-	var field []byte
-	_ = field
-	var zb0001 uint32
-	zb0001, err = dc.ReadMapHeader()
-	if err != nil {
-		err = msgp.WrapError(err)
-		return
-	}
-	for zb0001 > 0 {
-		zb0001--
-		field, err = dc.ReadMapKeyPtr()
-		if err != nil {
-			err = msgp.WrapError(err)
-			return
-		}
-		switch msgp.UnsafeString(field) {
-		case "path":
-			z.Path, err = dc.ReadString()
+		case "load1":
+			z.Load1, bts, err = msgp.ReadFloat64Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Path")
+				err = msgp.WrapError(err, "Load1")
 				return
 			}
-		case "size":
-			z.Size, err = dc.ReadInt64()
+		case "load5":
+			z.Load5, bts, err = msgp.ReadFloat64Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Size")
+				err = msgp.WrapError(err, "Load5")
 				return
 			}
-		case "mode":
-			z.Mode, err = dc.ReadUint32()
+		case "load15":
+			z.Load15, bts, err = msgp.ReadFloat64Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Mode")
+				err = msgp.WrapError(err, "Load15")
 				return
 			}
-		case "mtime":
-			z.Mtime, err = dc.ReadInt64()
+		case "mem_total":
+			z.MemTotal, bts, err = msgp.ReadUint64Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Mtime")
+				err = msgp.WrapError(err, "MemTotal")
 				return
 			}
-		default:
-			err = dc.Skip()
+		case "mem_available":
+			z.MemAvailable, bts, err = msgp.ReadUint64Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err)
+				err = msgp.WrapError(err, "MemAvailable")
 				return
 			}
-		}
-	}
-	return
-}
-
-// EncodeMsg implements msgp.Encodable
-func (z *FileInfo) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 4
-	// write "path"
-	err = en.Append(0x84, 0xa4, 0x70, 0x61, 0x74, 0x68)
-	if err != nil {
-		return
-	}
-	err = en.WriteString(z.Path)
-	if err != nil {
-		err = msgp.WrapError(err, "Path")
-		return
-	}
-	// write "size"
-	err = en.Append(0xa4, 0x73, 0x69, 0x7a, 0x65)
-	if err != nil {
-		return
-	}
-	err = en.WriteInt64(z.Size)
-	if err != nil {
-		err = msgp.WrapError(err, "Size")
-		return
-	}
-	// write "mode"
-	err = en.Append(0xa4, 0x6d, 0x6f, 0x64, 0x65)
-	if err != nil {
-		return
-	}
-	err = en.WriteUint32(z.Mode)
-	if err != nil {
-		err = msgp.WrapError(err, "Mode")
-		return
-	}
-	// write "mtime"
-	err = en.Append(0xa5, 0x6d, 0x74, 0x69, 0x6d, 0x65)
-	if err != nil {
-		return
-	}
-	err = en.WriteInt64(z.Mtime)
-	if err != nil {
-		err = msgp.WrapError(err, "Mtime")
-		return
-	}
-	return
-}
-
-// MarshalMsg implements msgp.Marshaler
-func (z *FileInfo) MarshalMsg(b []byte) (o []byte, err error) {
-	o = msgp.Require(b, z.Msgsize())
-	// map header, size 4
-	// string "path"
-	o = append(o, 0x84, 0xa4, 0x70, 0x61, 0x74, 0x68)
-	o = msgp.AppendString(o, z.Path)
-	// string "size"
-	o = append(o, 0xa4, 0x73, 0x69, 0x7a, 0x65)
-	o = msgp.AppendInt64(o, z.Size)
-	// string "mode"
-	o = append(o, 0xa4, 0x6d, 0x6f, 0x64, 0x65)
-	o = msgp.AppendUint32(o, z.Mode)
-	// string "mtime"
-	o = append(o, 0xa5, 0x6d, 0x74, 0x69, 0x6d, 0x65)
-	o = msgp.AppendInt64(o, z.Mtime)
-	return
-}
-
-// UnmarshalMsg implements msgp.Unmarshaler
-func (z *FileInfo) UnmarshalMsg(bts []byte) (o []byte, err error) {
-	var field []byte
-	_ = field
-	var zb0001 uint32
-	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
-	if err != nil {
-		err = msgp.WrapError(err)
-		return
-	}
-	for zb0001 > 0 {
-		zb0001--
-		field, bts, err = msgp.ReadMapKeyZC(bts)
-		if err != nil {
-			err = msgp.WrapError(err)
-			return
-		}
-		switch msgp.UnsafeString(field) {
-		case "path":
-			z.Path, bts, err = msgp.ReadStringBytes(bts)
+		case "swap_total":
+			z.SwapTotal, bts, err = msgp.ReadUint64Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Path")
+				err = msgp.WrapError(err, "SwapTotal")
 				return
 			}
-		case "size":
-			z.Size, bts, err = msgp.ReadInt64Bytes(bts)
+		case "swap_free":
+			z.SwapFree, bts, err = msgp.ReadUint64Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Size")
+				err = msgp.WrapError(err, "SwapFree")
 				return
 			}
-		case "mode":
-			z.Mode, bts, err = msgp.ReadUint32Bytes(bts)
+		case "filesystems":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Mode")
+				err = msgp.WrapError(err, "Filesystems")
 				return
 			}
-		case "mtime":
-			z.Mtime, bts, err = msgp.ReadInt64Bytes(bts)
+			if cap(z.Filesystems) >= int(zb0002) {
+				z.Filesystems = (z.Filesystems)[:zb0002]
+			} else {
+				z.Filesystems = make([]FsStat, zb0002)
+			}
+			for za0001 := range z.Filesystems {
+				bts, err = z.Filesystems[za0001].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Filesystems", za0001)
+					return
+				}
+			}
+		case "network":
+			var zb0003 uint32
+			zb0003, bts, err = msgp.ReadArrayHeaderBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Mtime")
+				err = msgp.WrapError(err, "Network")
+				return
+			}
+			if cap(z.Network) >= int(zb0003) {
+				z.Network = (z.Network)[:zb0003]
+			} else {
+				z.Network = make([]NetStat, zb0003)
+			}
+			for za0002 := range z.Network {
+				bts, err = z.Network[za0002].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Network", za0002)
+					return
+				}
+			}
+		case "processes":
+			var zb0004 uint32
+			zb0004, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Processes")
+				return
+			}
+			if cap(z.Processes) >= int(zb0004) {
+				z.Processes = (z.Processes)[:zb0004]
+			} else {
+				z.Processes = make([]ProcessStat, zb0004)
+			}
+			for za0003 := range z.Processes {
+				bts, err = z.Processes[za0003].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Processes", za0003)
+					return
+				}
+			}
+		case "agent":
+			bts, err = z.Agent.UnmarshalMsg(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Agent")
+				return
+			}
+		case "errors":
+			var zb0005 uint32
+			zb0005, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Errors")
 				return
 			}
+			if cap(z.Errors) >= int(zb0005) {
+				z.Errors = (z.Errors)[:zb0005]
+			} else {
+				z.Errors = make([]string, zb0005)
+			}
+			for za0004 := range z.Errors {
+				z.Errors[za0004], bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Errors", za0004)
+					return
+				}
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -407,13 +714,28 @@ func (z *FileInfo) UnmarshalMsg(bts []byte) (o []byte, err error) {
 }
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
-func (z *FileInfo) Msgsize() (s int) {
-	s = 1 + 5 + msgp.StringPrefixSize + len(z.Path) + 5 + msgp.Int64Size + 5 + msgp.Uint32Size + 6 + msgp.Int64Size
+func (z *AgentMetrics) Msgsize() (s int) {
+	s = 3 + 5 + msgp.Int64Size + 9 + msgp.StringPrefixSize + len(z.Hostname) + 3 + msgp.StringPrefixSize + len(z.OS) + 5 + msgp.StringPrefixSize + len(z.Arch) + 7 + msgp.Int64Size + 8 + msgp.Int32Size + 12 + msgp.Float64Size + 6 + msgp.Float64Size + 6 + msgp.Float64Size + 7 + msgp.Float64Size + 10 + msgp.Uint64Size + 14 + msgp.Uint64Size + 11 + msgp.Uint64Size + 10 + msgp.Uint64Size + 12 + msgp.ArrayHeaderSize
+	for za0001 := range z.Filesystems {
+		s += z.Filesystems[za0001].Msgsize()
+	}
+	s += 8 + msgp.ArrayHeaderSize
+	for za0002 := range z.Network {
+		s += z.Network[za0002].Msgsize()
+	}
+	s += 10 + msgp.ArrayHeaderSize
+	for za0003 := range z.Processes {
+		s += z.Processes[za0003].Msgsize()
+	}
+	s += 6 + z.Agent.Msgsize() + 7 + msgp.ArrayHeaderSize
+	for za0004 := range z.Errors {
+		s += msgp.StringPrefixSize + len(z.Errors[za0004])
+	}
 	return
 }
 
 // DecodeMsg implements msgp.Decodable
-func (z *ProxyHttpHeader) DecodeMsg(dc *msgp.Reader) (err error) {
+func (z *AgentMetricsRequest) DecodeMsg(dc *msgp.Reader) (err error) {
 	var field []byte
 	_ = field
 	var zb0001 uint32
@@ -430,16 +752,16 @@ func (z *ProxyHttpHeader) DecodeMsg(dc *msgp.Reader) (err error) {
 			return
 		}
 		switch msgp.UnsafeString(field) {
-		case "name":
-			z.Name, err = dc.ReadString()
+		case "top_n":
+			z.TopN, err = dc.ReadInt32()
 			if err != nil {
-				err = msgp.WrapError(err, "Name")
+				err = msgp.WrapError(err, "TopN")
 				return
 			}
-		case "value":
-			z.Value, err = dc.ReadString()
+		case "sample_ms":
+			z.SampleMs, err = dc.ReadInt32()
 			if err != nil {
-				err = msgp.WrapError(err, "Value")
+				err = msgp.WrapError(err, "SampleMs")
 				return
 			}
 		default:
@@ -454,46 +776,46 @@ func (z *ProxyHttpHeader) DecodeMsg(dc *msgp.Reader) (err error) {
 }
 
 // EncodeMsg implements msgp.Encodable
-func (z ProxyHttpHeader) EncodeMsg(en *msgp.Writer) (err error) {
+func (z AgentMetricsRequest) EncodeMsg(en *msgp.Writer) (err error) {
 	// map header, size 2
-	// write "name"
-	err = en.Append(0x82, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
+	// write "top_n"
+	err = en.Append(0x82, 0xa5, 0x74, 0x6f, 0x70, 0x5f, 0x6e)
 	if err != nil {
 		return
 	}
-	err = en.WriteString(z.Name)
+	err = en.WriteInt32(z.TopN)
 	if err != nil {
-		err = msgp.WrapError(err, "Name")
+		err = msgp.WrapError(err, "TopN")
 		return
 	}
-	// write "value"
-	err = en.Append(0xa5, 0x76, 0x61, 0x6c, 0x75, 0x65)
+	// write "sample_ms"
+	err = en.Append(0xa9, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x5f, 0x6d, 0x73)
 	if err != nil {
 		return
 	}
-	err = en.WriteString(z.Value)
+	err = en.WriteInt32(z.SampleMs)
 	if err != nil {
-		err = msgp.WrapError(err, "Value")
+		err = msgp.WrapError(err, "SampleMs")
 		return
 	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
-func (z ProxyHttpHeader) MarshalMsg(b []byte) (o []byte, err error) {
+func (z AgentMetricsRequest) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
 	// map header, size 2
-	// string "name"
-	o = append(o, 0x82, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
-	o = msgp.AppendString(o, z.Name)
-	// string "value"
-	o = append(o, 0xa5, 0x76, 0x61, 0x6c, 0x75, 0x65)
-	o = msgp.AppendString(o, z.Value)
+	// string "top_n"
+	o = append(o, 0x82, 0xa5, 0x74, 0x6f, 0x70, 0x5f, 0x6e)
+	o = msgp.AppendInt32(o, z.TopN)
+	// string "sample_ms"
+	o = append(o, 0xa9, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x5f, 0x6d, 0x73)
+	o = msgp.AppendInt32(o, z.SampleMs)
 	return
 }
 
 // UnmarshalMsg implements msgp.Unmarshaler
-func (z *ProxyHttpHeader) UnmarshalMsg(bts []byte) (o []byte, err error) {
+func (z *AgentMetricsRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
 	var field []byte
 	_ = field
 	var zb0001 uint32
@@ -510,16 +832,16 @@ func (z *ProxyHttpHeader) UnmarshalMsg(bts []byte) (o []byte, err error) {
 			return
 		}
 		switch msgp.UnsafeString(field) {
-		case "name":
-			z.Name, bts, err = msgp.ReadStringBytes(bts)
+		case "top_n":
+			z.TopN, bts, err = msgp.ReadInt32Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Name")
+				err = msgp.WrapError(err, "TopN")
 				return
 			}
-		case "value":
-			z.Value, bts, err = msgp.ReadStringBytes(bts)
+		case "sample_ms":
+			z.SampleMs, bts, err = msgp.ReadInt32Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Value")
+				err = msgp.WrapError(err, "SampleMs")
 				return
 			}
 		default:
@@ -535,13 +857,13 @@ func (z *ProxyHttpHeader) UnmarshalMsg(bts []byte) (o []byte, err error) {
 }
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
-func (z ProxyHttpHeader) Msgsize() (s int) {
-	s = 1 + 5 + msgp.StringPrefixSize + len(z.Name) + 6 + msgp.StringPrefixSize + len(z.Value)
+func (z AgentMetricsRequest) Msgsize() (s int) {
+	s = 1 + 6 + msgp.Int32Size + 10 + msgp.Int32Size
 	return
 }
 
 // DecodeMsg implements msgp.Decodable
-func (z *ProxyHttpRequest) DecodeMsg(dc *msgp.Reader) (err error) {
+func (z *AgentNotify) DecodeMsg(dc *msgp.Reader) (err error) {
 	var field []byte
 	_ = field
 	var zb0001 uint32
@@ -558,76 +880,40 @@ func (z *ProxyHttpRequest) DecodeMsg(dc *msgp.Reader) (err error) {
 			return
 		}
 		switch msgp.UnsafeString(field) {
-		case "method":
-			z.Method, err = dc.ReadString()
+		case "type":
+			z.Type, err = dc.ReadString()
 			if err != nil {
-				err = msgp.WrapError(err, "Method")
+				err = msgp.WrapError(err, "Type")
 				return
 			}
-		case "url":
-			z.URL, err = dc.ReadString()
+		case "id":
+			z.Id, err = dc.ReadString()
 			if err != nil {
-				err = msgp.WrapError(err, "URL")
+				err = msgp.WrapError(err, "Id")
 				return
 			}
-		case "headers":
-			var zb0002 uint32
-			zb0002, err = dc.ReadArrayHeader()
+		case "cmd":
+			z.Cmd, err = dc.ReadString()
 			if err != nil {
-				err = msgp.WrapError(err, "Headers")
+				err = msgp.WrapError(err, "Cmd")
 				return
 			}
-			if cap(z.Headers) >= int(zb0002) {
-				z.Headers = (z.Headers)[:zb0002]
-			} else {
-				z.Headers = make([]ProxyHttpHeader, zb0002)
+		case "has_stdin":
+			z.HasStdin, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "HasStdin")
+				return
 			}
-			for za0001 := range z.Headers {
-				var zb0003 uint32
-				zb0003, err = dc.ReadMapHeader()
-				if err != nil {
-					err = msgp.WrapError(err, "Headers", za0001)
-					return
-				}
-				for zb0003 > 0 {
-					zb0003--
-					field, err = dc.ReadMapKeyPtr()
-					if err != nil {
-						err = msgp.WrapError(err, "Headers", za0001)
-						return
-					}
-					switch msgp.UnsafeString(field) {
-					case "name":
-						z.Headers[za0001].Name, err = dc.ReadString()
-						if err != nil {
-							err = msgp.WrapError(err, "Headers", za0001, "Name")
-							return
-						}
-					case "value":
-						z.Headers[za0001].Value, err = dc.ReadString()
-						if err != nil {
-							err = msgp.WrapError(err, "Headers", za0001, "Value")
-							return
-						}
-					default:
-						err = dc.Skip()
-						if err != nil {
-							err = msgp.WrapError(err, "Headers", za0001)
-							return
-						}
-					}
-				}
-			}
-		case "host":
-			z.Host, err = dc.ReadString()
+		case "need_stdout":
+			z.NeedStdout, err = dc.ReadBool()
 			if err != nil {
-				err = msgp.WrapError(err, "Host")
+				err = msgp.WrapError(err, "NeedStdout")
 				return
 			}
-		case "body":
-			z.Body, err = dc.ReadBytes(z.Body)
+		case "need_stderr":
+			z.NeedStderr, err = dc.ReadBool()
 			if err != nil {
-				err = msgp.WrapError(err, "Body")
+				err = msgp.WrapError(err, "NeedStderr")
 				return
 			}
 		default:
@@ -642,117 +928,98 @@ func (z *ProxyHttpRequest) DecodeMsg(dc *msgp.Reader) (err error) {
 }
 
 // EncodeMsg implements msgp.Encodable
-func (z *ProxyHttpRequest) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 5
-	// write "method"
-	err = en.Append(0x85, 0xa6, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64)
+func (z *AgentNotify) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 6
+	// write "type"
+	err = en.Append(0x86, 0xa4, 0x74, 0x79, 0x70, 0x65)
 	if err != nil {
 		return
 	}
-	err = en.WriteString(z.Method)
+	err = en.WriteString(z.Type)
 	if err != nil {
-		err = msgp.WrapError(err, "Method")
+		err = msgp.WrapError(err, "Type")
 		return
 	}
-	// write "url"
-	err = en.Append(0xa3, 0x75, 0x72, 0x6c)
+	// write "id"
+	err = en.Append(0xa2, 0x69, 0x64)
 	if err != nil {
 		return
 	}
-	err = en.WriteString(z.URL)
+	err = en.WriteString(z.Id)
 	if err != nil {
-		err = msgp.WrapError(err, "URL")
+		err = msgp.WrapError(err, "Id")
 		return
 	}
-	// write "headers"
-	err = en.Append(0xa7, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73)
+	// write "cmd"
+	err = en.Append(0xa3, 0x63, 0x6d, 0x64)
 	if err != nil {
 		return
 	}
-	err = en.WriteArrayHeader(uint32(len(z.Headers)))
+	err = en.WriteString(z.Cmd)
 	if err != nil {
-		err = msgp.WrapError(err, "Headers")
+		err = msgp.WrapError(err, "Cmd")
 		return
 	}
-	for za0001 := range z.Headers {
-		// map header, size 2
-		// write "name"
-		err = en.Append(0x82, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
-		if err != nil {
-			return
-		}
-		err = en.WriteString(z.Headers[za0001].Name)
-		if err != nil {
-			err = msgp.WrapError(err, "Headers", za0001, "Name")
-			return
-		}
-		// write "value"
-		err = en.Append(0xa5, 0x76, 0x61, 0x6c, 0x75, 0x65)
-		if err != nil {
-			return
-		}
-		err = en.WriteString(z.Headers[za0001].Value)
-		if err != nil {
-			err = msgp.WrapError(err, "Headers", za0001, "Value")
-			return
-		}
+	// write "has_stdin"
+	err = en.Append(0xa9, 0x68, 0x61, 0x73, 0x5f, 0x73, 0x74, 0x64, 0x69, 0x6e)
+	if err != nil {
+		return
 	}
-	// write "host"
-	err = en.Append(0xa4, 0x68, 0x6f, 0x73, 0x74)
+	err = en.WriteBool(z.HasStdin)
 	if err != nil {
+		err = msgp.WrapError(err, "HasStdin")
 		return
 	}
-	err = en.WriteString(z.Host)
+	// write "need_stdout"
+	err = en.Append(0xab, 0x6e, 0x65, 0x65, 0x64, 0x5f, 0x73, 0x74, 0x64, 0x6f, 0x75, 0x74)
 	if err != nil {
-		err = msgp.WrapError(err, "Host")
 		return
 	}
-	// write "body"
-	err = en.Append(0xa4, 0x62, 0x6f, 0x64, 0x79)
+	err = en.WriteBool(z.NeedStdout)
+	if err != nil {
+		err = msgp.WrapError(err, "NeedStdout")
+		return
+	}
+	// write "need_stderr"
+	err = en.Append(0xab, 0x6e, 0x65, 0x65, 0x64, 0x5f, 0x73, 0x74, 0x64, 0x65, 0x72, 0x72)
 	if err != nil {
 		return
 	}
-	err = en.WriteBytes(z.Body)
+	err = en.WriteBool(z.NeedStderr)
 	if err != nil {
-		err = msgp.WrapError(err, "Body")
+		err = msgp.WrapError(err, "NeedStderr")
 		return
 	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
-func (z *ProxyHttpRequest) MarshalMsg(b []byte) (o []byte, err error) {
+func (z *AgentNotify) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 5
-	// string "method"
-	o = append(o, 0x85, 0xa6, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64)
-	o = msgp.AppendString(o, z.Method)
-	// string "url"
-	o = append(o, 0xa3, 0x75, 0x72, 0x6c)
-	o = msgp.AppendString(o, z.URL)
-	// string "headers"
-	o = append(o, 0xa7, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73)
-	o = msgp.AppendArrayHeader(o, uint32(len(z.Headers)))
-	for za0001 := range z.Headers {
-		// map header, size 2
-		// string "name"
-		o = append(o, 0x82, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
-		o = msgp.AppendString(o, z.Headers[za0001].Name)
-		// string "value"
-		o = append(o, 0xa5, 0x76, 0x61, 0x6c, 0x75, 0x65)
-		o = msgp.AppendString(o, z.Headers[za0001].Value)
-	}
-	// string "host"
-	o = append(o, 0xa4, 0x68, 0x6f, 0x73, 0x74)
-	o = msgp.AppendString(o, z.Host)
-	// string "body"
-	o = append(o, 0xa4, 0x62, 0x6f, 0x64, 0x79)
-	o = msgp.AppendBytes(o, z.Body)
+	// map header, size 6
+	// string "type"
+	o = append(o, 0x86, 0xa4, 0x74, 0x79, 0x70, 0x65)
+	o = msgp.AppendString(o, z.Type)
+	// string "id"
+	o = append(o, 0xa2, 0x69, 0x64)
+	o = msgp.AppendString(o, z.Id)
+	// string "cmd"
+	o = append(o, 0xa3, 0x63, 0x6d, 0x64)
+	o = msgp.AppendString(o, z.Cmd)
+	// string "has_stdin"
+	o = append(o, 0xa9, 0x68, 0x61, 0x73, 0x5f, 0x73, 0x74, 0x64, 0x69, 0x6e)
+	o = msgp.AppendBool(o, z.HasStdin)
+	// string "need_stdout"
+	o = append(o, 0xab, 0x6e, 0x65, 0x65, 0x64, 0x5f, 0x73, 0x74, 0x64, 0x6f, 0x75, 0x74)
+	o = msgp.AppendBool(o, z.NeedStdout)
+	// string "need_stderr"
+	o = append(o, 0xab, 0x6e, 0x65, 0x65, 0x64, 0x5f, 0x73, 0x74, 0x64, 0x65, 0x72, 0x72)
+	o = msgp.AppendBool(o, z.NeedStderr)
 	return
 }
 
 // UnmarshalMsg implements msgp.Unmarshaler
-func (z *ProxyHttpRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
+func (z *AgentNotify) UnmarshalMsg(bts []byte) (o []byte, err error) {
 	var field []byte
 	_ = field
 	var zb0001 uint32
@@ -769,76 +1036,40 @@ func (z *ProxyHttpRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
 			return
 		}
 		switch msgp.UnsafeString(field) {
-		case "method":
-			z.Method, bts, err = msgp.ReadStringBytes(bts)
+		case "type":
+			z.Type, bts, err = msgp.ReadStringBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Method")
+				err = msgp.WrapError(err, "Type")
 				return
 			}
-		case "url":
-			z.URL, bts, err = msgp.ReadStringBytes(bts)
+		case "id":
+			z.Id, bts, err = msgp.ReadStringBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "URL")
+				err = msgp.WrapError(err, "Id")
 				return
 			}
-		case "headers":
-			var zb0002 uint32
-			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+		case "cmd":
+			z.Cmd, bts, err = msgp.ReadStringBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Headers")
+				err = msgp.WrapError(err, "Cmd")
 				return
 			}
-			if cap(z.Headers) >= int(zb0002) {
-				z.Headers = (z.Headers)[:zb0002]
-			} else {
-				z.Headers = make([]ProxyHttpHeader, zb0002)
-			}
-			for za0001 := range z.Headers {
-				var zb0003 uint32
-				zb0003, bts, err = msgp.ReadMapHeaderBytes(bts)
-				if err != nil {
-					err = msgp.WrapError(err, "Headers", za0001)
-					return
-				}
-				for zb0003 > 0 {
-					zb0003--
-					field, bts, err = msgp.ReadMapKeyZC(bts)
-					if err != nil {
-						err = msgp.WrapError(err, "Headers", za0001)
-						return
-					}
-					switch msgp.UnsafeString(field) {
-					case "name":
-						z.Headers[za0001].Name, bts, err = msgp.ReadStringBytes(bts)
-						if err != nil {
-							err = msgp.WrapError(err, "Headers", za0001, "Name")
-							return
-						}
-					case "value":
-						z.Headers[za0001].Value, bts, err = msgp.ReadStringBytes(bts)
-						if err != nil {
-							err = msgp.WrapError(err, "Headers", za0001, "Value")
-							return
-						}
-					default:
-						bts, err = msgp.Skip(bts)
-						if err != nil {
-							err = msgp.WrapError(err, "Headers", za0001)
-							return
-						}
-					}
-				}
+		case "has_stdin":
+			z.HasStdin, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "HasStdin")
+				return
 			}
-		case "host":
-			z.Host, bts, err = msgp.ReadStringBytes(bts)
+		case "need_stdout":
+			z.NeedStdout, bts, err = msgp.ReadBoolBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Host")
+				err = msgp.WrapError(err, "NeedStdout")
 				return
 			}
-		case "body":
-			z.Body, bts, err = msgp.ReadBytesBytes(bts, z.Body)
+		case "need_stderr":
+			z.NeedStderr, bts, err = msgp.ReadBoolBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Body")
+				err = msgp.WrapError(err, "NeedStderr")
 				return
 			}
 		default:
@@ -854,17 +1085,13 @@ func (z *ProxyHttpRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
 }
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
-func (z *ProxyHttpRequest) Msgsize() (s int) {
-	s = 1 + 7 + msgp.StringPrefixSize + len(z.Method) + 4 + msgp.StringPrefixSize + len(z.URL) + 8 + msgp.ArrayHeaderSize
-	for za0001 := range z.Headers {
-		s += 1 + 5 + msgp.StringPrefixSize + len(z.Headers[za0001].Name) + 6 + msgp.StringPrefixSize + len(z.Headers[za0001].Value)
-	}
-	s += 5 + msgp.StringPrefixSize + len(z.Host) + 5 + msgp.BytesPrefixSize + len(z.Body)
+func (z *AgentNotify) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Type) + 3 + msgp.StringPrefixSize + len(z.Id) + 4 + msgp.StringPrefixSize + len(z.Cmd) + 10 + msgp.BoolSize + 12 + msgp.BoolSize + 12 + msgp.BoolSize
 	return
 }
 
 // DecodeMsg implements msgp.Decodable
-func (z *ProxyHttpResponse) DecodeMsg(dc *msgp.Reader) (err error) {
+func (z *AgentRuntime) DecodeMsg(dc *msgp.Reader) (err error) {
 	var field []byte
 	_ = field
 	var zb0001 uint32
@@ -881,70 +1108,40 @@ func (z *ProxyHttpResponse) DecodeMsg(dc *msgp.Reader) (err error) {
 			return
 		}
 		switch msgp.UnsafeString(field) {
-		case "connection_error":
-			z.ConnectionError, err = dc.ReadString()
+		case "version":
+			z.Version, err = dc.ReadString()
 			if err != nil {
-				err = msgp.WrapError(err, "ConnectionError")
+				err = msgp.WrapError(err, "Version")
 				return
 			}
-		case "status_code":
-			z.StatusCode, err = dc.ReadInt32()
+		case "pid":
+			z.Pid, err = dc.ReadInt32()
 			if err != nil {
-				err = msgp.WrapError(err, "StatusCode")
+				err = msgp.WrapError(err, "Pid")
 				return
 			}
-		case "headers":
-			var zb0002 uint32
-			zb0002, err = dc.ReadArrayHeader()
+		case "uptime":
+			z.Uptime, err = dc.ReadInt64()
 			if err != nil {
-				err = msgp.WrapError(err, "Headers")
+				err = msgp.WrapError(err, "Uptime")
 				return
 			}
-			if cap(z.Headers) >= int(zb0002) {
-				z.Headers = (z.Headers)[:zb0002]
-			} else {
-				z.Headers = make([]ProxyHttpHeader, zb0002)
+		case "goroutines":
+			z.Goroutines, err = dc.ReadInt32()
+			if err != nil {
+				err = msgp.WrapError(err, "Goroutines")
+				return
 			}
-			for za0001 := range z.Headers {
-				var zb0003 uint32
-				zb0003, err = dc.ReadMapHeader()
-				if err != nil {
-					err = msgp.WrapError(err, "Headers", za0001)
-					return
-				}
-				for zb0003 > 0 {
-					zb0003--
-					field, err = dc.ReadMapKeyPtr()
-					if err != nil {
-						err = msgp.WrapError(err, "Headers", za0001)
-						return
-					}
-					switch msgp.UnsafeString(field) {
-					case "name":
-						z.Headers[za0001].Name, err = dc.ReadString()
-						if err != nil {
-							err = msgp.WrapError(err, "Headers", za0001, "Name")
-							return
-						}
-					case "value":
-						z.Headers[za0001].Value, err = dc.ReadString()
-						if err != nil {
-							err = msgp.WrapError(err, "Headers", za0001, "Value")
-							return
-						}
-					default:
-						err = dc.Skip()
-						if err != nil {
-							err = msgp.WrapError(err, "Headers", za0001)
-							return
-						}
-					}
-				}
+		case "heap_alloc":
+			z.HeapAlloc, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "HeapAlloc")
+				return
 			}
-		case "is_websocket":
-			z.IsWebSocket, err = dc.ReadBool()
+		case "sys":
+			z.Sys, err = dc.ReadUint64()
 			if err != nil {
-				err = msgp.WrapError(err, "IsWebSocket")
+				err = msgp.WrapError(err, "Sys")
 				return
 			}
 		default:
@@ -959,104 +1156,250 @@ func (z *ProxyHttpResponse) DecodeMsg(dc *msgp.Reader) (err error) {
 }
 
 // EncodeMsg implements msgp.Encodable
-func (z *ProxyHttpResponse) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 4
-	// write "connection_error"
-	err = en.Append(0x84, 0xb0, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72)
+func (z *AgentRuntime) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 6
+	// write "version"
+	err = en.Append(0x86, 0xa7, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e)
 	if err != nil {
 		return
 	}
-	err = en.WriteString(z.ConnectionError)
+	err = en.WriteString(z.Version)
 	if err != nil {
-		err = msgp.WrapError(err, "ConnectionError")
+		err = msgp.WrapError(err, "Version")
 		return
 	}
-	// write "status_code"
-	err = en.Append(0xab, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65)
+	// write "pid"
+	err = en.Append(0xa3, 0x70, 0x69, 0x64)
 	if err != nil {
 		return
 	}
-	err = en.WriteInt32(z.StatusCode)
+	err = en.WriteInt32(z.Pid)
 	if err != nil {
-		err = msgp.WrapError(err, "StatusCode")
+		err = msgp.WrapError(err, "Pid")
 		return
 	}
-	// write "headers"
-	err = en.Append(0xa7, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73)
+	// write "uptime"
+	err = en.Append(0xa6, 0x75, 0x70, 0x74, 0x69, 0x6d, 0x65)
 	if err != nil {
 		return
 	}
-	err = en.WriteArrayHeader(uint32(len(z.Headers)))
+	err = en.WriteInt64(z.Uptime)
 	if err != nil {
-		err = msgp.WrapError(err, "Headers")
+		err = msgp.WrapError(err, "Uptime")
 		return
 	}
-	for za0001 := range z.Headers {
-		// map header, size 2
-		// write "name"
-		err = en.Append(0x82, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
+	// write "goroutines"
+	err = en.Append(0xaa, 0x67, 0x6f, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt32(z.Goroutines)
+	if err != nil {
+		err = msgp.WrapError(err, "Goroutines")
+		return
+	}
+	// write "heap_alloc"
+	err = en.Append(0xaa, 0x68, 0x65, 0x61, 0x70, 0x5f, 0x61, 0x6c, 0x6c, 0x6f, 0x63)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.HeapAlloc)
+	if err != nil {
+		err = msgp.WrapError(err, "HeapAlloc")
+		return
+	}
+	// write "sys"
+	err = en.Append(0xa3, 0x73, 0x79, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.Sys)
+	if err != nil {
+		err = msgp.WrapError(err, "Sys")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *AgentRuntime) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 6
+	// string "version"
+	o = append(o, 0x86, 0xa7, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e)
+	o = msgp.AppendString(o, z.Version)
+	// string "pid"
+	o = append(o, 0xa3, 0x70, 0x69, 0x64)
+	o = msgp.AppendInt32(o, z.Pid)
+	// string "uptime"
+	o = append(o, 0xa6, 0x75, 0x70, 0x74, 0x69, 0x6d, 0x65)
+	o = msgp.AppendInt64(o, z.Uptime)
+	// string "goroutines"
+	o = append(o, 0xaa, 0x67, 0x6f, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x65, 0x73)
+	o = msgp.AppendInt32(o, z.Goroutines)
+	// string "heap_alloc"
+	o = append(o, 0xaa, 0x68, 0x65, 0x61, 0x70, 0x5f, 0x61, 0x6c, 0x6c, 0x6f, 0x63)
+	o = msgp.AppendUint64(o, z.HeapAlloc)
+	// string "sys"
+	o = append(o, 0xa3, 0x73, 0x79, 0x73)
+	o = msgp.AppendUint64(o, z.Sys)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *AgentRuntime) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
 		if err != nil {
+			err = msgp.WrapError(err)
 			return
 		}
-		err = en.WriteString(z.Headers[za0001].Name)
-		if err != nil {
-			err = msgp.WrapError(err, "Headers", za0001, "Name")
-			return
+		switch msgp.UnsafeString(field) {
+		case "version":
+			z.Version, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Version")
+				return
+			}
+		case "pid":
+			z.Pid, bts, err = msgp.ReadInt32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Pid")
+				return
+			}
+		case "uptime":
+			z.Uptime, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Uptime")
+				return
+			}
+		case "goroutines":
+			z.Goroutines, bts, err = msgp.ReadInt32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Goroutines")
+				return
+			}
+		case "heap_alloc":
+			z.HeapAlloc, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "HeapAlloc")
+				return
+			}
+		case "sys":
+			z.Sys, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Sys")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
 		}
-		// write "value"
-		err = en.Append(0xa5, 0x76, 0x61, 0x6c, 0x75, 0x65)
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *AgentRuntime) Msgsize() (s int) {
+	s = 1 + 8 + msgp.StringPrefixSize + len(z.Version) + 4 + msgp.Int32Size + 7 + msgp.Int64Size + 11 + msgp.Int32Size + 11 + msgp.Uint64Size + 4 + msgp.Uint64Size
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *BlockSumRequest) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
 		if err != nil {
+			err = msgp.WrapError(err)
 			return
 		}
-		err = en.WriteString(z.Headers[za0001].Value)
-		if err != nil {
-			err = msgp.WrapError(err, "Headers", za0001, "Value")
-			return
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		case "block_size":
+			z.BlockSize, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "BlockSize")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
 		}
 	}
-	// write "is_websocket"
-	err = en.Append(0xac, 0x69, 0x73, 0x5f, 0x77, 0x65, 0x62, 0x73, 0x6f, 0x63, 0x6b, 0x65, 0x74)
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z BlockSumRequest) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 2
+	// write "path"
+	err = en.Append(0x82, 0xa4, 0x70, 0x61, 0x74, 0x68)
 	if err != nil {
 		return
 	}
-	err = en.WriteBool(z.IsWebSocket)
+	err = en.WriteString(z.Path)
 	if err != nil {
-		err = msgp.WrapError(err, "IsWebSocket")
+		err = msgp.WrapError(err, "Path")
+		return
+	}
+	// write "block_size"
+	err = en.Append(0xaa, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x73, 0x69, 0x7a, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.BlockSize)
+	if err != nil {
+		err = msgp.WrapError(err, "BlockSize")
 		return
 	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
-func (z *ProxyHttpResponse) MarshalMsg(b []byte) (o []byte, err error) {
+func (z BlockSumRequest) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 4
-	// string "connection_error"
-	o = append(o, 0x84, 0xb0, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72)
-	o = msgp.AppendString(o, z.ConnectionError)
-	// string "status_code"
-	o = append(o, 0xab, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65)
-	o = msgp.AppendInt32(o, z.StatusCode)
-	// string "headers"
-	o = append(o, 0xa7, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73)
-	o = msgp.AppendArrayHeader(o, uint32(len(z.Headers)))
-	for za0001 := range z.Headers {
-		// map header, size 2
-		// string "name"
-		o = append(o, 0x82, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
-		o = msgp.AppendString(o, z.Headers[za0001].Name)
-		// string "value"
-		o = append(o, 0xa5, 0x76, 0x61, 0x6c, 0x75, 0x65)
-		o = msgp.AppendString(o, z.Headers[za0001].Value)
-	}
-	// string "is_websocket"
-	o = append(o, 0xac, 0x69, 0x73, 0x5f, 0x77, 0x65, 0x62, 0x73, 0x6f, 0x63, 0x6b, 0x65, 0x74)
-	o = msgp.AppendBool(o, z.IsWebSocket)
+	// map header, size 2
+	// string "path"
+	o = append(o, 0x82, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	o = msgp.AppendString(o, z.Path)
+	// string "block_size"
+	o = append(o, 0xaa, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x73, 0x69, 0x7a, 0x65)
+	o = msgp.AppendInt64(o, z.BlockSize)
 	return
 }
 
 // UnmarshalMsg implements msgp.Unmarshaler
-func (z *ProxyHttpResponse) UnmarshalMsg(bts []byte) (o []byte, err error) {
+func (z *BlockSumRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
 	var field []byte
 	_ = field
 	var zb0001 uint32
@@ -1073,70 +1416,5602 @@ func (z *ProxyHttpResponse) UnmarshalMsg(bts []byte) (o []byte, err error) {
 			return
 		}
 		switch msgp.UnsafeString(field) {
-		case "connection_error":
-			z.ConnectionError, bts, err = msgp.ReadStringBytes(bts)
+		case "path":
+			z.Path, bts, err = msgp.ReadStringBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "ConnectionError")
+				err = msgp.WrapError(err, "Path")
 				return
 			}
-		case "status_code":
-			z.StatusCode, bts, err = msgp.ReadInt32Bytes(bts)
+		case "block_size":
+			z.BlockSize, bts, err = msgp.ReadInt64Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "StatusCode")
+				err = msgp.WrapError(err, "BlockSize")
 				return
 			}
-		case "headers":
-			var zb0002 uint32
-			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+		default:
+			bts, err = msgp.Skip(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Headers")
+				err = msgp.WrapError(err)
 				return
 			}
-			if cap(z.Headers) >= int(zb0002) {
-				z.Headers = (z.Headers)[:zb0002]
-			} else {
-				z.Headers = make([]ProxyHttpHeader, zb0002)
-			}
-			for za0001 := range z.Headers {
-				var zb0003 uint32
-				zb0003, bts, err = msgp.ReadMapHeaderBytes(bts)
-				if err != nil {
-					err = msgp.WrapError(err, "Headers", za0001)
-					return
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z BlockSumRequest) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Path) + 11 + msgp.Int64Size
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *BlockSumResponse) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "size":
+			z.Size, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "Size")
+				return
+			}
+		case "sums":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Sums")
+				return
+			}
+			if cap(z.Sums) >= int(zb0002) {
+				z.Sums = (z.Sums)[:zb0002]
+			} else {
+				z.Sums = make([][]byte, zb0002)
+			}
+			for za0001 := range z.Sums {
+				z.Sums[za0001], err = dc.ReadBytes(z.Sums[za0001])
+				if err != nil {
+					err = msgp.WrapError(err, "Sums", za0001)
+					return
 				}
-				for zb0003 > 0 {
-					zb0003--
-					field, bts, err = msgp.ReadMapKeyZC(bts)
-					if err != nil {
-						err = msgp.WrapError(err, "Headers", za0001)
-						return
-					}
-					switch msgp.UnsafeString(field) {
-					case "name":
-						z.Headers[za0001].Name, bts, err = msgp.ReadStringBytes(bts)
-						if err != nil {
-							err = msgp.WrapError(err, "Headers", za0001, "Name")
-							return
-						}
-					case "value":
-						z.Headers[za0001].Value, bts, err = msgp.ReadStringBytes(bts)
-						if err != nil {
-							err = msgp.WrapError(err, "Headers", za0001, "Value")
-							return
-						}
-					default:
-						bts, err = msgp.Skip(bts)
-						if err != nil {
-							err = msgp.WrapError(err, "Headers", za0001)
-							return
-						}
-					}
+			}
+		case "error":
+			z.Error, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *BlockSumResponse) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 3
+	// write "size"
+	err = en.Append(0x83, 0xa4, 0x73, 0x69, 0x7a, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.Size)
+	if err != nil {
+		err = msgp.WrapError(err, "Size")
+		return
+	}
+	// write "sums"
+	err = en.Append(0xa4, 0x73, 0x75, 0x6d, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Sums)))
+	if err != nil {
+		err = msgp.WrapError(err, "Sums")
+		return
+	}
+	for za0001 := range z.Sums {
+		err = en.WriteBytes(z.Sums[za0001])
+		if err != nil {
+			err = msgp.WrapError(err, "Sums", za0001)
+			return
+		}
+	}
+	// write "error"
+	err = en.Append(0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Error)
+	if err != nil {
+		err = msgp.WrapError(err, "Error")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *BlockSumResponse) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 3
+	// string "size"
+	o = append(o, 0x83, 0xa4, 0x73, 0x69, 0x7a, 0x65)
+	o = msgp.AppendInt64(o, z.Size)
+	// string "sums"
+	o = append(o, 0xa4, 0x73, 0x75, 0x6d, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Sums)))
+	for za0001 := range z.Sums {
+		o = msgp.AppendBytes(o, z.Sums[za0001])
+	}
+	// string "error"
+	o = append(o, 0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	o = msgp.AppendString(o, z.Error)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *BlockSumResponse) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "size":
+			z.Size, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Size")
+				return
+			}
+		case "sums":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Sums")
+				return
+			}
+			if cap(z.Sums) >= int(zb0002) {
+				z.Sums = (z.Sums)[:zb0002]
+			} else {
+				z.Sums = make([][]byte, zb0002)
+			}
+			for za0001 := range z.Sums {
+				z.Sums[za0001], bts, err = msgp.ReadBytesBytes(bts, z.Sums[za0001])
+				if err != nil {
+					err = msgp.WrapError(err, "Sums", za0001)
+					return
 				}
 			}
-		case "is_websocket":
-			z.IsWebSocket, bts, err = msgp.ReadBoolBytes(bts)
+		case "error":
+			z.Error, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *BlockSumResponse) Msgsize() (s int) {
+	s = 1 + 5 + msgp.Int64Size + 5 + msgp.ArrayHeaderSize
+	for za0001 := range z.Sums {
+		s += msgp.BytesPrefixSize + len(z.Sums[za0001])
+	}
+	s += 6 + msgp.StringPrefixSize + len(z.Error)
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *DiskUsageEntry) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		case "is_dir":
+			z.IsDir, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "IsDir")
+				return
+			}
+		case "size":
+			z.Size, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "Size")
+				return
+			}
+		case "usage":
+			z.Usage, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "Usage")
+				return
+			}
+		case "files":
+			z.Files, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "Files")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *DiskUsageEntry) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 5
+	// write "path"
+	err = en.Append(0x85, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Path)
+	if err != nil {
+		err = msgp.WrapError(err, "Path")
+		return
+	}
+	// write "is_dir"
+	err = en.Append(0xa6, 0x69, 0x73, 0x5f, 0x64, 0x69, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.IsDir)
+	if err != nil {
+		err = msgp.WrapError(err, "IsDir")
+		return
+	}
+	// write "size"
+	err = en.Append(0xa4, 0x73, 0x69, 0x7a, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.Size)
+	if err != nil {
+		err = msgp.WrapError(err, "Size")
+		return
+	}
+	// write "usage"
+	err = en.Append(0xa5, 0x75, 0x73, 0x61, 0x67, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.Usage)
+	if err != nil {
+		err = msgp.WrapError(err, "Usage")
+		return
+	}
+	// write "files"
+	err = en.Append(0xa5, 0x66, 0x69, 0x6c, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.Files)
+	if err != nil {
+		err = msgp.WrapError(err, "Files")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *DiskUsageEntry) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 5
+	// string "path"
+	o = append(o, 0x85, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	o = msgp.AppendString(o, z.Path)
+	// string "is_dir"
+	o = append(o, 0xa6, 0x69, 0x73, 0x5f, 0x64, 0x69, 0x72)
+	o = msgp.AppendBool(o, z.IsDir)
+	// string "size"
+	o = append(o, 0xa4, 0x73, 0x69, 0x7a, 0x65)
+	o = msgp.AppendInt64(o, z.Size)
+	// string "usage"
+	o = append(o, 0xa5, 0x75, 0x73, 0x61, 0x67, 0x65)
+	o = msgp.AppendInt64(o, z.Usage)
+	// string "files"
+	o = append(o, 0xa5, 0x66, 0x69, 0x6c, 0x65, 0x73)
+	o = msgp.AppendInt64(o, z.Files)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *DiskUsageEntry) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		case "is_dir":
+			z.IsDir, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "IsDir")
+				return
+			}
+		case "size":
+			z.Size, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Size")
+				return
+			}
+		case "usage":
+			z.Usage, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Usage")
+				return
+			}
+		case "files":
+			z.Files, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Files")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *DiskUsageEntry) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Path) + 7 + msgp.BoolSize + 5 + msgp.Int64Size + 6 + msgp.Int64Size + 6 + msgp.Int64Size
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *DiskUsageReport) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		case "done":
+			z.Done, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "Done")
+				return
+			}
+		case "error":
+			z.Error, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		case "scanned_dirs":
+			z.ScannedDirs, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "ScannedDirs")
+				return
+			}
+		case "scanned_files":
+			z.ScannedFiles, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "ScannedFiles")
+				return
+			}
+		case "unreadable":
+			z.Unreadable, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "Unreadable")
+				return
+			}
+		case "entries":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Entries")
+				return
+			}
+			if cap(z.Entries) >= int(zb0002) {
+				z.Entries = (z.Entries)[:zb0002]
+			} else {
+				z.Entries = make([]DiskUsageEntry, zb0002)
+			}
+			for za0001 := range z.Entries {
+				err = z.Entries[za0001].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Entries", za0001)
+					return
+				}
+			}
+		case "top":
+			var zb0003 uint32
+			zb0003, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Top")
+				return
+			}
+			if cap(z.Top) >= int(zb0003) {
+				z.Top = (z.Top)[:zb0003]
+			} else {
+				z.Top = make([]DiskUsageEntry, zb0003)
+			}
+			for za0002 := range z.Top {
+				err = z.Top[za0002].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Top", za0002)
+					return
+				}
+			}
+		case "filesystems":
+			var zb0004 uint32
+			zb0004, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Filesystems")
+				return
+			}
+			if cap(z.Filesystems) >= int(zb0004) {
+				z.Filesystems = (z.Filesystems)[:zb0004]
+			} else {
+				z.Filesystems = make([]FsStat, zb0004)
+			}
+			for za0003 := range z.Filesystems {
+				err = z.Filesystems[za0003].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Filesystems", za0003)
+					return
+				}
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *DiskUsageReport) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 9
+	// write "path"
+	err = en.Append(0x89, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Path)
+	if err != nil {
+		err = msgp.WrapError(err, "Path")
+		return
+	}
+	// write "done"
+	err = en.Append(0xa4, 0x64, 0x6f, 0x6e, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.Done)
+	if err != nil {
+		err = msgp.WrapError(err, "Done")
+		return
+	}
+	// write "error"
+	err = en.Append(0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Error)
+	if err != nil {
+		err = msgp.WrapError(err, "Error")
+		return
+	}
+	// write "scanned_dirs"
+	err = en.Append(0xac, 0x73, 0x63, 0x61, 0x6e, 0x6e, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x72, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.ScannedDirs)
+	if err != nil {
+		err = msgp.WrapError(err, "ScannedDirs")
+		return
+	}
+	// write "scanned_files"
+	err = en.Append(0xad, 0x73, 0x63, 0x61, 0x6e, 0x6e, 0x65, 0x64, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.ScannedFiles)
+	if err != nil {
+		err = msgp.WrapError(err, "ScannedFiles")
+		return
+	}
+	// write "unreadable"
+	err = en.Append(0xaa, 0x75, 0x6e, 0x72, 0x65, 0x61, 0x64, 0x61, 0x62, 0x6c, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.Unreadable)
+	if err != nil {
+		err = msgp.WrapError(err, "Unreadable")
+		return
+	}
+	// write "entries"
+	err = en.Append(0xa7, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Entries)))
+	if err != nil {
+		err = msgp.WrapError(err, "Entries")
+		return
+	}
+	for za0001 := range z.Entries {
+		err = z.Entries[za0001].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Entries", za0001)
+			return
+		}
+	}
+	// write "top"
+	err = en.Append(0xa3, 0x74, 0x6f, 0x70)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Top)))
+	if err != nil {
+		err = msgp.WrapError(err, "Top")
+		return
+	}
+	for za0002 := range z.Top {
+		err = z.Top[za0002].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Top", za0002)
+			return
+		}
+	}
+	// write "filesystems"
+	err = en.Append(0xab, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Filesystems)))
+	if err != nil {
+		err = msgp.WrapError(err, "Filesystems")
+		return
+	}
+	for za0003 := range z.Filesystems {
+		err = z.Filesystems[za0003].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Filesystems", za0003)
+			return
+		}
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *DiskUsageReport) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 9
+	// string "path"
+	o = append(o, 0x89, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	o = msgp.AppendString(o, z.Path)
+	// string "done"
+	o = append(o, 0xa4, 0x64, 0x6f, 0x6e, 0x65)
+	o = msgp.AppendBool(o, z.Done)
+	// string "error"
+	o = append(o, 0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	o = msgp.AppendString(o, z.Error)
+	// string "scanned_dirs"
+	o = append(o, 0xac, 0x73, 0x63, 0x61, 0x6e, 0x6e, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x72, 0x73)
+	o = msgp.AppendInt64(o, z.ScannedDirs)
+	// string "scanned_files"
+	o = append(o, 0xad, 0x73, 0x63, 0x61, 0x6e, 0x6e, 0x65, 0x64, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x73)
+	o = msgp.AppendInt64(o, z.ScannedFiles)
+	// string "unreadable"
+	o = append(o, 0xaa, 0x75, 0x6e, 0x72, 0x65, 0x61, 0x64, 0x61, 0x62, 0x6c, 0x65)
+	o = msgp.AppendInt64(o, z.Unreadable)
+	// string "entries"
+	o = append(o, 0xa7, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Entries)))
+	for za0001 := range z.Entries {
+		o, err = z.Entries[za0001].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Entries", za0001)
+			return
+		}
+	}
+	// string "top"
+	o = append(o, 0xa3, 0x74, 0x6f, 0x70)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Top)))
+	for za0002 := range z.Top {
+		o, err = z.Top[za0002].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Top", za0002)
+			return
+		}
+	}
+	// string "filesystems"
+	o = append(o, 0xab, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Filesystems)))
+	for za0003 := range z.Filesystems {
+		o, err = z.Filesystems[za0003].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Filesystems", za0003)
+			return
+		}
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *DiskUsageReport) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		case "done":
+			z.Done, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Done")
+				return
+			}
+		case "error":
+			z.Error, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		case "scanned_dirs":
+			z.ScannedDirs, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ScannedDirs")
+				return
+			}
+		case "scanned_files":
+			z.ScannedFiles, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ScannedFiles")
+				return
+			}
+		case "unreadable":
+			z.Unreadable, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Unreadable")
+				return
+			}
+		case "entries":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Entries")
+				return
+			}
+			if cap(z.Entries) >= int(zb0002) {
+				z.Entries = (z.Entries)[:zb0002]
+			} else {
+				z.Entries = make([]DiskUsageEntry, zb0002)
+			}
+			for za0001 := range z.Entries {
+				bts, err = z.Entries[za0001].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Entries", za0001)
+					return
+				}
+			}
+		case "top":
+			var zb0003 uint32
+			zb0003, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Top")
+				return
+			}
+			if cap(z.Top) >= int(zb0003) {
+				z.Top = (z.Top)[:zb0003]
+			} else {
+				z.Top = make([]DiskUsageEntry, zb0003)
+			}
+			for za0002 := range z.Top {
+				bts, err = z.Top[za0002].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Top", za0002)
+					return
+				}
+			}
+		case "filesystems":
+			var zb0004 uint32
+			zb0004, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Filesystems")
+				return
+			}
+			if cap(z.Filesystems) >= int(zb0004) {
+				z.Filesystems = (z.Filesystems)[:zb0004]
+			} else {
+				z.Filesystems = make([]FsStat, zb0004)
+			}
+			for za0003 := range z.Filesystems {
+				bts, err = z.Filesystems[za0003].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Filesystems", za0003)
+					return
+				}
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *DiskUsageReport) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Path) + 5 + msgp.BoolSize + 6 + msgp.StringPrefixSize + len(z.Error) + 13 + msgp.Int64Size + 14 + msgp.Int64Size + 11 + msgp.Int64Size + 8 + msgp.ArrayHeaderSize
+	for za0001 := range z.Entries {
+		s += z.Entries[za0001].Msgsize()
+	}
+	s += 4 + msgp.ArrayHeaderSize
+	for za0002 := range z.Top {
+		s += z.Top[za0002].Msgsize()
+	}
+	s += 12 + msgp.ArrayHeaderSize
+	for za0003 := range z.Filesystems {
+		s += z.Filesystems[za0003].Msgsize()
+	}
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *DiskUsageRequest) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		case "max_depth":
+			z.MaxDepth, err = dc.ReadInt32()
+			if err != nil {
+				err = msgp.WrapError(err, "MaxDepth")
+				return
+			}
+		case "top_n":
+			z.TopN, err = dc.ReadInt32()
+			if err != nil {
+				err = msgp.WrapError(err, "TopN")
+				return
+			}
+		case "one_file_system":
+			z.OneFileSystem, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "OneFileSystem")
+				return
+			}
+		case "progress_interval_ms":
+			z.ProgressInterval, err = dc.ReadInt32()
+			if err != nil {
+				err = msgp.WrapError(err, "ProgressInterval")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *DiskUsageRequest) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 5
+	// write "path"
+	err = en.Append(0x85, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Path)
+	if err != nil {
+		err = msgp.WrapError(err, "Path")
+		return
+	}
+	// write "max_depth"
+	err = en.Append(0xa9, 0x6d, 0x61, 0x78, 0x5f, 0x64, 0x65, 0x70, 0x74, 0x68)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt32(z.MaxDepth)
+	if err != nil {
+		err = msgp.WrapError(err, "MaxDepth")
+		return
+	}
+	// write "top_n"
+	err = en.Append(0xa5, 0x74, 0x6f, 0x70, 0x5f, 0x6e)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt32(z.TopN)
+	if err != nil {
+		err = msgp.WrapError(err, "TopN")
+		return
+	}
+	// write "one_file_system"
+	err = en.Append(0xaf, 0x6f, 0x6e, 0x65, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.OneFileSystem)
+	if err != nil {
+		err = msgp.WrapError(err, "OneFileSystem")
+		return
+	}
+	// write "progress_interval_ms"
+	err = en.Append(0xb4, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x5f, 0x6d, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt32(z.ProgressInterval)
+	if err != nil {
+		err = msgp.WrapError(err, "ProgressInterval")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *DiskUsageRequest) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 5
+	// string "path"
+	o = append(o, 0x85, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	o = msgp.AppendString(o, z.Path)
+	// string "max_depth"
+	o = append(o, 0xa9, 0x6d, 0x61, 0x78, 0x5f, 0x64, 0x65, 0x70, 0x74, 0x68)
+	o = msgp.AppendInt32(o, z.MaxDepth)
+	// string "top_n"
+	o = append(o, 0xa5, 0x74, 0x6f, 0x70, 0x5f, 0x6e)
+	o = msgp.AppendInt32(o, z.TopN)
+	// string "one_file_system"
+	o = append(o, 0xaf, 0x6f, 0x6e, 0x65, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d)
+	o = msgp.AppendBool(o, z.OneFileSystem)
+	// string "progress_interval_ms"
+	o = append(o, 0xb4, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x5f, 0x6d, 0x73)
+	o = msgp.AppendInt32(o, z.ProgressInterval)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *DiskUsageRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		case "max_depth":
+			z.MaxDepth, bts, err = msgp.ReadInt32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "MaxDepth")
+				return
+			}
+		case "top_n":
+			z.TopN, bts, err = msgp.ReadInt32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "TopN")
+				return
+			}
+		case "one_file_system":
+			z.OneFileSystem, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "OneFileSystem")
+				return
+			}
+		case "progress_interval_ms":
+			z.ProgressInterval, bts, err = msgp.ReadInt32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ProgressInterval")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *DiskUsageRequest) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Path) + 10 + msgp.Int32Size + 6 + msgp.Int32Size + 16 + msgp.BoolSize + 21 + msgp.Int32Size
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *FileInfo) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		case "size":
+			z.Size, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "Size")
+				return
+			}
+		case "mode":
+			z.Mode, err = dc.ReadUint32()
+			if err != nil {
+				err = msgp.WrapError(err, "Mode")
+				return
+			}
+		case "mtime":
+			z.Mtime, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "Mtime")
+				return
+			}
+		case "error":
+			z.Error, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *FileInfo) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 5
+	// write "path"
+	err = en.Append(0x85, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Path)
+	if err != nil {
+		err = msgp.WrapError(err, "Path")
+		return
+	}
+	// write "size"
+	err = en.Append(0xa4, 0x73, 0x69, 0x7a, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.Size)
+	if err != nil {
+		err = msgp.WrapError(err, "Size")
+		return
+	}
+	// write "mode"
+	err = en.Append(0xa4, 0x6d, 0x6f, 0x64, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint32(z.Mode)
+	if err != nil {
+		err = msgp.WrapError(err, "Mode")
+		return
+	}
+	// write "mtime"
+	err = en.Append(0xa5, 0x6d, 0x74, 0x69, 0x6d, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.Mtime)
+	if err != nil {
+		err = msgp.WrapError(err, "Mtime")
+		return
+	}
+	// write "error"
+	err = en.Append(0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Error)
+	if err != nil {
+		err = msgp.WrapError(err, "Error")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *FileInfo) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 5
+	// string "path"
+	o = append(o, 0x85, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	o = msgp.AppendString(o, z.Path)
+	// string "size"
+	o = append(o, 0xa4, 0x73, 0x69, 0x7a, 0x65)
+	o = msgp.AppendInt64(o, z.Size)
+	// string "mode"
+	o = append(o, 0xa4, 0x6d, 0x6f, 0x64, 0x65)
+	o = msgp.AppendUint32(o, z.Mode)
+	// string "mtime"
+	o = append(o, 0xa5, 0x6d, 0x74, 0x69, 0x6d, 0x65)
+	o = msgp.AppendInt64(o, z.Mtime)
+	// string "error"
+	o = append(o, 0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	o = msgp.AppendString(o, z.Error)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *FileInfo) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		case "size":
+			z.Size, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Size")
+				return
+			}
+		case "mode":
+			z.Mode, bts, err = msgp.ReadUint32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Mode")
+				return
+			}
+		case "mtime":
+			z.Mtime, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Mtime")
+				return
+			}
+		case "error":
+			z.Error, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *FileInfo) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Path) + 5 + msgp.Int64Size + 5 + msgp.Uint32Size + 6 + msgp.Int64Size + 6 + msgp.StringPrefixSize + len(z.Error)
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *FsStat) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "device":
+			z.Device, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Device")
+				return
+			}
+		case "mount_point":
+			z.MountPoint, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "MountPoint")
+				return
+			}
+		case "fs_type":
+			z.FsType, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "FsType")
+				return
+			}
+		case "total":
+			z.Total, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "Total")
+				return
+			}
+		case "free":
+			z.Free, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "Free")
+				return
+			}
+		case "avail":
+			z.Avail, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "Avail")
+				return
+			}
+		case "inodes":
+			z.Inodes, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "Inodes")
+				return
+			}
+		case "inodes_free":
+			z.InodesFree, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "InodesFree")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *FsStat) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 8
+	// write "device"
+	err = en.Append(0x88, 0xa6, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Device)
+	if err != nil {
+		err = msgp.WrapError(err, "Device")
+		return
+	}
+	// write "mount_point"
+	err = en.Append(0xab, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x70, 0x6f, 0x69, 0x6e, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.MountPoint)
+	if err != nil {
+		err = msgp.WrapError(err, "MountPoint")
+		return
+	}
+	// write "fs_type"
+	err = en.Append(0xa7, 0x66, 0x73, 0x5f, 0x74, 0x79, 0x70, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.FsType)
+	if err != nil {
+		err = msgp.WrapError(err, "FsType")
+		return
+	}
+	// write "total"
+	err = en.Append(0xa5, 0x74, 0x6f, 0x74, 0x61, 0x6c)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.Total)
+	if err != nil {
+		err = msgp.WrapError(err, "Total")
+		return
+	}
+	// write "free"
+	err = en.Append(0xa4, 0x66, 0x72, 0x65, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.Free)
+	if err != nil {
+		err = msgp.WrapError(err, "Free")
+		return
+	}
+	// write "avail"
+	err = en.Append(0xa5, 0x61, 0x76, 0x61, 0x69, 0x6c)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.Avail)
+	if err != nil {
+		err = msgp.WrapError(err, "Avail")
+		return
+	}
+	// write "inodes"
+	err = en.Append(0xa6, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.Inodes)
+	if err != nil {
+		err = msgp.WrapError(err, "Inodes")
+		return
+	}
+	// write "inodes_free"
+	err = en.Append(0xab, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x5f, 0x66, 0x72, 0x65, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.InodesFree)
+	if err != nil {
+		err = msgp.WrapError(err, "InodesFree")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *FsStat) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 8
+	// string "device"
+	o = append(o, 0x88, 0xa6, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65)
+	o = msgp.AppendString(o, z.Device)
+	// string "mount_point"
+	o = append(o, 0xab, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x70, 0x6f, 0x69, 0x6e, 0x74)
+	o = msgp.AppendString(o, z.MountPoint)
+	// string "fs_type"
+	o = append(o, 0xa7, 0x66, 0x73, 0x5f, 0x74, 0x79, 0x70, 0x65)
+	o = msgp.AppendString(o, z.FsType)
+	// string "total"
+	o = append(o, 0xa5, 0x74, 0x6f, 0x74, 0x61, 0x6c)
+	o = msgp.AppendUint64(o, z.Total)
+	// string "free"
+	o = append(o, 0xa4, 0x66, 0x72, 0x65, 0x65)
+	o = msgp.AppendUint64(o, z.Free)
+	// string "avail"
+	o = append(o, 0xa5, 0x61, 0x76, 0x61, 0x69, 0x6c)
+	o = msgp.AppendUint64(o, z.Avail)
+	// string "inodes"
+	o = append(o, 0xa6, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x73)
+	o = msgp.AppendUint64(o, z.Inodes)
+	// string "inodes_free"
+	o = append(o, 0xab, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x5f, 0x66, 0x72, 0x65, 0x65)
+	o = msgp.AppendUint64(o, z.InodesFree)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *FsStat) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "device":
+			z.Device, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Device")
+				return
+			}
+		case "mount_point":
+			z.MountPoint, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "MountPoint")
+				return
+			}
+		case "fs_type":
+			z.FsType, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "FsType")
+				return
+			}
+		case "total":
+			z.Total, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Total")
+				return
+			}
+		case "free":
+			z.Free, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Free")
+				return
+			}
+		case "avail":
+			z.Avail, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Avail")
+				return
+			}
+		case "inodes":
+			z.Inodes, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Inodes")
+				return
+			}
+		case "inodes_free":
+			z.InodesFree, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "InodesFree")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *FsStat) Msgsize() (s int) {
+	s = 1 + 7 + msgp.StringPrefixSize + len(z.Device) + 12 + msgp.StringPrefixSize + len(z.MountPoint) + 8 + msgp.StringPrefixSize + len(z.FsType) + 6 + msgp.Uint64Size + 5 + msgp.Uint64Size + 6 + msgp.Uint64Size + 7 + msgp.Uint64Size + 12 + msgp.Uint64Size
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ListDirRequest) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		case "cursor":
+			z.Cursor, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Cursor")
+				return
+			}
+		case "limit":
+			z.Limit, err = dc.ReadInt32()
+			if err != nil {
+				err = msgp.WrapError(err, "Limit")
+				return
+			}
+		case "sort_by":
+			z.SortBy, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "SortBy")
+				return
+			}
+		case "desc":
+			z.Desc, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "Desc")
+				return
+			}
+		case "show_hidden":
+			z.ShowHidden, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "ShowHidden")
+				return
+			}
+		case "count_only":
+			z.CountOnly, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "CountOnly")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *ListDirRequest) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 7
+	// write "path"
+	err = en.Append(0x87, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Path)
+	if err != nil {
+		err = msgp.WrapError(err, "Path")
+		return
+	}
+	// write "cursor"
+	err = en.Append(0xa6, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Cursor)
+	if err != nil {
+		err = msgp.WrapError(err, "Cursor")
+		return
+	}
+	// write "limit"
+	err = en.Append(0xa5, 0x6c, 0x69, 0x6d, 0x69, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt32(z.Limit)
+	if err != nil {
+		err = msgp.WrapError(err, "Limit")
+		return
+	}
+	// write "sort_by"
+	err = en.Append(0xa7, 0x73, 0x6f, 0x72, 0x74, 0x5f, 0x62, 0x79)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.SortBy)
+	if err != nil {
+		err = msgp.WrapError(err, "SortBy")
+		return
+	}
+	// write "desc"
+	err = en.Append(0xa4, 0x64, 0x65, 0x73, 0x63)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.Desc)
+	if err != nil {
+		err = msgp.WrapError(err, "Desc")
+		return
+	}
+	// write "show_hidden"
+	err = en.Append(0xab, 0x73, 0x68, 0x6f, 0x77, 0x5f, 0x68, 0x69, 0x64, 0x64, 0x65, 0x6e)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.ShowHidden)
+	if err != nil {
+		err = msgp.WrapError(err, "ShowHidden")
+		return
+	}
+	// write "count_only"
+	err = en.Append(0xaa, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x6f, 0x6e, 0x6c, 0x79)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.CountOnly)
+	if err != nil {
+		err = msgp.WrapError(err, "CountOnly")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *ListDirRequest) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 7
+	// string "path"
+	o = append(o, 0x87, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	o = msgp.AppendString(o, z.Path)
+	// string "cursor"
+	o = append(o, 0xa6, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72)
+	o = msgp.AppendString(o, z.Cursor)
+	// string "limit"
+	o = append(o, 0xa5, 0x6c, 0x69, 0x6d, 0x69, 0x74)
+	o = msgp.AppendInt32(o, z.Limit)
+	// string "sort_by"
+	o = append(o, 0xa7, 0x73, 0x6f, 0x72, 0x74, 0x5f, 0x62, 0x79)
+	o = msgp.AppendString(o, z.SortBy)
+	// string "desc"
+	o = append(o, 0xa4, 0x64, 0x65, 0x73, 0x63)
+	o = msgp.AppendBool(o, z.Desc)
+	// string "show_hidden"
+	o = append(o, 0xab, 0x73, 0x68, 0x6f, 0x77, 0x5f, 0x68, 0x69, 0x64, 0x64, 0x65, 0x6e)
+	o = msgp.AppendBool(o, z.ShowHidden)
+	// string "count_only"
+	o = append(o, 0xaa, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x6f, 0x6e, 0x6c, 0x79)
+	o = msgp.AppendBool(o, z.CountOnly)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ListDirRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		case "cursor":
+			z.Cursor, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Cursor")
+				return
+			}
+		case "limit":
+			z.Limit, bts, err = msgp.ReadInt32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Limit")
+				return
+			}
+		case "sort_by":
+			z.SortBy, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "SortBy")
+				return
+			}
+		case "desc":
+			z.Desc, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Desc")
+				return
+			}
+		case "show_hidden":
+			z.ShowHidden, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ShowHidden")
+				return
+			}
+		case "count_only":
+			z.CountOnly, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "CountOnly")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *ListDirRequest) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Path) + 7 + msgp.StringPrefixSize + len(z.Cursor) + 6 + msgp.Int32Size + 8 + msgp.StringPrefixSize + len(z.SortBy) + 5 + msgp.BoolSize + 12 + msgp.BoolSize + 11 + msgp.BoolSize
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ListDirResponse) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		case "entries":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Entries")
+				return
+			}
+			if cap(z.Entries) >= int(zb0002) {
+				z.Entries = (z.Entries)[:zb0002]
+			} else {
+				z.Entries = make([]FileInfo, zb0002)
+			}
+			for za0001 := range z.Entries {
+				err = z.Entries[za0001].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Entries", za0001)
+					return
+				}
+			}
+		case "total":
+			z.Total, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "Total")
+				return
+			}
+		case "next_cursor":
+			z.NextCursor, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "NextCursor")
+				return
+			}
+		case "error":
+			z.Error, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *ListDirResponse) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 5
+	// write "path"
+	err = en.Append(0x85, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Path)
+	if err != nil {
+		err = msgp.WrapError(err, "Path")
+		return
+	}
+	// write "entries"
+	err = en.Append(0xa7, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Entries)))
+	if err != nil {
+		err = msgp.WrapError(err, "Entries")
+		return
+	}
+	for za0001 := range z.Entries {
+		err = z.Entries[za0001].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Entries", za0001)
+			return
+		}
+	}
+	// write "total"
+	err = en.Append(0xa5, 0x74, 0x6f, 0x74, 0x61, 0x6c)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.Total)
+	if err != nil {
+		err = msgp.WrapError(err, "Total")
+		return
+	}
+	// write "next_cursor"
+	err = en.Append(0xab, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.NextCursor)
+	if err != nil {
+		err = msgp.WrapError(err, "NextCursor")
+		return
+	}
+	// write "error"
+	err = en.Append(0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Error)
+	if err != nil {
+		err = msgp.WrapError(err, "Error")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *ListDirResponse) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 5
+	// string "path"
+	o = append(o, 0x85, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	o = msgp.AppendString(o, z.Path)
+	// string "entries"
+	o = append(o, 0xa7, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Entries)))
+	for za0001 := range z.Entries {
+		o, err = z.Entries[za0001].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Entries", za0001)
+			return
+		}
+	}
+	// string "total"
+	o = append(o, 0xa5, 0x74, 0x6f, 0x74, 0x61, 0x6c)
+	o = msgp.AppendInt64(o, z.Total)
+	// string "next_cursor"
+	o = append(o, 0xab, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72)
+	o = msgp.AppendString(o, z.NextCursor)
+	// string "error"
+	o = append(o, 0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	o = msgp.AppendString(o, z.Error)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ListDirResponse) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		case "entries":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Entries")
+				return
+			}
+			if cap(z.Entries) >= int(zb0002) {
+				z.Entries = (z.Entries)[:zb0002]
+			} else {
+				z.Entries = make([]FileInfo, zb0002)
+			}
+			for za0001 := range z.Entries {
+				bts, err = z.Entries[za0001].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Entries", za0001)
+					return
+				}
+			}
+		case "total":
+			z.Total, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Total")
+				return
+			}
+		case "next_cursor":
+			z.NextCursor, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "NextCursor")
+				return
+			}
+		case "error":
+			z.Error, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *ListDirResponse) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Path) + 8 + msgp.ArrayHeaderSize
+	for za0001 := range z.Entries {
+		s += z.Entries[za0001].Msgsize()
+	}
+	s += 6 + msgp.Int64Size + 12 + msgp.StringPrefixSize + len(z.NextCursor) + 6 + msgp.StringPrefixSize + len(z.Error)
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *NetStat) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "interface":
+			z.Interface, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Interface")
+				return
+			}
+		case "rx_bytes":
+			z.RxBytes, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "RxBytes")
+				return
+			}
+		case "tx_bytes":
+			z.TxBytes, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "TxBytes")
+				return
+			}
+		case "rx_packets":
+			z.RxPackets, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "RxPackets")
+				return
+			}
+		case "tx_packets":
+			z.TxPackets, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "TxPackets")
+				return
+			}
+		case "rx_errors":
+			z.RxErrors, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "RxErrors")
+				return
+			}
+		case "tx_errors":
+			z.TxErrors, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "TxErrors")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *NetStat) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 7
+	// write "interface"
+	err = en.Append(0x87, 0xa9, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Interface)
+	if err != nil {
+		err = msgp.WrapError(err, "Interface")
+		return
+	}
+	// write "rx_bytes"
+	err = en.Append(0xa8, 0x72, 0x78, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.RxBytes)
+	if err != nil {
+		err = msgp.WrapError(err, "RxBytes")
+		return
+	}
+	// write "tx_bytes"
+	err = en.Append(0xa8, 0x74, 0x78, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.TxBytes)
+	if err != nil {
+		err = msgp.WrapError(err, "TxBytes")
+		return
+	}
+	// write "rx_packets"
+	err = en.Append(0xaa, 0x72, 0x78, 0x5f, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.RxPackets)
+	if err != nil {
+		err = msgp.WrapError(err, "RxPackets")
+		return
+	}
+	// write "tx_packets"
+	err = en.Append(0xaa, 0x74, 0x78, 0x5f, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.TxPackets)
+	if err != nil {
+		err = msgp.WrapError(err, "TxPackets")
+		return
+	}
+	// write "rx_errors"
+	err = en.Append(0xa9, 0x72, 0x78, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.RxErrors)
+	if err != nil {
+		err = msgp.WrapError(err, "RxErrors")
+		return
+	}
+	// write "tx_errors"
+	err = en.Append(0xa9, 0x74, 0x78, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.TxErrors)
+	if err != nil {
+		err = msgp.WrapError(err, "TxErrors")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *NetStat) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 7
+	// string "interface"
+	o = append(o, 0x87, 0xa9, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65)
+	o = msgp.AppendString(o, z.Interface)
+	// string "rx_bytes"
+	o = append(o, 0xa8, 0x72, 0x78, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73)
+	o = msgp.AppendUint64(o, z.RxBytes)
+	// string "tx_bytes"
+	o = append(o, 0xa8, 0x74, 0x78, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73)
+	o = msgp.AppendUint64(o, z.TxBytes)
+	// string "rx_packets"
+	o = append(o, 0xaa, 0x72, 0x78, 0x5f, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73)
+	o = msgp.AppendUint64(o, z.RxPackets)
+	// string "tx_packets"
+	o = append(o, 0xaa, 0x74, 0x78, 0x5f, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73)
+	o = msgp.AppendUint64(o, z.TxPackets)
+	// string "rx_errors"
+	o = append(o, 0xa9, 0x72, 0x78, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73)
+	o = msgp.AppendUint64(o, z.RxErrors)
+	// string "tx_errors"
+	o = append(o, 0xa9, 0x74, 0x78, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73)
+	o = msgp.AppendUint64(o, z.TxErrors)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *NetStat) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "interface":
+			z.Interface, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Interface")
+				return
+			}
+		case "rx_bytes":
+			z.RxBytes, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "RxBytes")
+				return
+			}
+		case "tx_bytes":
+			z.TxBytes, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "TxBytes")
+				return
+			}
+		case "rx_packets":
+			z.RxPackets, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "RxPackets")
+				return
+			}
+		case "tx_packets":
+			z.TxPackets, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "TxPackets")
+				return
+			}
+		case "rx_errors":
+			z.RxErrors, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "RxErrors")
+				return
+			}
+		case "tx_errors":
+			z.TxErrors, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "TxErrors")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *NetStat) Msgsize() (s int) {
+	s = 1 + 10 + msgp.StringPrefixSize + len(z.Interface) + 9 + msgp.Uint64Size + 9 + msgp.Uint64Size + 11 + msgp.Uint64Size + 11 + msgp.Uint64Size + 10 + msgp.Uint64Size + 10 + msgp.Uint64Size
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ProcessInfo) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "pid":
+			z.Pid, err = dc.ReadInt32()
+			if err != nil {
+				err = msgp.WrapError(err, "Pid")
+				return
+			}
+		case "ppid":
+			z.Ppid, err = dc.ReadInt32()
+			if err != nil {
+				err = msgp.WrapError(err, "Ppid")
+				return
+			}
+		case "user":
+			z.User, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "User")
+				return
+			}
+		case "name":
+			z.Name, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Name")
+				return
+			}
+		case "cmdline":
+			z.Cmdline, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Cmdline")
+				return
+			}
+		case "state":
+			z.State, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "State")
+				return
+			}
+		case "threads":
+			z.Threads, err = dc.ReadInt32()
+			if err != nil {
+				err = msgp.WrapError(err, "Threads")
+				return
+			}
+		case "cpu_percent":
+			z.CPUPercent, err = dc.ReadFloat64()
+			if err != nil {
+				err = msgp.WrapError(err, "CPUPercent")
+				return
+			}
+		case "rss":
+			z.RSS, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "RSS")
+				return
+			}
+		case "start_time":
+			z.StartTime, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "StartTime")
+				return
+			}
+		case "children":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Children")
+				return
+			}
+			if cap(z.Children) >= int(zb0002) {
+				z.Children = (z.Children)[:zb0002]
+			} else {
+				z.Children = make([]ProcessInfo, zb0002)
+			}
+			for za0001 := range z.Children {
+				err = z.Children[za0001].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Children", za0001)
+					return
+				}
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *ProcessInfo) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 11
+	// write "pid"
+	err = en.Append(0x8b, 0xa3, 0x70, 0x69, 0x64)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt32(z.Pid)
+	if err != nil {
+		err = msgp.WrapError(err, "Pid")
+		return
+	}
+	// write "ppid"
+	err = en.Append(0xa4, 0x70, 0x70, 0x69, 0x64)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt32(z.Ppid)
+	if err != nil {
+		err = msgp.WrapError(err, "Ppid")
+		return
+	}
+	// write "user"
+	err = en.Append(0xa4, 0x75, 0x73, 0x65, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.User)
+	if err != nil {
+		err = msgp.WrapError(err, "User")
+		return
+	}
+	// write "name"
+	err = en.Append(0xa4, 0x6e, 0x61, 0x6d, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Name)
+	if err != nil {
+		err = msgp.WrapError(err, "Name")
+		return
+	}
+	// write "cmdline"
+	err = en.Append(0xa7, 0x63, 0x6d, 0x64, 0x6c, 0x69, 0x6e, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Cmdline)
+	if err != nil {
+		err = msgp.WrapError(err, "Cmdline")
+		return
+	}
+	// write "state"
+	err = en.Append(0xa5, 0x73, 0x74, 0x61, 0x74, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.State)
+	if err != nil {
+		err = msgp.WrapError(err, "State")
+		return
+	}
+	// write "threads"
+	err = en.Append(0xa7, 0x74, 0x68, 0x72, 0x65, 0x61, 0x64, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt32(z.Threads)
+	if err != nil {
+		err = msgp.WrapError(err, "Threads")
+		return
+	}
+	// write "cpu_percent"
+	err = en.Append(0xab, 0x63, 0x70, 0x75, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteFloat64(z.CPUPercent)
+	if err != nil {
+		err = msgp.WrapError(err, "CPUPercent")
+		return
+	}
+	// write "rss"
+	err = en.Append(0xa3, 0x72, 0x73, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.RSS)
+	if err != nil {
+		err = msgp.WrapError(err, "RSS")
+		return
+	}
+	// write "start_time"
+	err = en.Append(0xaa, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.StartTime)
+	if err != nil {
+		err = msgp.WrapError(err, "StartTime")
+		return
+	}
+	// write "children"
+	err = en.Append(0xa8, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Children)))
+	if err != nil {
+		err = msgp.WrapError(err, "Children")
+		return
+	}
+	for za0001 := range z.Children {
+		err = z.Children[za0001].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Children", za0001)
+			return
+		}
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *ProcessInfo) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 11
+	// string "pid"
+	o = append(o, 0x8b, 0xa3, 0x70, 0x69, 0x64)
+	o = msgp.AppendInt32(o, z.Pid)
+	// string "ppid"
+	o = append(o, 0xa4, 0x70, 0x70, 0x69, 0x64)
+	o = msgp.AppendInt32(o, z.Ppid)
+	// string "user"
+	o = append(o, 0xa4, 0x75, 0x73, 0x65, 0x72)
+	o = msgp.AppendString(o, z.User)
+	// string "name"
+	o = append(o, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
+	o = msgp.AppendString(o, z.Name)
+	// string "cmdline"
+	o = append(o, 0xa7, 0x63, 0x6d, 0x64, 0x6c, 0x69, 0x6e, 0x65)
+	o = msgp.AppendString(o, z.Cmdline)
+	// string "state"
+	o = append(o, 0xa5, 0x73, 0x74, 0x61, 0x74, 0x65)
+	o = msgp.AppendString(o, z.State)
+	// string "threads"
+	o = append(o, 0xa7, 0x74, 0x68, 0x72, 0x65, 0x61, 0x64, 0x73)
+	o = msgp.AppendInt32(o, z.Threads)
+	// string "cpu_percent"
+	o = append(o, 0xab, 0x63, 0x70, 0x75, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74)
+	o = msgp.AppendFloat64(o, z.CPUPercent)
+	// string "rss"
+	o = append(o, 0xa3, 0x72, 0x73, 0x73)
+	o = msgp.AppendUint64(o, z.RSS)
+	// string "start_time"
+	o = append(o, 0xaa, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65)
+	o = msgp.AppendInt64(o, z.StartTime)
+	// string "children"
+	o = append(o, 0xa8, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Children)))
+	for za0001 := range z.Children {
+		o, err = z.Children[za0001].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Children", za0001)
+			return
+		}
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ProcessInfo) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "pid":
+			z.Pid, bts, err = msgp.ReadInt32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Pid")
+				return
+			}
+		case "ppid":
+			z.Ppid, bts, err = msgp.ReadInt32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Ppid")
+				return
+			}
+		case "user":
+			z.User, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "User")
+				return
+			}
+		case "name":
+			z.Name, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Name")
+				return
+			}
+		case "cmdline":
+			z.Cmdline, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Cmdline")
+				return
+			}
+		case "state":
+			z.State, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "State")
+				return
+			}
+		case "threads":
+			z.Threads, bts, err = msgp.ReadInt32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Threads")
+				return
+			}
+		case "cpu_percent":
+			z.CPUPercent, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "CPUPercent")
+				return
+			}
+		case "rss":
+			z.RSS, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "RSS")
+				return
+			}
+		case "start_time":
+			z.StartTime, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "StartTime")
+				return
+			}
+		case "children":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Children")
+				return
+			}
+			if cap(z.Children) >= int(zb0002) {
+				z.Children = (z.Children)[:zb0002]
+			} else {
+				z.Children = make([]ProcessInfo, zb0002)
+			}
+			for za0001 := range z.Children {
+				bts, err = z.Children[za0001].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Children", za0001)
+					return
+				}
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *ProcessInfo) Msgsize() (s int) {
+	s = 1 + 4 + msgp.Int32Size + 5 + msgp.Int32Size + 5 + msgp.StringPrefixSize + len(z.User) + 5 + msgp.StringPrefixSize + len(z.Name) + 8 + msgp.StringPrefixSize + len(z.Cmdline) + 6 + msgp.StringPrefixSize + len(z.State) + 8 + msgp.Int32Size + 12 + msgp.Float64Size + 4 + msgp.Uint64Size + 11 + msgp.Int64Size + 9 + msgp.ArrayHeaderSize
+	for za0001 := range z.Children {
+		s += z.Children[za0001].Msgsize()
+	}
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ProcessListRequest) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "tree":
+			z.Tree, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "Tree")
+				return
+			}
+		case "sample_ms":
+			z.SampleMs, err = dc.ReadInt32()
+			if err != nil {
+				err = msgp.WrapError(err, "SampleMs")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z ProcessListRequest) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 2
+	// write "tree"
+	err = en.Append(0x82, 0xa4, 0x74, 0x72, 0x65, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.Tree)
+	if err != nil {
+		err = msgp.WrapError(err, "Tree")
+		return
+	}
+	// write "sample_ms"
+	err = en.Append(0xa9, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x5f, 0x6d, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt32(z.SampleMs)
+	if err != nil {
+		err = msgp.WrapError(err, "SampleMs")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z ProcessListRequest) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 2
+	// string "tree"
+	o = append(o, 0x82, 0xa4, 0x74, 0x72, 0x65, 0x65)
+	o = msgp.AppendBool(o, z.Tree)
+	// string "sample_ms"
+	o = append(o, 0xa9, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x5f, 0x6d, 0x73)
+	o = msgp.AppendInt32(o, z.SampleMs)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ProcessListRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "tree":
+			z.Tree, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Tree")
+				return
+			}
+		case "sample_ms":
+			z.SampleMs, bts, err = msgp.ReadInt32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "SampleMs")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z ProcessListRequest) Msgsize() (s int) {
+	s = 1 + 5 + msgp.BoolSize + 10 + msgp.Int32Size
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ProcessListResponse) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "processes":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Processes")
+				return
+			}
+			if cap(z.Processes) >= int(zb0002) {
+				z.Processes = (z.Processes)[:zb0002]
+			} else {
+				z.Processes = make([]ProcessInfo, zb0002)
+			}
+			for za0001 := range z.Processes {
+				err = z.Processes[za0001].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Processes", za0001)
+					return
+				}
+			}
+		case "error":
+			z.Error, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *ProcessListResponse) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 2
+	// write "processes"
+	err = en.Append(0x82, 0xa9, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Processes)))
+	if err != nil {
+		err = msgp.WrapError(err, "Processes")
+		return
+	}
+	for za0001 := range z.Processes {
+		err = z.Processes[za0001].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Processes", za0001)
+			return
+		}
+	}
+	// write "error"
+	err = en.Append(0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Error)
+	if err != nil {
+		err = msgp.WrapError(err, "Error")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *ProcessListResponse) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 2
+	// string "processes"
+	o = append(o, 0x82, 0xa9, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Processes)))
+	for za0001 := range z.Processes {
+		o, err = z.Processes[za0001].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Processes", za0001)
+			return
+		}
+	}
+	// string "error"
+	o = append(o, 0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	o = msgp.AppendString(o, z.Error)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ProcessListResponse) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "processes":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Processes")
+				return
+			}
+			if cap(z.Processes) >= int(zb0002) {
+				z.Processes = (z.Processes)[:zb0002]
+			} else {
+				z.Processes = make([]ProcessInfo, zb0002)
+			}
+			for za0001 := range z.Processes {
+				bts, err = z.Processes[za0001].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Processes", za0001)
+					return
+				}
+			}
+		case "error":
+			z.Error, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *ProcessListResponse) Msgsize() (s int) {
+	s = 1 + 10 + msgp.ArrayHeaderSize
+	for za0001 := range z.Processes {
+		s += z.Processes[za0001].Msgsize()
+	}
+	s += 6 + msgp.StringPrefixSize + len(z.Error)
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ProcessSignalRequest) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "pid":
+			z.Pid, err = dc.ReadInt32()
+			if err != nil {
+				err = msgp.WrapError(err, "Pid")
+				return
+			}
+		case "signal":
+			z.Signal, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Signal")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z ProcessSignalRequest) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 2
+	// write "pid"
+	err = en.Append(0x82, 0xa3, 0x70, 0x69, 0x64)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt32(z.Pid)
+	if err != nil {
+		err = msgp.WrapError(err, "Pid")
+		return
+	}
+	// write "signal"
+	err = en.Append(0xa6, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Signal)
+	if err != nil {
+		err = msgp.WrapError(err, "Signal")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z ProcessSignalRequest) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 2
+	// string "pid"
+	o = append(o, 0x82, 0xa3, 0x70, 0x69, 0x64)
+	o = msgp.AppendInt32(o, z.Pid)
+	// string "signal"
+	o = append(o, 0xa6, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c)
+	o = msgp.AppendString(o, z.Signal)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ProcessSignalRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "pid":
+			z.Pid, bts, err = msgp.ReadInt32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Pid")
+				return
+			}
+		case "signal":
+			z.Signal, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Signal")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z ProcessSignalRequest) Msgsize() (s int) {
+	s = 1 + 4 + msgp.Int32Size + 7 + msgp.StringPrefixSize + len(z.Signal)
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ProcessSignalResponse) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "error":
+			z.Error, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z ProcessSignalResponse) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 1
+	// write "error"
+	err = en.Append(0x81, 0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Error)
+	if err != nil {
+		err = msgp.WrapError(err, "Error")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z ProcessSignalResponse) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 1
+	// string "error"
+	o = append(o, 0x81, 0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	o = msgp.AppendString(o, z.Error)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ProcessSignalResponse) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "error":
+			z.Error, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z ProcessSignalResponse) Msgsize() (s int) {
+	s = 1 + 6 + msgp.StringPrefixSize + len(z.Error)
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ProcessStat) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "pid":
+			z.Pid, err = dc.ReadInt32()
+			if err != nil {
+				err = msgp.WrapError(err, "Pid")
+				return
+			}
+		case "name":
+			z.Name, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Name")
+				return
+			}
+		case "cpu_percent":
+			z.CPUPercent, err = dc.ReadFloat64()
+			if err != nil {
+				err = msgp.WrapError(err, "CPUPercent")
+				return
+			}
+		case "rss":
+			z.RSS, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "RSS")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *ProcessStat) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 4
+	// write "pid"
+	err = en.Append(0x84, 0xa3, 0x70, 0x69, 0x64)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt32(z.Pid)
+	if err != nil {
+		err = msgp.WrapError(err, "Pid")
+		return
+	}
+	// write "name"
+	err = en.Append(0xa4, 0x6e, 0x61, 0x6d, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Name)
+	if err != nil {
+		err = msgp.WrapError(err, "Name")
+		return
+	}
+	// write "cpu_percent"
+	err = en.Append(0xab, 0x63, 0x70, 0x75, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteFloat64(z.CPUPercent)
+	if err != nil {
+		err = msgp.WrapError(err, "CPUPercent")
+		return
+	}
+	// write "rss"
+	err = en.Append(0xa3, 0x72, 0x73, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.RSS)
+	if err != nil {
+		err = msgp.WrapError(err, "RSS")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *ProcessStat) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 4
+	// string "pid"
+	o = append(o, 0x84, 0xa3, 0x70, 0x69, 0x64)
+	o = msgp.AppendInt32(o, z.Pid)
+	// string "name"
+	o = append(o, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
+	o = msgp.AppendString(o, z.Name)
+	// string "cpu_percent"
+	o = append(o, 0xab, 0x63, 0x70, 0x75, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74)
+	o = msgp.AppendFloat64(o, z.CPUPercent)
+	// string "rss"
+	o = append(o, 0xa3, 0x72, 0x73, 0x73)
+	o = msgp.AppendUint64(o, z.RSS)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ProcessStat) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "pid":
+			z.Pid, bts, err = msgp.ReadInt32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Pid")
+				return
+			}
+		case "name":
+			z.Name, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Name")
+				return
+			}
+		case "cpu_percent":
+			z.CPUPercent, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "CPUPercent")
+				return
+			}
+		case "rss":
+			z.RSS, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "RSS")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *ProcessStat) Msgsize() (s int) {
+	s = 1 + 4 + msgp.Int32Size + 5 + msgp.StringPrefixSize + len(z.Name) + 12 + msgp.Float64Size + 4 + msgp.Uint64Size
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ProxyHttpHeader) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "name":
+			z.Name, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Name")
+				return
+			}
+		case "value":
+			z.Value, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Value")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z ProxyHttpHeader) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 2
+	// write "name"
+	err = en.Append(0x82, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Name)
+	if err != nil {
+		err = msgp.WrapError(err, "Name")
+		return
+	}
+	// write "value"
+	err = en.Append(0xa5, 0x76, 0x61, 0x6c, 0x75, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Value)
+	if err != nil {
+		err = msgp.WrapError(err, "Value")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z ProxyHttpHeader) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 2
+	// string "name"
+	o = append(o, 0x82, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
+	o = msgp.AppendString(o, z.Name)
+	// string "value"
+	o = append(o, 0xa5, 0x76, 0x61, 0x6c, 0x75, 0x65)
+	o = msgp.AppendString(o, z.Value)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ProxyHttpHeader) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "name":
+			z.Name, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Name")
+				return
+			}
+		case "value":
+			z.Value, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Value")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z ProxyHttpHeader) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Name) + 6 + msgp.StringPrefixSize + len(z.Value)
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ProxyHttpRequest) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "method":
+			z.Method, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Method")
+				return
+			}
+		case "url":
+			z.URL, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "URL")
+				return
+			}
+		case "headers":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Headers")
+				return
+			}
+			if cap(z.Headers) >= int(zb0002) {
+				z.Headers = (z.Headers)[:zb0002]
+			} else {
+				z.Headers = make([]ProxyHttpHeader, zb0002)
+			}
+			for za0001 := range z.Headers {
+				var zb0003 uint32
+				zb0003, err = dc.ReadMapHeader()
+				if err != nil {
+					err = msgp.WrapError(err, "Headers", za0001)
+					return
+				}
+				for zb0003 > 0 {
+					zb0003--
+					field, err = dc.ReadMapKeyPtr()
+					if err != nil {
+						err = msgp.WrapError(err, "Headers", za0001)
+						return
+					}
+					switch msgp.UnsafeString(field) {
+					case "name":
+						z.Headers[za0001].Name, err = dc.ReadString()
+						if err != nil {
+							err = msgp.WrapError(err, "Headers", za0001, "Name")
+							return
+						}
+					case "value":
+						z.Headers[za0001].Value, err = dc.ReadString()
+						if err != nil {
+							err = msgp.WrapError(err, "Headers", za0001, "Value")
+							return
+						}
+					default:
+						err = dc.Skip()
+						if err != nil {
+							err = msgp.WrapError(err, "Headers", za0001)
+							return
+						}
+					}
+				}
+			}
+		case "host":
+			z.Host, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Host")
+				return
+			}
+		case "body":
+			z.Body, err = dc.ReadBytes(z.Body)
+			if err != nil {
+				err = msgp.WrapError(err, "Body")
+				return
+			}
+		case "body_stream":
+			z.BodyStream, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "BodyStream")
+				return
+			}
+		case "window":
+			z.Window, err = dc.ReadUint32()
+			if err != nil {
+				err = msgp.WrapError(err, "Window")
+				return
+			}
+		case "no_follow_redirects":
+			z.NoFollowRedirects, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "NoFollowRedirects")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *ProxyHttpRequest) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 8
+	// write "method"
+	err = en.Append(0x88, 0xa6, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Method)
+	if err != nil {
+		err = msgp.WrapError(err, "Method")
+		return
+	}
+	// write "url"
+	err = en.Append(0xa3, 0x75, 0x72, 0x6c)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.URL)
+	if err != nil {
+		err = msgp.WrapError(err, "URL")
+		return
+	}
+	// write "headers"
+	err = en.Append(0xa7, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Headers)))
+	if err != nil {
+		err = msgp.WrapError(err, "Headers")
+		return
+	}
+	for za0001 := range z.Headers {
+		// map header, size 2
+		// write "name"
+		err = en.Append(0x82, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.Headers[za0001].Name)
+		if err != nil {
+			err = msgp.WrapError(err, "Headers", za0001, "Name")
+			return
+		}
+		// write "value"
+		err = en.Append(0xa5, 0x76, 0x61, 0x6c, 0x75, 0x65)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.Headers[za0001].Value)
+		if err != nil {
+			err = msgp.WrapError(err, "Headers", za0001, "Value")
+			return
+		}
+	}
+	// write "host"
+	err = en.Append(0xa4, 0x68, 0x6f, 0x73, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Host)
+	if err != nil {
+		err = msgp.WrapError(err, "Host")
+		return
+	}
+	// write "body"
+	err = en.Append(0xa4, 0x62, 0x6f, 0x64, 0x79)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.Body)
+	if err != nil {
+		err = msgp.WrapError(err, "Body")
+		return
+	}
+	// write "body_stream"
+	err = en.Append(0xab, 0x62, 0x6f, 0x64, 0x79, 0x5f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.BodyStream)
+	if err != nil {
+		err = msgp.WrapError(err, "BodyStream")
+		return
+	}
+	// write "window"
+	err = en.Append(0xa6, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint32(z.Window)
+	if err != nil {
+		err = msgp.WrapError(err, "Window")
+		return
+	}
+	// write "no_follow_redirects"
+	err = en.Append(0xb3, 0x6e, 0x6f, 0x5f, 0x66, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x72, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.NoFollowRedirects)
+	if err != nil {
+		err = msgp.WrapError(err, "NoFollowRedirects")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *ProxyHttpRequest) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 8
+	// string "method"
+	o = append(o, 0x88, 0xa6, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64)
+	o = msgp.AppendString(o, z.Method)
+	// string "url"
+	o = append(o, 0xa3, 0x75, 0x72, 0x6c)
+	o = msgp.AppendString(o, z.URL)
+	// string "headers"
+	o = append(o, 0xa7, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Headers)))
+	for za0001 := range z.Headers {
+		// map header, size 2
+		// string "name"
+		o = append(o, 0x82, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
+		o = msgp.AppendString(o, z.Headers[za0001].Name)
+		// string "value"
+		o = append(o, 0xa5, 0x76, 0x61, 0x6c, 0x75, 0x65)
+		o = msgp.AppendString(o, z.Headers[za0001].Value)
+	}
+	// string "host"
+	o = append(o, 0xa4, 0x68, 0x6f, 0x73, 0x74)
+	o = msgp.AppendString(o, z.Host)
+	// string "body"
+	o = append(o, 0xa4, 0x62, 0x6f, 0x64, 0x79)
+	o = msgp.AppendBytes(o, z.Body)
+	// string "body_stream"
+	o = append(o, 0xab, 0x62, 0x6f, 0x64, 0x79, 0x5f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d)
+	o = msgp.AppendBool(o, z.BodyStream)
+	// string "window"
+	o = append(o, 0xa6, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77)
+	o = msgp.AppendUint32(o, z.Window)
+	// string "no_follow_redirects"
+	o = append(o, 0xb3, 0x6e, 0x6f, 0x5f, 0x66, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x72, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x73)
+	o = msgp.AppendBool(o, z.NoFollowRedirects)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ProxyHttpRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "method":
+			z.Method, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Method")
+				return
+			}
+		case "url":
+			z.URL, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "URL")
+				return
+			}
+		case "headers":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Headers")
+				return
+			}
+			if cap(z.Headers) >= int(zb0002) {
+				z.Headers = (z.Headers)[:zb0002]
+			} else {
+				z.Headers = make([]ProxyHttpHeader, zb0002)
+			}
+			for za0001 := range z.Headers {
+				var zb0003 uint32
+				zb0003, bts, err = msgp.ReadMapHeaderBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Headers", za0001)
+					return
+				}
+				for zb0003 > 0 {
+					zb0003--
+					field, bts, err = msgp.ReadMapKeyZC(bts)
+					if err != nil {
+						err = msgp.WrapError(err, "Headers", za0001)
+						return
+					}
+					switch msgp.UnsafeString(field) {
+					case "name":
+						z.Headers[za0001].Name, bts, err = msgp.ReadStringBytes(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Headers", za0001, "Name")
+							return
+						}
+					case "value":
+						z.Headers[za0001].Value, bts, err = msgp.ReadStringBytes(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Headers", za0001, "Value")
+							return
+						}
+					default:
+						bts, err = msgp.Skip(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Headers", za0001)
+							return
+						}
+					}
+				}
+			}
+		case "host":
+			z.Host, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Host")
+				return
+			}
+		case "body":
+			z.Body, bts, err = msgp.ReadBytesBytes(bts, z.Body)
+			if err != nil {
+				err = msgp.WrapError(err, "Body")
+				return
+			}
+		case "body_stream":
+			z.BodyStream, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "BodyStream")
+				return
+			}
+		case "window":
+			z.Window, bts, err = msgp.ReadUint32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Window")
+				return
+			}
+		case "no_follow_redirects":
+			z.NoFollowRedirects, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "NoFollowRedirects")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *ProxyHttpRequest) Msgsize() (s int) {
+	s = 1 + 7 + msgp.StringPrefixSize + len(z.Method) + 4 + msgp.StringPrefixSize + len(z.URL) + 8 + msgp.ArrayHeaderSize
+	for za0001 := range z.Headers {
+		s += 1 + 5 + msgp.StringPrefixSize + len(z.Headers[za0001].Name) + 6 + msgp.StringPrefixSize + len(z.Headers[za0001].Value)
+	}
+	s += 5 + msgp.StringPrefixSize + len(z.Host) + 5 + msgp.BytesPrefixSize + len(z.Body) + 12 + msgp.BoolSize + 7 + msgp.Uint32Size + 20 + msgp.BoolSize
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ProxyHttpResponse) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "connection_error":
+			z.ConnectionError, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "ConnectionError")
+				return
+			}
+		case "status_code":
+			z.StatusCode, err = dc.ReadInt32()
+			if err != nil {
+				err = msgp.WrapError(err, "StatusCode")
+				return
+			}
+		case "headers":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Headers")
+				return
+			}
+			if cap(z.Headers) >= int(zb0002) {
+				z.Headers = (z.Headers)[:zb0002]
+			} else {
+				z.Headers = make([]ProxyHttpHeader, zb0002)
+			}
+			for za0001 := range z.Headers {
+				var zb0003 uint32
+				zb0003, err = dc.ReadMapHeader()
+				if err != nil {
+					err = msgp.WrapError(err, "Headers", za0001)
+					return
+				}
+				for zb0003 > 0 {
+					zb0003--
+					field, err = dc.ReadMapKeyPtr()
+					if err != nil {
+						err = msgp.WrapError(err, "Headers", za0001)
+						return
+					}
+					switch msgp.UnsafeString(field) {
+					case "name":
+						z.Headers[za0001].Name, err = dc.ReadString()
+						if err != nil {
+							err = msgp.WrapError(err, "Headers", za0001, "Name")
+							return
+						}
+					case "value":
+						z.Headers[za0001].Value, err = dc.ReadString()
+						if err != nil {
+							err = msgp.WrapError(err, "Headers", za0001, "Value")
+							return
+						}
+					default:
+						err = dc.Skip()
+						if err != nil {
+							err = msgp.WrapError(err, "Headers", za0001)
+							return
+						}
+					}
+				}
+			}
+		case "is_websocket":
+			z.IsWebSocket, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "IsWebSocket")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *ProxyHttpResponse) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 4
+	// write "connection_error"
+	err = en.Append(0x84, 0xb0, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.ConnectionError)
+	if err != nil {
+		err = msgp.WrapError(err, "ConnectionError")
+		return
+	}
+	// write "status_code"
+	err = en.Append(0xab, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt32(z.StatusCode)
+	if err != nil {
+		err = msgp.WrapError(err, "StatusCode")
+		return
+	}
+	// write "headers"
+	err = en.Append(0xa7, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Headers)))
+	if err != nil {
+		err = msgp.WrapError(err, "Headers")
+		return
+	}
+	for za0001 := range z.Headers {
+		// map header, size 2
+		// write "name"
+		err = en.Append(0x82, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.Headers[za0001].Name)
+		if err != nil {
+			err = msgp.WrapError(err, "Headers", za0001, "Name")
+			return
+		}
+		// write "value"
+		err = en.Append(0xa5, 0x76, 0x61, 0x6c, 0x75, 0x65)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.Headers[za0001].Value)
+		if err != nil {
+			err = msgp.WrapError(err, "Headers", za0001, "Value")
+			return
+		}
+	}
+	// write "is_websocket"
+	err = en.Append(0xac, 0x69, 0x73, 0x5f, 0x77, 0x65, 0x62, 0x73, 0x6f, 0x63, 0x6b, 0x65, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.IsWebSocket)
+	if err != nil {
+		err = msgp.WrapError(err, "IsWebSocket")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *ProxyHttpResponse) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 4
+	// string "connection_error"
+	o = append(o, 0x84, 0xb0, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	o = msgp.AppendString(o, z.ConnectionError)
+	// string "status_code"
+	o = append(o, 0xab, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65)
+	o = msgp.AppendInt32(o, z.StatusCode)
+	// string "headers"
+	o = append(o, 0xa7, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Headers)))
+	for za0001 := range z.Headers {
+		// map header, size 2
+		// string "name"
+		o = append(o, 0x82, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
+		o = msgp.AppendString(o, z.Headers[za0001].Name)
+		// string "value"
+		o = append(o, 0xa5, 0x76, 0x61, 0x6c, 0x75, 0x65)
+		o = msgp.AppendString(o, z.Headers[za0001].Value)
+	}
+	// string "is_websocket"
+	o = append(o, 0xac, 0x69, 0x73, 0x5f, 0x77, 0x65, 0x62, 0x73, 0x6f, 0x63, 0x6b, 0x65, 0x74)
+	o = msgp.AppendBool(o, z.IsWebSocket)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ProxyHttpResponse) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "connection_error":
+			z.ConnectionError, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ConnectionError")
+				return
+			}
+		case "status_code":
+			z.StatusCode, bts, err = msgp.ReadInt32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "StatusCode")
+				return
+			}
+		case "headers":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Headers")
+				return
+			}
+			if cap(z.Headers) >= int(zb0002) {
+				z.Headers = (z.Headers)[:zb0002]
+			} else {
+				z.Headers = make([]ProxyHttpHeader, zb0002)
+			}
+			for za0001 := range z.Headers {
+				var zb0003 uint32
+				zb0003, bts, err = msgp.ReadMapHeaderBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Headers", za0001)
+					return
+				}
+				for zb0003 > 0 {
+					zb0003--
+					field, bts, err = msgp.ReadMapKeyZC(bts)
+					if err != nil {
+						err = msgp.WrapError(err, "Headers", za0001)
+						return
+					}
+					switch msgp.UnsafeString(field) {
+					case "name":
+						z.Headers[za0001].Name, bts, err = msgp.ReadStringBytes(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Headers", za0001, "Name")
+							return
+						}
+					case "value":
+						z.Headers[za0001].Value, bts, err = msgp.ReadStringBytes(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Headers", za0001, "Value")
+							return
+						}
+					default:
+						bts, err = msgp.Skip(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Headers", za0001)
+							return
+						}
+					}
+				}
+			}
+		case "is_websocket":
+			z.IsWebSocket, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "IsWebSocket")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *ProxyHttpResponse) Msgsize() (s int) {
+	s = 1 + 17 + msgp.StringPrefixSize + len(z.ConnectionError) + 12 + msgp.Int32Size + 8 + msgp.ArrayHeaderSize
+	for za0001 := range z.Headers {
+		s += 1 + 5 + msgp.StringPrefixSize + len(z.Headers[za0001].Name) + 6 + msgp.StringPrefixSize + len(z.Headers[za0001].Value)
+	}
+	s += 13 + msgp.BoolSize
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ProxyOpenRequest) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "network":
+			z.Network, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Network")
+				return
+			}
+		case "address":
+			z.Address, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Address")
+				return
+			}
+		case "window":
+			z.Window, err = dc.ReadUint32()
+			if err != nil {
+				err = msgp.WrapError(err, "Window")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z ProxyOpenRequest) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 3
+	// write "network"
+	err = en.Append(0x83, 0xa7, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Network)
+	if err != nil {
+		err = msgp.WrapError(err, "Network")
+		return
+	}
+	// write "address"
+	err = en.Append(0xa7, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Address)
+	if err != nil {
+		err = msgp.WrapError(err, "Address")
+		return
+	}
+	// write "window"
+	err = en.Append(0xa6, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint32(z.Window)
+	if err != nil {
+		err = msgp.WrapError(err, "Window")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z ProxyOpenRequest) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 3
+	// string "network"
+	o = append(o, 0x83, 0xa7, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b)
+	o = msgp.AppendString(o, z.Network)
+	// string "address"
+	o = append(o, 0xa7, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73)
+	o = msgp.AppendString(o, z.Address)
+	// string "window"
+	o = append(o, 0xa6, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77)
+	o = msgp.AppendUint32(o, z.Window)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ProxyOpenRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "network":
+			z.Network, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Network")
+				return
+			}
+		case "address":
+			z.Address, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Address")
+				return
+			}
+		case "window":
+			z.Window, bts, err = msgp.ReadUint32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Window")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z ProxyOpenRequest) Msgsize() (s int) {
+	s = 1 + 8 + msgp.StringPrefixSize + len(z.Network) + 8 + msgp.StringPrefixSize + len(z.Address) + 7 + msgp.Uint32Size
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ServiceActionRequest) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "unit":
+			z.Unit, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Unit")
+				return
+			}
+		case "action":
+			z.Action, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Action")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z ServiceActionRequest) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 2
+	// write "unit"
+	err = en.Append(0x82, 0xa4, 0x75, 0x6e, 0x69, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Unit)
+	if err != nil {
+		err = msgp.WrapError(err, "Unit")
+		return
+	}
+	// write "action"
+	err = en.Append(0xa6, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Action)
+	if err != nil {
+		err = msgp.WrapError(err, "Action")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z ServiceActionRequest) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 2
+	// string "unit"
+	o = append(o, 0x82, 0xa4, 0x75, 0x6e, 0x69, 0x74)
+	o = msgp.AppendString(o, z.Unit)
+	// string "action"
+	o = append(o, 0xa6, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e)
+	o = msgp.AppendString(o, z.Action)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ServiceActionRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "unit":
+			z.Unit, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Unit")
+				return
+			}
+		case "action":
+			z.Action, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Action")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z ServiceActionRequest) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Unit) + 7 + msgp.StringPrefixSize + len(z.Action)
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ServiceActionResponse) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "unit":
+			err = z.Unit.DecodeMsg(dc)
+			if err != nil {
+				err = msgp.WrapError(err, "Unit")
+				return
+			}
+		case "error":
+			z.Error, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *ServiceActionResponse) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 2
+	// write "unit"
+	err = en.Append(0x82, 0xa4, 0x75, 0x6e, 0x69, 0x74)
+	if err != nil {
+		return
+	}
+	err = z.Unit.EncodeMsg(en)
+	if err != nil {
+		err = msgp.WrapError(err, "Unit")
+		return
+	}
+	// write "error"
+	err = en.Append(0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Error)
+	if err != nil {
+		err = msgp.WrapError(err, "Error")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *ServiceActionResponse) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 2
+	// string "unit"
+	o = append(o, 0x82, 0xa4, 0x75, 0x6e, 0x69, 0x74)
+	o, err = z.Unit.MarshalMsg(o)
+	if err != nil {
+		err = msgp.WrapError(err, "Unit")
+		return
+	}
+	// string "error"
+	o = append(o, 0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	o = msgp.AppendString(o, z.Error)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ServiceActionResponse) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "unit":
+			bts, err = z.Unit.UnmarshalMsg(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Unit")
+				return
+			}
+		case "error":
+			z.Error, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *ServiceActionResponse) Msgsize() (s int) {
+	s = 1 + 5 + z.Unit.Msgsize() + 6 + msgp.StringPrefixSize + len(z.Error)
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ServiceJournalRequest) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "unit":
+			z.Unit, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Unit")
+				return
+			}
+		case "lines":
+			z.Lines, err = dc.ReadInt32()
+			if err != nil {
+				err = msgp.WrapError(err, "Lines")
+				return
+			}
+		case "since":
+			z.Since, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Since")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z ServiceJournalRequest) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 3
+	// write "unit"
+	err = en.Append(0x83, 0xa4, 0x75, 0x6e, 0x69, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Unit)
+	if err != nil {
+		err = msgp.WrapError(err, "Unit")
+		return
+	}
+	// write "lines"
+	err = en.Append(0xa5, 0x6c, 0x69, 0x6e, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt32(z.Lines)
+	if err != nil {
+		err = msgp.WrapError(err, "Lines")
+		return
+	}
+	// write "since"
+	err = en.Append(0xa5, 0x73, 0x69, 0x6e, 0x63, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Since)
+	if err != nil {
+		err = msgp.WrapError(err, "Since")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z ServiceJournalRequest) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 3
+	// string "unit"
+	o = append(o, 0x83, 0xa4, 0x75, 0x6e, 0x69, 0x74)
+	o = msgp.AppendString(o, z.Unit)
+	// string "lines"
+	o = append(o, 0xa5, 0x6c, 0x69, 0x6e, 0x65, 0x73)
+	o = msgp.AppendInt32(o, z.Lines)
+	// string "since"
+	o = append(o, 0xa5, 0x73, 0x69, 0x6e, 0x63, 0x65)
+	o = msgp.AppendString(o, z.Since)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ServiceJournalRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "unit":
+			z.Unit, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Unit")
+				return
+			}
+		case "lines":
+			z.Lines, bts, err = msgp.ReadInt32Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Lines")
+				return
+			}
+		case "since":
+			z.Since, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Since")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z ServiceJournalRequest) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Unit) + 6 + msgp.Int32Size + 6 + msgp.StringPrefixSize + len(z.Since)
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ServiceJournalResponse) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "lines":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Lines")
+				return
+			}
+			if cap(z.Lines) >= int(zb0002) {
+				z.Lines = (z.Lines)[:zb0002]
+			} else {
+				z.Lines = make([]string, zb0002)
+			}
+			for za0001 := range z.Lines {
+				z.Lines[za0001], err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "Lines", za0001)
+					return
+				}
+			}
+		case "error":
+			z.Error, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *ServiceJournalResponse) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 2
+	// write "lines"
+	err = en.Append(0x82, 0xa5, 0x6c, 0x69, 0x6e, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Lines)))
+	if err != nil {
+		err = msgp.WrapError(err, "Lines")
+		return
+	}
+	for za0001 := range z.Lines {
+		err = en.WriteString(z.Lines[za0001])
+		if err != nil {
+			err = msgp.WrapError(err, "Lines", za0001)
+			return
+		}
+	}
+	// write "error"
+	err = en.Append(0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Error)
+	if err != nil {
+		err = msgp.WrapError(err, "Error")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *ServiceJournalResponse) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 2
+	// string "lines"
+	o = append(o, 0x82, 0xa5, 0x6c, 0x69, 0x6e, 0x65, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Lines)))
+	for za0001 := range z.Lines {
+		o = msgp.AppendString(o, z.Lines[za0001])
+	}
+	// string "error"
+	o = append(o, 0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	o = msgp.AppendString(o, z.Error)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ServiceJournalResponse) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "lines":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Lines")
+				return
+			}
+			if cap(z.Lines) >= int(zb0002) {
+				z.Lines = (z.Lines)[:zb0002]
+			} else {
+				z.Lines = make([]string, zb0002)
+			}
+			for za0001 := range z.Lines {
+				z.Lines[za0001], bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Lines", za0001)
+					return
+				}
+			}
+		case "error":
+			z.Error, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *ServiceJournalResponse) Msgsize() (s int) {
+	s = 1 + 6 + msgp.ArrayHeaderSize
+	for za0001 := range z.Lines {
+		s += msgp.StringPrefixSize + len(z.Lines[za0001])
+	}
+	s += 6 + msgp.StringPrefixSize + len(z.Error)
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ServiceListRequest) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "type":
+			z.Type, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Type")
+				return
+			}
+		case "all":
+			z.All, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "All")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z ServiceListRequest) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 2
+	// write "type"
+	err = en.Append(0x82, 0xa4, 0x74, 0x79, 0x70, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Type)
+	if err != nil {
+		err = msgp.WrapError(err, "Type")
+		return
+	}
+	// write "all"
+	err = en.Append(0xa3, 0x61, 0x6c, 0x6c)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.All)
+	if err != nil {
+		err = msgp.WrapError(err, "All")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z ServiceListRequest) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 2
+	// string "type"
+	o = append(o, 0x82, 0xa4, 0x74, 0x79, 0x70, 0x65)
+	o = msgp.AppendString(o, z.Type)
+	// string "all"
+	o = append(o, 0xa3, 0x61, 0x6c, 0x6c)
+	o = msgp.AppendBool(o, z.All)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ServiceListRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "type":
+			z.Type, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Type")
+				return
+			}
+		case "all":
+			z.All, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "All")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z ServiceListRequest) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Type) + 4 + msgp.BoolSize
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ServiceListResponse) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "units":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Units")
+				return
+			}
+			if cap(z.Units) >= int(zb0002) {
+				z.Units = (z.Units)[:zb0002]
+			} else {
+				z.Units = make([]ServiceUnit, zb0002)
+			}
+			for za0001 := range z.Units {
+				err = z.Units[za0001].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Units", za0001)
+					return
+				}
+			}
+		case "error":
+			z.Error, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *ServiceListResponse) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 2
+	// write "units"
+	err = en.Append(0x82, 0xa5, 0x75, 0x6e, 0x69, 0x74, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Units)))
+	if err != nil {
+		err = msgp.WrapError(err, "Units")
+		return
+	}
+	for za0001 := range z.Units {
+		err = z.Units[za0001].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Units", za0001)
+			return
+		}
+	}
+	// write "error"
+	err = en.Append(0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Error)
+	if err != nil {
+		err = msgp.WrapError(err, "Error")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *ServiceListResponse) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 2
+	// string "units"
+	o = append(o, 0x82, 0xa5, 0x75, 0x6e, 0x69, 0x74, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Units)))
+	for za0001 := range z.Units {
+		o, err = z.Units[za0001].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Units", za0001)
+			return
+		}
+	}
+	// string "error"
+	o = append(o, 0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	o = msgp.AppendString(o, z.Error)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ServiceListResponse) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "units":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Units")
+				return
+			}
+			if cap(z.Units) >= int(zb0002) {
+				z.Units = (z.Units)[:zb0002]
+			} else {
+				z.Units = make([]ServiceUnit, zb0002)
+			}
+			for za0001 := range z.Units {
+				bts, err = z.Units[za0001].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Units", za0001)
+					return
+				}
+			}
+		case "error":
+			z.Error, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *ServiceListResponse) Msgsize() (s int) {
+	s = 1 + 6 + msgp.ArrayHeaderSize
+	for za0001 := range z.Units {
+		s += z.Units[za0001].Msgsize()
+	}
+	s += 6 + msgp.StringPrefixSize + len(z.Error)
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *ServiceUnit) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "unit":
+			z.Unit, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Unit")
+				return
+			}
+		case "load":
+			z.Load, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Load")
+				return
+			}
+		case "active":
+			z.Active, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Active")
+				return
+			}
+		case "sub":
+			z.Sub, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Sub")
+				return
+			}
+		case "description":
+			z.Description, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Description")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *ServiceUnit) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 5
+	// write "unit"
+	err = en.Append(0x85, 0xa4, 0x75, 0x6e, 0x69, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Unit)
+	if err != nil {
+		err = msgp.WrapError(err, "Unit")
+		return
+	}
+	// write "load"
+	err = en.Append(0xa4, 0x6c, 0x6f, 0x61, 0x64)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Load)
+	if err != nil {
+		err = msgp.WrapError(err, "Load")
+		return
+	}
+	// write "active"
+	err = en.Append(0xa6, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Active)
+	if err != nil {
+		err = msgp.WrapError(err, "Active")
+		return
+	}
+	// write "sub"
+	err = en.Append(0xa3, 0x73, 0x75, 0x62)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Sub)
+	if err != nil {
+		err = msgp.WrapError(err, "Sub")
+		return
+	}
+	// write "description"
+	err = en.Append(0xab, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Description)
+	if err != nil {
+		err = msgp.WrapError(err, "Description")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *ServiceUnit) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 5
+	// string "unit"
+	o = append(o, 0x85, 0xa4, 0x75, 0x6e, 0x69, 0x74)
+	o = msgp.AppendString(o, z.Unit)
+	// string "load"
+	o = append(o, 0xa4, 0x6c, 0x6f, 0x61, 0x64)
+	o = msgp.AppendString(o, z.Load)
+	// string "active"
+	o = append(o, 0xa6, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65)
+	o = msgp.AppendString(o, z.Active)
+	// string "sub"
+	o = append(o, 0xa3, 0x73, 0x75, 0x62)
+	o = msgp.AppendString(o, z.Sub)
+	// string "description"
+	o = append(o, 0xab, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e)
+	o = msgp.AppendString(o, z.Description)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ServiceUnit) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "unit":
+			z.Unit, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Unit")
+				return
+			}
+		case "load":
+			z.Load, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Load")
+				return
+			}
+		case "active":
+			z.Active, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Active")
+				return
+			}
+		case "sub":
+			z.Sub, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Sub")
+				return
+			}
+		case "description":
+			z.Description, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Description")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *ServiceUnit) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Unit) + 5 + msgp.StringPrefixSize + len(z.Load) + 7 + msgp.StringPrefixSize + len(z.Active) + 4 + msgp.StringPrefixSize + len(z.Sub) + 12 + msgp.StringPrefixSize + len(z.Description)
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *SetAttrRequest) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		case "mode":
+			z.Mode, err = dc.ReadUint32()
+			if err != nil {
+				err = msgp.WrapError(err, "Mode")
+				return
+			}
+		case "mtime":
+			z.Mtime, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "Mtime")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z SetAttrRequest) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 3
+	// write "path"
+	err = en.Append(0x83, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Path)
+	if err != nil {
+		err = msgp.WrapError(err, "Path")
+		return
+	}
+	// write "mode"
+	err = en.Append(0xa4, 0x6d, 0x6f, 0x64, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint32(z.Mode)
+	if err != nil {
+		err = msgp.WrapError(err, "Mode")
+		return
+	}
+	// write "mtime"
+	err = en.Append(0xa5, 0x6d, 0x74, 0x69, 0x6d, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.Mtime)
+	if err != nil {
+		err = msgp.WrapError(err, "Mtime")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z SetAttrRequest) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 3
+	// string "path"
+	o = append(o, 0x83, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	o = msgp.AppendString(o, z.Path)
+	// string "mode"
+	o = append(o, 0xa4, 0x6d, 0x6f, 0x64, 0x65)
+	o = msgp.AppendUint32(o, z.Mode)
+	// string "mtime"
+	o = append(o, 0xa5, 0x6d, 0x74, 0x69, 0x6d, 0x65)
+	o = msgp.AppendInt64(o, z.Mtime)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *SetAttrRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		case "mode":
+			z.Mode, bts, err = msgp.ReadUint32Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "IsWebSocket")
+				err = msgp.WrapError(err, "Mode")
+				return
+			}
+		case "mtime":
+			z.Mtime, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Mtime")
 				return
 			}
 		default:
@@ -1152,12 +7027,8 @@ func (z *ProxyHttpResponse) UnmarshalMsg(bts []byte) (o []byte, err error) {
 }
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
-func (z *ProxyHttpResponse) Msgsize() (s int) {
-	s = 1 + 17 + msgp.StringPrefixSize + len(z.ConnectionError) + 12 + msgp.Int32Size + 8 + msgp.ArrayHeaderSize
-	for za0001 := range z.Headers {
-		s += 1 + 5 + msgp.StringPrefixSize + len(z.Headers[za0001].Name) + 6 + msgp.StringPrefixSize + len(z.Headers[za0001].Value)
-	}
-	s += 13 + msgp.BoolSize
+func (z SetAttrRequest) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Path) + 5 + msgp.Uint32Size + 6 + msgp.Int64Size
 	return
 }
 
@@ -1418,3 +7289,303 @@ func (z *StartPtyRequest) Msgsize() (s int) {
 	s += 12 + msgp.BoolSize
 	return
 }
+
+// DecodeMsg implements msgp.Decodable
+func (z *SyncTreeRequest) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z SyncTreeRequest) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 1
+	// write "path"
+	err = en.Append(0x81, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Path)
+	if err != nil {
+		err = msgp.WrapError(err, "Path")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z SyncTreeRequest) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 1
+	// string "path"
+	o = append(o, 0x81, 0xa4, 0x70, 0x61, 0x74, 0x68)
+	o = msgp.AppendString(o, z.Path)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *SyncTreeRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "path":
+			z.Path, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Path")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z SyncTreeRequest) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Path)
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *SyncTreeResponse) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "entries":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Entries")
+				return
+			}
+			if cap(z.Entries) >= int(zb0002) {
+				z.Entries = (z.Entries)[:zb0002]
+			} else {
+				z.Entries = make([]FileInfo, zb0002)
+			}
+			for za0001 := range z.Entries {
+				err = z.Entries[za0001].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Entries", za0001)
+					return
+				}
+			}
+		case "not_exist":
+			z.NotExist, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "NotExist")
+				return
+			}
+		case "error":
+			z.Error, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *SyncTreeResponse) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 3
+	// write "entries"
+	err = en.Append(0x83, 0xa7, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Entries)))
+	if err != nil {
+		err = msgp.WrapError(err, "Entries")
+		return
+	}
+	for za0001 := range z.Entries {
+		err = z.Entries[za0001].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Entries", za0001)
+			return
+		}
+	}
+	// write "not_exist"
+	err = en.Append(0xa9, 0x6e, 0x6f, 0x74, 0x5f, 0x65, 0x78, 0x69, 0x73, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.NotExist)
+	if err != nil {
+		err = msgp.WrapError(err, "NotExist")
+		return
+	}
+	// write "error"
+	err = en.Append(0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Error)
+	if err != nil {
+		err = msgp.WrapError(err, "Error")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *SyncTreeResponse) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 3
+	// string "entries"
+	o = append(o, 0x83, 0xa7, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Entries)))
+	for za0001 := range z.Entries {
+		o, err = z.Entries[za0001].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Entries", za0001)
+			return
+		}
+	}
+	// string "not_exist"
+	o = append(o, 0xa9, 0x6e, 0x6f, 0x74, 0x5f, 0x65, 0x78, 0x69, 0x73, 0x74)
+	o = msgp.AppendBool(o, z.NotExist)
+	// string "error"
+	o = append(o, 0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+	o = msgp.AppendString(o, z.Error)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *SyncTreeResponse) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "entries":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Entries")
+				return
+			}
+			if cap(z.Entries) >= int(zb0002) {
+				z.Entries = (z.Entries)[:zb0002]
+			} else {
+				z.Entries = make([]FileInfo, zb0002)
+			}
+			for za0001 := range z.Entries {
+				bts, err = z.Entries[za0001].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Entries", za0001)
+					return
+				}
+			}
+		case "not_exist":
+			z.NotExist, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "NotExist")
+				return
+			}
+		case "error":
+			z.Error, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *SyncTreeResponse) Msgsize() (s int) {
+	s = 1 + 8 + msgp.ArrayHeaderSize
+	for za0001 := range z.Entries {
+		s += z.Entries[za0001].Msgsize()
+	}
+	s += 10 + msgp.BoolSize + 6 + msgp.StringPrefixSize + len(z.Error)
+	return
+}
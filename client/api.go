@@ -0,0 +1,81 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// apiHeaders returns headers for server API, with the API key if configured
+func apiHeaders() http.Header {
+	headers := http.Header{}
+	if biz.Config.APIKey != "" {
+		headers.Set("X-API-Key", biz.Config.APIKey)
+	}
+	return headers
+}
+
+var tlsConfigOnce = sync.OnceValue(func() *tls.Config {
+	config := &tls.Config{InsecureSkipVerify: biz.Config.Insecure}
+	if biz.Config.CACert != "" {
+		pem, err := os.ReadFile(biz.Config.CACert)
+		if err != nil {
+			log.Fatalf("Failed to read CA cert: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			log.Fatalf("No certificate found in %s", biz.Config.CACert)
+		}
+		config.RootCAs = pool
+	}
+	return config
+})
+
+// tlsConfig returns TLS config for server connections, with CA cert from client profile if any
+func tlsConfig() *tls.Config {
+	return tlsConfigOnce()
+}
+
+// httpClient returns a client for server API
+func httpClient() *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig(),
+	}}
+}
+
+// omniURL returns the WebSocket URL of omni session to the agent
+func omniURL() string {
+	wsURL := biz.Config.BaseUrl
+	wsURL = strings.Replace(wsURL, "http://", "ws://", 1)
+	wsURL = strings.Replace(wsURL, "https://", "wss://", 1)
+	return wsURL + "/api/agent/" + biz.Config.Name + "/omni/"
+}
+
+// dialOmni opens an omni session to the agent.
+// The response is returned on handshake failure, to tell auth errors apart.
+func dialOmni() (*utils.RWChan, *http.Response, error) {
+	dialer := websocket.Dialer{
+		TLSClientConfig: tlsConfig(),
+	}
+	conn, resp, err := dialer.Dial(omniURL(), apiHeaders())
+	if err != nil {
+		if resp != nil {
+			err = fmt.Errorf("%w (%s)", err, resp.Status)
+		}
+		return nil, resp, err
+	}
+	return utils.MakeRWChanFromWebSocket(conn), resp, nil
+}
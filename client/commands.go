@@ -0,0 +1,295 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// runCommand runs a one-shot subcommand (list, exec, shell, cp, sync, profiles) and returns the process exit code
+func runCommand(args []string) int {
+	var err error
+	code := 0
+	switch args[0] {
+	case "list":
+		err = cmdList(args[1:])
+	case "exec":
+		code, err = cmdExec(args[1:])
+	case "shell":
+		code, err = cmdShell(args[1:])
+	case "cp":
+		err = cmdCp(args[1:])
+	case "sync":
+		err = cmdSync(args[1:])
+	case "profiles":
+		err = cmdProfiles()
+	default:
+		err = fmt.Errorf("unknown command %q, expected list, exec, shell, cp, sync, forward or profiles", args[0])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	return code
+}
+
+// apiRequest sends a request to server API, and fails on non-2xx status
+func apiRequest(method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, biz.Config.BaseUrl+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = apiHeaders()
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", resp.Status, msg)
+	}
+	return resp, nil
+}
+
+type agentInstance struct {
+	Id         uint64    `json:"id"`
+	Name       string    `json:"name"`
+	UserAgent  string    `json:"user_agent"`
+	JoinAt     time.Time `json:"join_at"`
+	RemoteAddr string    `json:"remote_addr"`
+}
+
+// cmdList prints online agent instances. with -n, only instances of that agent.
+// with -a, all known agents in server registry, including offline ones
+func cmdList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	all := fs.Bool("a", false, "list all known agents, including offline ones")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *all {
+		return listRegistry()
+	}
+
+	path := "/api/agent/"
+	if biz.Config.Name != "" {
+		path += url.PathEscape(biz.Config.Name) + "/"
+	}
+	resp, err := apiRequest("GET", path, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	instances := []agentInstance{}
+	if err := json.NewDecoder(resp.Body).Decode(&instances); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tID\tREMOTE ADDR\tJOINED\tUSER AGENT")
+	for _, it := range instances {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n", it.Name, it.Id, it.RemoteAddr, it.JoinAt.Local().Format(time.DateTime), it.UserAgent)
+	}
+	return tw.Flush()
+}
+
+type registryEntry struct {
+	Name       string            `json:"name"`
+	LastSeen   time.Time         `json:"last_seen"`
+	RemoteAddr string            `json:"remote_addr"`
+	Version    string            `json:"version"`
+	Labels     map[string]string `json:"labels"`
+	Online     bool              `json:"online"`
+	Instances  int               `json:"instances"`
+}
+
+func listRegistry() error {
+	resp, err := apiRequest("GET", "/api/registry/", "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	entries := []registryEntry{}
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATE\tLAST SEEN\tREMOTE ADDR\tVERSION\tLABELS")
+	for _, it := range entries {
+		if biz.Config.Name != "" && it.Name != biz.Config.Name {
+			continue
+		}
+		state := "offline"
+		if it.Online {
+			state = fmt.Sprintf("online (%d)", it.Instances)
+		}
+		labels := make([]string, 0, len(it.Labels))
+		for k, v := range it.Labels {
+			labels = append(labels, k+"="+v)
+		}
+		sort.Strings(labels)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", it.Name, state, it.LastSeen.Local().Format(time.DateTime), it.RemoteAddr, it.Version, strings.Join(labels, ","))
+	}
+	return tw.Flush()
+}
+
+// cmdProfiles prints profiles in client config, and their forward sets. the profile in use is marked with *
+func cmdProfiles() error {
+	cfg := biz.ClientProfiles
+	if cfg == nil || len(cfg.Profiles) == 0 {
+		return errors.New("no profile found in client config")
+	}
+
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tPROFILE\tSERVER\tAGENT\tFORWARDS")
+	for _, name := range names {
+		p := cfg.Profiles[name]
+		mark := ""
+		if name == biz.Config.Profile {
+			mark = "*"
+		}
+		sets := make([]string, 0, len(p.Forwards))
+		for set := range p.Forwards {
+			sets = append(sets, set)
+		}
+		sort.Strings(sets)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", mark, name, p.BaseUrl, p.Agent, strings.Join(sets, ", "))
+	}
+	return tw.Flush()
+}
+
+// cmdExec runs a shell command on agent via exec API.
+// stdin is sent to the command, unless it is a terminal.
+func cmdExec(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, errors.New("usage: exec 'command'")
+	}
+
+	body, form := io.Pipe()
+	mw := multipart.NewWriter(form)
+	go func() {
+		mw.WriteField("cmd", args[0])
+		mw.WriteField("full", "1")
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			part, err := mw.CreateFormFile("stdin", "stdin")
+			if err != nil {
+				form.CloseWithError(err)
+				return
+			}
+			if _, err := io.Copy(part, os.Stdin); err != nil {
+				form.CloseWithError(err)
+				return
+			}
+		}
+		form.CloseWithError(mw.Close())
+	}()
+
+	resp, err := apiRequest("POST", "/api/agent/"+url.PathEscape(biz.Config.Name)+"/exec/", mw.FormDataContentType(), body)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return readExecFrames(resp.Body, os.Stdout, os.Stderr)
+}
+
+// readExecFrames demuxes the full=1 response of exec API, and returns the exit code.
+// each frame is [len:u32le][type][data], type is 0x00 exit code, 0x01 stdout, 0x02 stderr, 0x03 debug.
+func readExecFrames(r io.Reader, stdout, stderr io.Writer) (int, error) {
+	header := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF {
+				err = errors.New("connection closed before command exited")
+			}
+			return 0, err
+		}
+		data := make([]byte, binary.LittleEndian.Uint32(header))
+		if _, err := io.ReadFull(r, data); err != nil {
+			return 0, err
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		switch data[0] {
+		case 0x00:
+			if len(data) < 5 {
+				return 0, errors.New("bad exit code frame")
+			}
+			return int(int32(binary.LittleEndian.Uint32(data[1:]))), nil
+		case 0x01:
+			stdout.Write(data[1:])
+		case 0x02:
+			stderr.Write(data[1:])
+		case 0x03:
+			fmt.Fprintf(stderr, "[agent] %s\n", data[1:])
+		}
+	}
+}
+
+// awaitFrame reads omni session until a frame of given type. debug message (0xff) from agent is returned as error
+func awaitFrame(ws *utils.RWChan, want byte) ([]byte, error) {
+	for data := range ws.Read {
+		if len(data) == 0 {
+			continue
+		}
+		if data[0] == want {
+			return data, nil
+		}
+		if data[0] == 0xff {
+			return nil, errors.New(string(data[1:]))
+		}
+	}
+	return nil, errors.New("connection closed")
+}
+
+// agentFeatures says hello to agent, and returns its features. an old agent replies nothing but the ping
+func agentFeatures(ws *utils.RWChan) (map[string]bool, error) {
+	ws.Write([]byte{0xfe})
+	ws.Write(helloPing)
+	features := map[string]bool{}
+	for data := range ws.Read {
+		if len(data) == 0 {
+			continue
+		}
+		if data[0] == 0xfe {
+			for _, f := range strings.Split(string(data[1:]), ",") {
+				features[f] = true
+			}
+		}
+		if bytes.Equal(data, helloPing) {
+			return features, nil
+		}
+	}
+	return nil, errors.New("connection closed")
+}
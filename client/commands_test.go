@@ -0,0 +1,58 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func execFrame(data ...byte) []byte {
+	return append(binary.LittleEndian.AppendUint32(nil, uint32(len(data))), data...)
+}
+
+func TestReadExecFrames(t *testing.T) {
+	stream := bytes.Join([][]byte{
+		execFrame(0x01, 'o', 'u', 't'),
+		execFrame(0x02, 'e', 'r', 'r'),
+		execFrame(0x01, '!'),
+		execFrame(0x00, 0xfe, 0xff, 0xff, 0xff), // -2
+		execFrame(0x01, 'x'),                    // not read after exit code
+	}, nil)
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	code, err := readExecFrames(bytes.NewReader(stream), stdout, stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != -2 {
+		t.Errorf("exit code = %d, want -2", code)
+	}
+	if stdout.String() != "out!" || stderr.String() != "err" {
+		t.Errorf("stdout = %q, stderr = %q", stdout, stderr)
+	}
+
+	// stream ends without exit code
+	_, err = readExecFrames(bytes.NewReader(execFrame(0x01, 'a')), stdout, stderr)
+	if err == nil {
+		t.Error("expected error when exit code is missing")
+	}
+}
+
+func TestSplitRemote(t *testing.T) {
+	cases := []struct {
+		arg    string
+		path   string
+		remote bool
+	}{
+		{":/etc/hosts", "/etc/hosts", true},
+		{":", "", true},
+		{"./a.txt", "./a.txt", false},
+		{`C:\a.txt`, `C:\a.txt`, false},
+	}
+	for _, c := range cases {
+		path, remote := splitRemote(c.arg)
+		if path != c.path || remote != c.remote {
+			t.Errorf("splitRemote(%q) = %q, %v", c.arg, path, remote)
+		}
+	}
+}
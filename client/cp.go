@@ -0,0 +1,307 @@
+package client
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	cpChunkSize   = 256 * 1024
+	cpMaxInflight = 4 // concurrent reads when downloading. uploads are sequential, for 0x10 extends file with Truncate
+)
+
+// splitRemote tells whether a cp argument is remote, which is marked by a leading ":"
+func splitRemote(arg string) (string, bool) {
+	if strings.HasPrefix(arg, ":") {
+		return arg[1:], true
+	}
+	return arg, false
+}
+
+// cmdCp copies one file between local and agent. remote path is prefixed with ":"
+func cmdCp(args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: cp SRC DST, remote path is prefixed with \":\"")
+	}
+	src, srcRemote := splitRemote(args[0])
+	dst, dstRemote := splitRemote(args[1])
+	if srcRemote == dstRemote {
+		return errors.New("exactly one of SRC and DST must be remote (prefixed with \":\")")
+	}
+
+	ws, _, err := dialOmni()
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	if dstRemote {
+		return upload(ws, src, dst)
+	}
+	return download(ws, src, dst)
+}
+
+func remoteStat(ws *utils.RWChan, p string) (*biz.FileInfo, error) {
+	ws.Write(utils.PrependBytes([]byte{0x11}, []byte(p)))
+	data, err := awaitFrame(ws, 0x11)
+	if err != nil {
+		return nil, err
+	}
+	info := &biz.FileInfo{}
+	if _, err := info.UnmarshalMsg(data[1:]); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func upload(ws *utils.RWChan, src, dst string) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if stat.IsDir() {
+		return fmt.Errorf("%s is a directory", src)
+	}
+
+	// copy into remote directory, if it is
+	if dst == "" || strings.HasSuffix(dst, "/") {
+		dst += filepath.Base(src)
+	} else if info, err := remoteStat(ws, dst); err == nil && fs.FileMode(info.Mode).IsDir() {
+		dst = path.Join(dst, filepath.Base(src))
+	}
+
+	p := newProgress(dst, stat.Size())
+	defer p.finish()
+
+	if err := writeRemoteRanges(ws, dst, file, blockRanges(stat.Size(), cpChunkSize, nil), p.add); err != nil {
+		return err
+	}
+	// truncate to the size. an empty file is created by the truncate as well
+	return writeRemoteChunk(ws, dst, stat.Size(), nil)
+}
+
+// byteRange is a part of file to transfer
+type byteRange struct {
+	offset int64
+	length int64
+}
+
+// blockRanges splits a file into blocks, and returns ranges to transfer.
+// blocks that same(i) reports true are skipped. if same is nil, the whole file is returned.
+// adjacent blocks are merged, up to cpChunkSize per range
+func blockRanges(size, blockSize int64, same func(i int) bool) []byteRange {
+	ranges := []byteRange{}
+	for i := 0; int64(i)*blockSize < size; i++ {
+		if same != nil && same(i) {
+			continue
+		}
+		offset := int64(i) * blockSize
+		length := min(blockSize, size-offset)
+		if n := len(ranges); n > 0 {
+			last := &ranges[n-1]
+			if last.offset+last.length == offset && last.length+length <= max(cpChunkSize, blockSize) {
+				last.length += length
+				continue
+			}
+		}
+		ranges = append(ranges, byteRange{offset, length})
+	}
+	return ranges
+}
+
+// writeRemoteRanges copies ranges of a local file to remote, one by one
+func writeRemoteRanges(ws *utils.RWChan, p string, file io.ReaderAt, ranges []byteRange, onWritten func(n int64)) error {
+	buf := make([]byte, cpChunkSize)
+	for _, r := range ranges {
+		if int64(len(buf)) < r.length {
+			buf = make([]byte, r.length)
+		}
+		n, err := file.ReadAt(buf[:r.length], r.offset)
+		if int64(n) < r.length {
+			if err == nil || err == io.EOF {
+				err = errors.New("file changed during transfer")
+			}
+			return err
+		}
+		if err := writeRemoteChunk(ws, p, r.offset, buf[:r.length]); err != nil {
+			return err
+		}
+		if onWritten != nil {
+			onWritten(r.length)
+		}
+	}
+	return nil
+}
+
+// writeRemoteChunk sends a 0x10 chunk and waits for its ack. empty data truncates the file to offset
+func writeRemoteChunk(ws *utils.RWChan, p string, offset int64, data []byte) error {
+	ws.Write(utils.JoinBytes2(
+		0x10,
+		binary.LittleEndian.AppendUint64(nil, uint64(offset)),
+		binary.LittleEndian.AppendUint64(nil, uint64(len(data))),
+		[]byte(p),
+		data,
+	))
+	for {
+		ack, err := awaitFrame(ws, 0x10)
+		if err != nil {
+			return err
+		}
+		if len(ack) >= 9 && int64(binary.LittleEndian.Uint64(ack[1:])) == offset && string(ack[9:]) == p {
+			return nil
+		}
+	}
+}
+
+func download(ws *utils.RWChan, src, dst string) error {
+	info, err := remoteStat(ws, src)
+	if err != nil {
+		return err
+	}
+	mode := fs.FileMode(info.Mode)
+	if mode.IsDir() {
+		return fmt.Errorf("%s is a directory", src)
+	}
+
+	// copy into local directory, if it is
+	if stat, err := os.Stat(dst); err == nil && stat.IsDir() {
+		dst = filepath.Join(dst, path.Base(src))
+	}
+	file, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	p := newProgress(dst, info.Size)
+	defer p.finish()
+
+	err = readRemoteRanges(ws, src, blockRanges(info.Size, cpChunkSize, nil), func(offset int64, data []byte) error {
+		_, err := file.WriteAt(data, offset)
+		p.add(int64(len(data)))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// readRemoteRanges reads ranges of a remote file, and calls write with the data.
+// reads are pipelined. replies may come out of order, so write shall handle the offset
+func readRemoteRanges(ws *utils.RWChan, p string, ranges []byteRange, write func(offset int64, data []byte) error) error {
+	pending := map[int64]int64{} // offset -> requested length
+	next := 0
+	for next < len(ranges) || len(pending) > 0 {
+		for len(pending) < cpMaxInflight && next < len(ranges) {
+			r := ranges[next]
+			ws.Write(utils.JoinBytes2(
+				0x12,
+				binary.LittleEndian.AppendUint64(nil, uint64(r.offset)),
+				binary.LittleEndian.AppendUint64(nil, uint64(r.length)),
+				[]byte(p),
+			))
+			pending[r.offset] = r.length
+			next++
+		}
+
+		data, err := awaitFrame(ws, 0x12)
+		if err != nil {
+			return err
+		}
+		if len(data) < 17 {
+			continue
+		}
+		offset := int64(binary.LittleEndian.Uint64(data[1:]))
+		length := int64(binary.LittleEndian.Uint64(data[9:]))
+		requested, ok := pending[offset]
+		if !ok || int64(len(data))-17 < length || string(data[17:int64(len(data))-length]) != p {
+			continue
+		}
+		if length != requested {
+			return fmt.Errorf("%s changed during transfer", p)
+		}
+		delete(pending, offset)
+
+		if err := write(offset, data[int64(len(data))-length:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// progress prints transfer progress to stderr, if it is a terminal
+type progress struct {
+	name      string
+	total     int64
+	done      int64
+	started   time.Time
+	lastPrint time.Time
+	enabled   bool
+}
+
+func newProgress(name string, total int64) *progress {
+	return &progress{
+		name:    name,
+		total:   total,
+		started: time.Now(),
+		enabled: term.IsTerminal(int(os.Stderr.Fd())),
+	}
+}
+
+func (p *progress) add(n int64) {
+	p.done += n
+	if time.Since(p.lastPrint) >= 200*time.Millisecond {
+		p.print()
+	}
+}
+
+func (p *progress) print() {
+	if !p.enabled {
+		return
+	}
+	p.lastPrint = time.Now()
+	percent := 100.0
+	if p.total > 0 {
+		percent = float64(p.done) * 100 / float64(p.total)
+	}
+	speed := float64(p.done) / max(time.Since(p.started).Seconds(), 0.001)
+	fmt.Fprintf(os.Stderr, "\r%s  %s / %s  %5.1f%%  %s/s  ", p.name, formatBytes(p.done), formatBytes(p.total), percent, formatBytes(int64(speed)))
+}
+
+func (p *progress) finish() {
+	if p.enabled {
+		p.print()
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -1,118 +1,323 @@
 package client
 
 import (
-	"crypto/tls"
+	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"net"
 	"net/http"
+	"os"
 	"remote-agent/biz"
 	"remote-agent/utils"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
-	"github.com/gorilla/websocket"
+	"github.com/avast/retry-go"
 )
 
+// endpoint is a host:port, or a unix socket path
+type endpoint struct {
+	network string // "tcp", "udp" or "unix"
+	address string
+}
+
+// portForward is a forward spec. For -L, -U and -D, listen is local and target is dialed by agent.
+// For -R, listen is on agent side and target is dialed locally.
 type portForward struct {
-	localPort  int
-	remoteAddr string
-	remotePort int
+	listen endpoint
+	target endpoint
 }
 
-func parsePortForward(s string) (portForward, error) {
-	// Accepts: localPort:remoteAddr:remotePort  or  localPort:remotePort (→ localhost)
-	parts := strings.SplitN(s, ":", 3)
-	switch len(parts) {
-	case 2:
-		lp, err := strconv.Atoi(parts[0])
-		if err != nil {
-			return portForward{}, fmt.Errorf("invalid local port %q", parts[0])
-		}
-		rp, err := strconv.Atoi(parts[1])
-		if err != nil {
-			return portForward{}, fmt.Errorf("invalid remote port %q", parts[1])
+// parsePortForward parses a forward spec, like ssh:
+//
+//	[bind:]port:host:hostport
+//	[bind:]port:/path/to/socket
+//	/path/to/socket:host:hostport
+//	port:hostport (host is localhost)
+//
+// network is "tcp" or "udp". An IPv6 address is in brackets. A part containing "/" is a unix socket path.
+// Listeners bind 127.0.0.1 unless told; "*" binds all interfaces.
+func parsePortForward(s, network string) (portForward, error) {
+	parts := splitSpec(s)
+	if len(parts) < 2 {
+		return portForward{}, fmt.Errorf("expected [bind:]port:host:hostport, got %q", s)
+	}
+
+	var pf portForward
+	var err error
+	rest := parts
+	last := parts[len(parts)-1]
+	switch {
+	case isSocketPath(last):
+		pf.target = endpoint{network: "unix", address: last}
+		rest = parts[:len(parts)-1]
+	case len(parts) == 2:
+		pf.target, err = hostPort(network, "localhost", last)
+		rest = parts[:1]
+	default:
+		pf.target, err = hostPort(network, parts[len(parts)-2], last)
+		rest = parts[:len(parts)-2]
+	}
+	if err != nil {
+		return portForward{}, err
+	}
+
+	if pf.listen, err = parseListen(rest, network); err != nil {
+		return portForward{}, err
+	}
+	if network == "udp" && (pf.listen.network == "unix" || pf.target.network == "unix") {
+		return portForward{}, errors.New("unix sockets are not supported for udp")
+	}
+	return pf, nil
+}
+
+// parseListen parses the listen part of a spec: [bind:]port, or a unix socket path
+func parseListen(parts []string, network string) (endpoint, error) {
+	switch {
+	case len(parts) == 1 && isSocketPath(parts[0]):
+		return endpoint{network: "unix", address: parts[0]}, nil
+	case len(parts) == 1:
+		return hostPort(network, "127.0.0.1", parts[0])
+	case len(parts) == 2:
+		bind := parts[0]
+		if bind == "*" {
+			bind = ""
 		}
-		return portForward{localPort: lp, remoteAddr: "localhost", remotePort: rp}, nil
-	case 3:
-		lp, err := strconv.Atoi(parts[0])
-		if err != nil {
-			return portForward{}, fmt.Errorf("invalid local port %q", parts[0])
+		return hostPort(network, bind, parts[1])
+	default:
+		return endpoint{}, fmt.Errorf("expected [bind:]port, got %q", strings.Join(parts, ":"))
+	}
+}
+
+// splitSpec splits a spec by ":", except those in brackets of IPv6 addresses
+func splitSpec(s string) []string {
+	parts := []string{}
+	inBrackets := false
+	start := 0
+	for i, ch := range s {
+		switch ch {
+		case '[':
+			inBrackets = true
+		case ']':
+			inBrackets = false
+		case ':':
+			if !inBrackets {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
 		}
-		rp, err := strconv.Atoi(parts[2])
-		if err != nil {
-			return portForward{}, fmt.Errorf("invalid remote port %q", parts[2])
+	}
+	return append(parts, s[start:])
+}
+
+func isSocketPath(s string) bool {
+	return strings.Contains(s, "/")
+}
+
+func hostPort(network, host, port string) (endpoint, error) {
+	p, err := strconv.Atoi(port)
+	if err != nil || p <= 0 || p > 65535 {
+		return endpoint{}, fmt.Errorf("invalid port %q", port)
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return endpoint{network: network, address: net.JoinHostPort(host, port)}, nil
+}
+
+// listenLocalStream listens on a local tcp or unix endpoint.
+// A stale unix socket, left by a previous run and not listened by anyone, is removed first.
+func listenLocalStream(ep endpoint) (net.Listener, error) {
+	if ep.network == "unix" {
+		if conn, err := net.Dial("unix", ep.address); err == nil {
+			conn.Close()
+		} else if info, err := os.Lstat(ep.address); err == nil && info.Mode()&fs.ModeSocket != 0 {
+			os.Remove(ep.address)
 		}
-		return portForward{localPort: lp, remoteAddr: parts[1], remotePort: rp}, nil
-	default:
-		return portForward{}, fmt.Errorf("expected localPort:remoteAddr:remotePort, got %q", s)
 	}
+	return net.Listen(ep.network, ep.address)
 }
 
-// localConn tracks one forwarded TCP connection.
+// localConn tracks one forwarded TCP connection, or one UDP peer.
 type localConn struct {
-	conn    net.Conn
+	conn    io.WriteCloser
+	id      uint32
+	idBytes []byte
 	ready   chan struct{} // closed when dial result received from agent
-	dialErr string       // non-empty if dial failed
+	dialErr string        // non-empty if dial failed
+	toLocal *utils.ByteQueue
+	udp     bool // datagrams beyond the queue limit are dropped, instead of closing the channel
+
+	// flow control. nil if agent doesn't support it
+	sendWindow *utils.SendWindow
+	recvWindow *utils.RecvWindow
 }
 
 type clientState struct {
-	ws      *utils.RWChan
-	counter atomic.Uint32
-	conns   sync.Map // map[uint32]*localConn
+	ws       *utils.RWChan
+	counter  atomic.Uint32
+	conns    sync.Map // map[uint32]*localConn
+	reverses sync.Map // map[uint32]*reverseForward, key is listener id
+
+	hello     chan struct{} // closed when agent features are known
+	helloOnce sync.Once
+	features  map[string]bool
 }
 
+var helloPing = []byte{0xff, 'h', 'i'}
+
 func Run() {
 	cfg := biz.Config
+	if len(cfg.ClientArgs) > 0 {
+		os.Exit(runCommand(cfg.ClientArgs))
+	}
 
 	// Parse port-forward specs
 	pfs := make([]portForward, 0, len(cfg.ClientForwards))
 	for _, spec := range cfg.ClientForwards {
-		pf, err := parsePortForward(spec)
+		pf, err := parsePortForward(spec, "tcp")
 		if err != nil {
 			log.Fatalf("invalid -L %q: %v", spec, err)
 		}
 		pfs = append(pfs, pf)
 	}
+	udpPfs := make([]portForward, 0, len(cfg.ClientUdp))
+	for _, spec := range cfg.ClientUdp {
+		pf, err := parsePortForward(spec, "udp")
+		if err != nil {
+			log.Fatalf("invalid -U %q: %v", spec, err)
+		}
+		udpPfs = append(udpPfs, pf)
+	}
+	socksListens := make([]endpoint, 0, len(cfg.ClientSocks))
+	for _, spec := range cfg.ClientSocks {
+		ep, err := parseListen(splitSpec(spec), "tcp")
+		if err != nil {
+			log.Fatalf("invalid -D %q: %v", spec, err)
+		}
+		socksListens = append(socksListens, ep)
+	}
+	socksUser, socksPassword, _ := strings.Cut(cfg.SocksAuth, ":")
+	reversePfs := make([]portForward, 0, len(cfg.ClientReverse))
+	for _, spec := range cfg.ClientReverse {
+		// [bind:]remotePort:localAddr:localPort, parsed in the same format as -L
+		pf, err := parsePortForward(spec, "tcp")
+		if err != nil {
+			log.Fatalf("invalid -R %q: %v", spec, err)
+		}
+		reversePfs = append(reversePfs, pf)
+	}
 
-	// Build WebSocket URL from server base URL
-	wsURL := cfg.BaseUrl
-	wsURL = strings.Replace(wsURL, "http://", "ws://", 1)
-	wsURL = strings.Replace(wsURL, "https://", "wss://", 1)
-	wsURL += "/api/agent/" + cfg.Name + "/omni/"
+	f := &forwarder{reversePfs: reversePfs}
+	f.cond = sync.NewCond(&f.mu)
 
-	headers := http.Header{}
-	if cfg.APIKey != "" {
-		headers.Set("X-API-Key", cfg.APIKey)
+	// Start a local TCP listener for each -L spec, a UDP socket for each -U spec, and a SOCKS5 server for each -D.
+	// They stay open across reconnections; new connections wait for the session.
+	for _, pf := range pfs {
+		go f.listenLocal(pf)
+	}
+	for _, pf := range udpPfs {
+		go f.listenLocalUdp(pf)
+	}
+	for _, ep := range socksListens {
+		go f.listenSocks(ep, socksUser, socksPassword)
 	}
 
-	dialer := websocket.Dialer{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: cfg.Insecure},
+	// Connect, and reconnect with backoff when the connection drops.
+	for {
+		retry.Do(f.runSession,
+			retry.Delay(time.Second),
+			retry.MaxDelay(time.Second*60),
+			retry.LastErrorOnly(true),
+			retry.OnRetry(func(n uint, err error) {
+				log.Printf("connection failed (attempt %d): %v. reconnecting...", n+1, err)
+			}),
+		)
 	}
-	conn, _, err := dialer.Dial(wsURL, headers)
-	if err != nil {
-		log.Fatalf("failed to connect to %s: %v", wsURL, err)
+}
+
+// forwarder owns the local listeners, and the current session to agent.
+type forwarder struct {
+	reversePfs []portForward // -R specs, requested again on each session
+
+	mu        sync.Mutex
+	cond      *sync.Cond
+	session   *clientState // nil while disconnected
+	connected bool         // connected at least once
+}
+
+// waitSession returns the current session, waiting while disconnected.
+func (f *forwarder) waitSession() *clientState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for f.session == nil {
+		f.cond.Wait()
 	}
-	log.Printf("connected to %s (agent: %s)", cfg.BaseUrl, cfg.Name)
+	return f.session
+}
+
+func (f *forwarder) setSession(c *clientState) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.session = c
+	f.cond.Broadcast()
+}
+
+// a session shorter than this is counted as a failed attempt, so the backoff keeps growing
+const minHealthySession = 30 * time.Second
 
-	c := &clientState{ws: utils.MakeRWChanFromWebSocket(conn)}
+// runSession connects to agent, and serves until the connection drops.
+// It returns nil if the session was healthy, so the backoff starts over.
+func (f *forwarder) runSession() error {
+	cfg := biz.Config
+	ws, resp, err := dialOmni()
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			log.Fatalf("failed to connect to %s: %v (check the API key)", omniURL(), err)
+		}
+		return err
+	}
 
+	c := &clientState{ws: ws, hello: make(chan struct{}), features: map[string]bool{}}
 	go c.demux()
 
-	// Start a local TCP listener for each -L spec.
-	// Block until all listeners exit (i.e. until the WS connection drops).
-	wg := sync.WaitGroup{}
-	for _, pf := range pfs {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			c.listenLocal(pf)
-		}()
+	// say hello and send a ping.
+	// agent replies hello before pong, if it supports. otherwise, it's an old agent without any feature.
+	c.ws.Write([]byte{0xfe})
+	c.ws.Write(helloPing)
+	<-c.hello
+	if c.ws.Ctx.Err() != nil {
+		return errors.New("connection closed before agent replied")
 	}
-	wg.Wait()
+
+	if f.connected {
+		log.Printf("reconnected to %s (agent: %s)", cfg.BaseUrl, cfg.Name)
+	} else {
+		log.Printf("connected to %s (agent: %s)", cfg.BaseUrl, cfg.Name)
+		f.connected = true
+	}
+	started := time.Now()
+	f.setSession(c)
+
+	// ask agent to listen for each -R spec. agent closes them when the session ends
+	for _, pf := range f.reversePfs {
+		go c.listenRemote(pf)
+	}
+
+	<-c.ws.Ctx.Done()
+	f.setSession(nil)
+	log.Printf("disconnected from %s, open connections closed. local listeners are kept", cfg.BaseUrl)
+
+	if time.Since(started) < minHealthySession {
+		return errors.New("connection lost")
+	}
+	return nil
 }
 
 // demux reads from the agent WebSocket and dispatches to the right localConn.
@@ -122,7 +327,19 @@ func (c *clientState) demux() {
 			continue
 		}
 		switch data[0] {
+		case 0xfe: // hello: [0xfe][features, comma separated]
+			c.helloOnce.Do(func() {
+				for _, f := range strings.Split(string(data[1:]), ",") {
+					c.features[f] = true
+				}
+				close(c.hello)
+			})
+
 		case 0xff: // debug message from agent
+			if bytes.Equal(data, helloPing) {
+				c.helloOnce.Do(func() { close(c.hello) })
+				continue
+			}
 			log.Printf("[agent] %s", string(data[1:]))
 
 		case 0x20: // dial result: [0x20][id:4][errCode:1][message]
@@ -145,9 +362,19 @@ func (c *clientState) demux() {
 				continue
 			}
 			id := binary.LittleEndian.Uint32(data[1:5])
-			payload := data[5:]
 			if v, ok := c.conns.Load(id); ok {
-				v.(*localConn).conn.Write(payload)
+				lc := v.(*localConn)
+				if lc.recvWindow == nil && !lc.udp {
+					// an agent without flow control is slowed down by waiting, which stalls other connections too
+					lc.toLocal.PushWait(context.Background(), data[5:])
+					continue
+				}
+				// never block other connections. an agent overrunning the window is cut off
+				if err := lc.toLocal.Push(data[5:]); err == utils.ErrQueueFull && !lc.udp {
+					log.Printf("connection 0x%x closed: %s", id, err.Error())
+					lc.toLocal.Close()
+					c.ws.Write(utils.JoinBytes2(0x22, lc.idBytes))
+				}
 			}
 
 		case 0x22: // close: [0x22][id:4]
@@ -156,70 +383,181 @@ func (c *clientState) demux() {
 			}
 			id := binary.LittleEndian.Uint32(data[1:5])
 			if v, ok := c.conns.LoadAndDelete(id); ok {
-				v.(*localConn).conn.Close()
+				v.(*localConn).toLocal.Close() // local conn is closed after pending data written
+			}
+
+		case 0x28: // listen result: [0x28][id:4][errCode:1][message]
+			if len(data) < 6 {
+				continue
+			}
+			id := binary.LittleEndian.Uint32(data[1:5])
+			if v, ok := c.reverses.Load(id); ok {
+				msg := ""
+				if data[5] != 0x00 {
+					msg = string(data[6:])
+				}
+				v.(*reverseForward).result <- msg
+			}
+
+		case 0x29: // accepted by agent: [0x29][listenerId:4][id:4][remoteAddr]
+			if len(data) < 9 {
+				continue
+			}
+			id := binary.LittleEndian.Uint32(data[1:5])
+			if v, ok := c.reverses.Load(id); ok {
+				c.onAccepted(v.(*reverseForward), bytes.Clone(data[5:9]), string(data[9:]))
+			} else {
+				c.ws.Write(utils.JoinBytes2(0x22, data[5:9]))
+			}
+
+		case 0x2a: // listener closed by agent: [0x2a][id:4][message]
+			if len(data) < 5 {
+				continue
+			}
+			id := binary.LittleEndian.Uint32(data[1:5])
+			if v, ok := c.reverses.Load(id); ok {
+				log.Printf("agent closed listener %s: %s", v.(*reverseForward).listen, string(data[5:]))
+			}
+
+		case 0x25: // window update: [0x25][id:4][bytes:4]
+			if len(data) < 9 {
+				continue
+			}
+			id := binary.LittleEndian.Uint32(data[1:5])
+			if v, ok := c.conns.Load(id); ok {
+				v.(*localConn).sendWindow.Grant(binary.LittleEndian.Uint32(data[5:9]))
 			}
 		}
 	}
 
 	// WS closed — close all open local connections
-	log.Println("agent connection closed, shutting down")
+	c.helloOnce.Do(func() { close(c.hello) })
 	c.conns.Range(func(_, v any) bool {
 		v.(*localConn).conn.Close()
 		return true
 	})
 }
 
-func (c *clientState) listenLocal(pf portForward) {
-	addr := fmt.Sprintf("127.0.0.1:%d", pf.localPort)
-	ln, err := net.Listen("tcp", addr)
+// writeLocal writes data from agent to the local connection, and grants window back to agent.
+// it closes the local connection when agent closes the channel.
+func (c *clientState) writeLocal(lc *localConn) {
+	defer lc.conn.Close()
+	for {
+		data, ok := lc.toLocal.Pop(c.ws.Ctx)
+		if !ok {
+			return
+		}
+		if _, err := lc.conn.Write(data); err != nil {
+			return
+		}
+		if grant := lc.recvWindow.Consumed(len(data)); grant > 0 {
+			c.ws.Write(utils.JoinBytes2(0x25, lc.idBytes, binary.LittleEndian.AppendUint32(nil, grant)))
+		}
+	}
+}
+
+func (f *forwarder) listenLocal(pf portForward) {
+	ln, err := listenLocalStream(pf.listen)
 	if err != nil {
-		log.Fatalf("failed to listen on %s: %v", addr, err)
+		log.Fatalf("failed to listen on %s: %v", pf.listen.address, err)
 	}
 	defer ln.Close()
-	log.Printf("forwarding 127.0.0.1:%d -> %s:%d (via agent)", pf.localPort, pf.remoteAddr, pf.remotePort)
-
-	// Close listener when WebSocket disconnects
-	go func() {
-		<-c.ws.Ctx.Done()
-		ln.Close()
-	}()
+	log.Printf("forwarding %s -> %s (via agent)", pf.listen.address, pf.target.address)
 
 	for {
 		conn, err := ln.Accept()
 		if err != nil {
 			return
 		}
-		go c.handleConn(conn, pf)
+		go func() { f.waitSession().handleConn(conn, pf) }()
 	}
 }
 
-func (c *clientState) handleConn(conn net.Conn, pf portForward) {
+// openChannel asks agent to dial target, and waits for the dial result.
+// The returned localConn is registered in c.conns; the caller shall remove it when done.
+func (c *clientState) openChannel(conn io.WriteCloser, target endpoint) (*localConn, error) {
 	id := c.counter.Add(1)
 	idBytes := binary.LittleEndian.AppendUint32(nil, id)
 
-	lc := &localConn{conn: conn, ready: make(chan struct{})}
+	<-c.hello
+	var window uint32
+	if c.features["window"] {
+		window = utils.DefaultWindowSize
+	}
+	if target.network != "tcp" && !c.features[target.network] {
+		return nil, fmt.Errorf("agent doesn't support %s forwarding, please upgrade it", target.network)
+	}
+
+	lc := &localConn{conn: conn, id: id, idBytes: idBytes, ready: make(chan struct{}), toLocal: utils.NewByteQueue(utils.StreamQueueLimit)}
+	if target.network == "udp" {
+		lc.udp = true
+		lc.toLocal = utils.NewByteQueue(utils.DatagramQueueLimit)
+	}
+	if window > 0 {
+		lc.sendWindow = utils.NewSendWindow(window)
+		lc.recvWindow = utils.NewRecvWindow(window)
+	}
 	c.conns.Store(id, lc)
-	defer c.conns.CompareAndDelete(id, lc)
-	defer conn.Close()
 
-	// Send open packet: [0x20][id:4][port:2][addr]
-	portBytes := binary.LittleEndian.AppendUint16(nil, uint16(pf.remotePort))
-	c.ws.Write(utils.JoinBytes2(0x20, idBytes, portBytes, []byte(pf.remoteAddr)))
+	if window > 0 {
+		// Send open packet: [0x26][id:4][msgpack(ProxyOpenRequest)]
+		req := biz.ProxyOpenRequest{
+			Network: target.network,
+			Address: target.address,
+			Window:  window,
+		}
+		reqBytes, _ := req.MarshalMsg(nil)
+		c.ws.Write(utils.JoinBytes2(0x26, idBytes, reqBytes))
+	} else {
+		// Send open packet: [0x20][id:4][port:2][addr]
+		host, port, _ := net.SplitHostPort(target.address)
+		portNum, _ := strconv.Atoi(port)
+		portBytes := binary.LittleEndian.AppendUint16(nil, uint16(portNum))
+		c.ws.Write(utils.JoinBytes2(0x20, idBytes, portBytes, []byte(host)))
+	}
 
 	// Wait for dial result
-	<-lc.ready
+	select {
+	case <-lc.ready:
+	case <-c.ws.Ctx.Done():
+		c.conns.CompareAndDelete(id, lc)
+		return nil, errors.New("agent connection closed")
+	}
 	if lc.dialErr != "" {
-		log.Printf("[0x%x] dial %s:%d failed: %s", id, pf.remoteAddr, pf.remotePort, lc.dialErr)
+		c.conns.CompareAndDelete(id, lc)
 		c.ws.Write(utils.JoinBytes2(0x22, idBytes))
+		return nil, errors.New(lc.dialErr)
+	}
+	return lc, nil
+}
+
+func (c *clientState) handleConn(conn net.Conn, pf portForward) {
+	defer conn.Close()
+
+	lc, err := c.openChannel(conn, pf.target)
+	if err != nil {
+		log.Printf("dial %s failed: %s", pf.target.address, err.Error())
 		return
 	}
+	defer c.conns.CompareAndDelete(lc.id, lc)
+	c.forward(conn, lc)
+}
+
+// forward pipes data between a local TCP connection and its opened channel, until either side closes.
+func (c *clientState) forward(conn net.Conn, lc *localConn) {
+	// Forward agent → local TCP
+	go c.writeLocal(lc)
+	defer lc.toLocal.Close()
 
 	// Forward local TCP → agent
 	buf := make([]byte, 32*1024)
 	for {
 		n, err := conn.Read(buf)
 		if n > 0 {
-			c.ws.Write(utils.JoinBytes2(0x21, idBytes, buf[:n]))
+			if !lc.sendWindow.Acquire(c.ws.Ctx, n) {
+				break
+			}
+			c.ws.Write(utils.JoinBytes2(0x21, lc.idBytes, buf[:n]))
 		}
 		if err != nil {
 			break
@@ -227,5 +565,92 @@ func (c *clientState) handleConn(conn net.Conn, pf portForward) {
 	}
 
 	// Notify agent that client side closed
-	c.ws.Write(utils.JoinBytes2(0x22, idBytes))
+	c.ws.Write(utils.JoinBytes2(0x22, lc.idBytes))
+}
+
+// udpPeer is a local UDP client. Each peer has its own channel to agent,
+// which the agent closes when idle.
+type udpPeer struct {
+	pc        net.PacketConn
+	addr      net.Addr
+	header    []byte           // prepended to datagrams to peer, like SOCKS5 UDP header. optional
+	toAgent   *utils.ByteQueue // datagrams from peer
+	onClose   func()
+	closeOnce sync.Once
+}
+
+func (p *udpPeer) Write(data []byte) (int, error) {
+	if len(p.header) > 0 {
+		data = append(p.header[:len(p.header):len(p.header)], data...)
+	}
+	return p.pc.WriteTo(data, p.addr)
+}
+func (p *udpPeer) Close() error {
+	p.closeOnce.Do(func() {
+		p.toAgent.Close()
+		p.onClose()
+	})
+	return nil
+}
+
+func (f *forwarder) listenLocalUdp(pf portForward) {
+	pc, err := net.ListenPacket("udp", pf.listen.address)
+	if err != nil {
+		log.Fatalf("failed to listen on udp %s: %v", pf.listen.address, err)
+	}
+	defer pc.Close()
+	log.Printf("forwarding udp %s -> %s (via agent)", pf.listen.address, pf.target.address)
+
+	peers := sync.Map{} // map[string]*udpPeer
+	buf := make([]byte, 65536)
+	for {
+		n, peerAddr, err := pc.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if n == 0 {
+			continue
+		}
+
+		key := peerAddr.String()
+		v, ok := peers.Load(key)
+		if !ok {
+			p := &udpPeer{pc: pc, addr: peerAddr, toAgent: utils.NewByteQueue(utils.DatagramQueueLimit)}
+			p.onClose = func() { peers.CompareAndDelete(key, p) }
+			peers.Store(key, p)
+			go func() { f.waitSession().handleUdpPeer(p, pf) }() // datagrams are queued meanwhile
+			v = p
+		}
+		v.(*udpPeer).toAgent.Push(bytes.Clone(buf[:n])) // dropped if the queue is full, like a lossy network
+	}
+}
+
+func (c *clientState) handleUdpPeer(p *udpPeer, pf portForward) {
+	defer p.Close()
+
+	lc, err := c.openChannel(p, pf.target)
+	if err != nil {
+		log.Printf("udp %s failed: %s", pf.target.address, err.Error())
+		return
+	}
+	defer c.conns.CompareAndDelete(lc.id, lc)
+
+	// Forward agent → local peer. Closes p when agent closes the idle channel
+	go c.writeLocal(lc)
+	defer lc.toLocal.Close()
+
+	// Forward local peer → agent, one datagram per package
+	for {
+		data, ok := p.toAgent.Pop(c.ws.Ctx)
+		if !ok {
+			break
+		}
+		if !lc.sendWindow.Acquire(c.ws.Ctx, len(data)) {
+			break
+		}
+		c.ws.Write(utils.JoinBytes2(0x21, lc.idBytes, data))
+	}
+
+	// Notify agent that client side closed
+	c.ws.Write(utils.JoinBytes2(0x22, lc.idBytes))
 }
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"testing"
+)
+
+func TestParsePortForward(t *testing.T) {
+	tcp := func(addr string) endpoint { return endpoint{network: "tcp", address: addr} }
+	unix := func(path string) endpoint { return endpoint{network: "unix", address: path} }
+
+	cases := []struct {
+		spec string
+		want portForward
+	}{
+		{"8080:80", portForward{tcp("127.0.0.1:8080"), tcp("localhost:80")}},
+		{"5432:db:5432", portForward{tcp("127.0.0.1:5432"), tcp("db:5432")}},
+		{"0.0.0.0:8080:web:80", portForward{tcp("0.0.0.0:8080"), tcp("web:80")}},
+		{"*:8080:web:80", portForward{tcp(":8080"), tcp("web:80")}},
+		{"[::1]:8080:[fe80::1]:80", portForward{tcp("[::1]:8080"), tcp("[fe80::1]:80")}},
+		{"2375:/var/run/docker.sock", portForward{tcp("127.0.0.1:2375"), unix("/var/run/docker.sock")}},
+		{"[::]:2375:/var/run/docker.sock", portForward{tcp("[::]:2375"), unix("/var/run/docker.sock")}},
+		{"/tmp/pg.sock:/run/postgresql/.s.PGSQL.5432", portForward{unix("/tmp/pg.sock"), unix("/run/postgresql/.s.PGSQL.5432")}},
+		{"./local.sock:db:5432", portForward{unix("./local.sock"), tcp("db:5432")}},
+	}
+	for _, c := range cases {
+		got, err := parsePortForward(c.spec, "tcp")
+		if err != nil {
+			t.Errorf("parsePortForward(%q): %v", c.spec, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("parsePortForward(%q) = %+v, want %+v", c.spec, got, c.want)
+		}
+	}
+
+	for _, spec := range []string{"8080", "abc:80", "8080:db:99999", "a:b:8080:db:80", "/tmp/x.sock"} {
+		if _, err := parsePortForward(spec, "tcp"); err == nil {
+			t.Errorf("parsePortForward(%q) should fail", spec)
+		}
+	}
+
+	// udp
+	got, err := parsePortForward("0.0.0.0:5353:10.0.0.2:53", "udp")
+	if err != nil || got.listen != (endpoint{"udp", "0.0.0.0:5353"}) || got.target != (endpoint{"udp", "10.0.0.2:53"}) {
+		t.Errorf("udp forward: %+v, %v", got, err)
+	}
+	if _, err := parsePortForward("5353:/run/dns.sock", "udp"); err == nil {
+		t.Errorf("unix socket with udp should fail")
+	}
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"encoding/binary"
+	"log"
+	"net"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"sync"
+)
+
+// reverseForward is a listener on agent side (-R), like `ssh -R`.
+// Connections accepted by agent are dialed to a local target.
+type reverseForward struct {
+	listen endpoint // on agent side, like "127.0.0.1:9000"
+	target endpoint // on client side, like "localhost:3000"
+	result chan string
+}
+
+// lateConn is a local connection dialed after its channel is registered,
+// so data from agent can be queued meanwhile.
+type lateConn struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	closed bool
+}
+
+// set the dialed connection. returns false if closed already
+func (l *lateConn) set(conn net.Conn) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		conn.Close()
+		return false
+	}
+	l.conn = conn
+	return true
+}
+
+func (l *lateConn) Write(data []byte) (int, error) {
+	l.mu.Lock()
+	conn := l.conn
+	l.mu.Unlock()
+	if conn == nil {
+		return 0, net.ErrClosed
+	}
+	return conn.Write(data)
+}
+
+func (l *lateConn) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	if l.conn != nil {
+		return l.conn.Close()
+	}
+	return nil
+}
+
+// listenRemote asks agent to listen on pf.listen, and forwards accepted connections to pf.target on this side.
+// It blocks until the WebSocket disconnects. The listener is closed by agent when the session ends.
+func (c *clientState) listenRemote(pf portForward) {
+	<-c.hello
+	if !c.features["reverse"] {
+		log.Printf("agent doesn't support reverse forwarding (-R), please upgrade it")
+		return
+	}
+	if pf.listen.network == "unix" && !c.features["unix"] {
+		log.Printf("agent doesn't support unix sockets, please upgrade it")
+		return
+	}
+
+	id := c.counter.Add(1)
+	idBytes := binary.LittleEndian.AppendUint32(nil, id)
+	rf := &reverseForward{
+		listen: pf.listen,
+		target: pf.target,
+		result: make(chan string, 1),
+	}
+	c.reverses.Store(id, rf)
+	defer c.reverses.Delete(id)
+
+	// Send listen packet: [0x28][id:4][msgpack(ProxyOpenRequest)]
+	req := biz.ProxyOpenRequest{Network: rf.listen.network, Address: rf.listen.address}
+	if c.features["window"] {
+		req.Window = utils.DefaultWindowSize
+	}
+	reqBytes, _ := req.MarshalMsg(nil)
+	c.ws.Write(utils.JoinBytes2(0x28, idBytes, reqBytes))
+
+	select {
+	case err := <-rf.result:
+		if err != "" {
+			log.Printf("agent failed to listen on %s: %s", rf.listen.address, err)
+			return
+		}
+	case <-c.ws.Ctx.Done():
+		return
+	}
+	log.Printf("forwarding agent %s -> %s (reverse)", rf.listen.address, rf.target.address)
+	<-c.ws.Ctx.Done()
+}
+
+// onAccepted registers the channel of a connection accepted by agent, and dials the local target.
+// Called by demux, so it must not block.
+func (c *clientState) onAccepted(rf *reverseForward, chIdBytes []byte, remoteAddr string) {
+	chId := binary.LittleEndian.Uint32(chIdBytes)
+	late := &lateConn{}
+	lc := &localConn{conn: late, id: chId, idBytes: chIdBytes, ready: make(chan struct{}), toLocal: utils.NewByteQueue(utils.StreamQueueLimit)}
+	close(lc.ready)
+	if c.features["window"] {
+		lc.sendWindow = utils.NewSendWindow(utils.DefaultWindowSize)
+		lc.recvWindow = utils.NewRecvWindow(utils.DefaultWindowSize)
+	}
+	c.conns.Store(chId, lc)
+
+	go func() {
+		defer c.conns.CompareAndDelete(chId, lc)
+
+		conn, err := net.Dial(rf.target.network, rf.target.address)
+		if err != nil {
+			log.Printf("[reverse %s] dial %s for %s failed: %s", rf.listen.address, rf.target.address, remoteAddr, err.Error())
+			c.ws.Write(utils.JoinBytes2(0x22, chIdBytes))
+			return
+		}
+		if !late.set(conn) {
+			return
+		}
+		c.forward(conn, lc)
+	}()
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"remote-agent/biz"
+	"remote-agent/utils"
+
+	"golang.org/x/term"
+)
+
+// cmdShell opens an interactive pty on agent, with local terminal in raw mode.
+// args is the command to run, default is agent's sh. returns its exit code
+func cmdShell(args []string) (int, error) {
+	ws, _, err := dialOmni()
+	if err != nil {
+		return 0, err
+	}
+	defer ws.Close()
+
+	req := biz.StartPtyRequest{InheritEnv: true}
+	if len(args) > 0 {
+		req.Cmd = args[0]
+		req.Args = args[1:]
+	}
+	if t := os.Getenv("TERM"); t != "" {
+		req.Env = []string{"TERM=" + t}
+	}
+	payload, err := req.MarshalMsg([]byte{0x01})
+	if err != nil {
+		return 0, err
+	}
+	ws.Write(payload)
+
+	stdinFd := int(os.Stdin.Fd())
+	if term.IsTerminal(stdinFd) {
+		if state, err := term.MakeRaw(stdinFd); err == nil {
+			defer term.Restore(stdinFd, state)
+		}
+	}
+
+	sendSize := func() {
+		cols, rows, err := term.GetSize(int(os.Stdout.Fd()))
+		if err != nil {
+			return
+		}
+		ws.Write(resizeFrame(cols, rows))
+	}
+
+	opened := false
+	for data := range ws.Read {
+		if len(data) == 0 {
+			continue
+		}
+		switch data[0] {
+		case 0x00: // pty output
+			os.Stdout.Write(data[1:])
+		case 0x01: // pty opened
+			opened = true
+			sendSize()
+			defer notifyResize(sendSize)()
+			go copyStdin(ws)
+		case 0x02: // pty closed, with exit code. older agents don't send it
+			if len(data) >= 5 {
+				return int(int32(binary.LittleEndian.Uint32(data[1:5]))), nil
+			}
+			return 0, nil
+		case 0xff: // before opened, it's the reason of failure. after that, only read errors when pty closes
+			if !opened {
+				return 0, errors.New(string(data[1:]))
+			}
+		}
+	}
+	return 0, fmt.Errorf("connection closed")
+}
+
+// copyStdin sends stdin to pty as 0x00 frames
+func copyStdin(ws *utils.RWChan) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if n > 0 {
+			ws.Write(utils.PrependBytes([]byte{0x00}, buf[:n]))
+		}
+		if err != nil {
+			// piped stdin ended. send Ctrl-D so the shell exits after reading everything
+			ws.Write([]byte{0x00, 0x04})
+			return
+		}
+	}
+}
+
+// resizeFrame makes a 0x03 pty resize frame: [cols:u16][rows:u16][width:u16][height:u16]
+func resizeFrame(cols, rows int) []byte {
+	frame := []byte{0x03}
+	frame = binary.LittleEndian.AppendUint16(frame, uint16(cols))
+	frame = binary.LittleEndian.AppendUint16(frame, uint16(rows))
+	frame = binary.LittleEndian.AppendUint16(frame, 0)
+	frame = binary.LittleEndian.AppendUint16(frame, 0)
+	return frame
+}
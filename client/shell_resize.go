@@ -0,0 +1,24 @@
+//go:build !windows
+
+package client
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize calls fn when the terminal is resized, until the returned stop func is called
+func notifyResize(fn func()) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGWINCH)
+	go func() {
+		for range ch {
+			fn()
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(ch)
+	}
+}
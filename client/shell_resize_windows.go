@@ -0,0 +1,6 @@
+package client
+
+// notifyResize is a no-op on windows, which has no SIGWINCH. size is only sent once when pty opens
+func notifyResize(fn func()) (stop func()) {
+	return func() {}
+}
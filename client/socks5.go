@@ -0,0 +1,331 @@
+package client
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"remote-agent/utils"
+	"strconv"
+	"sync"
+)
+
+// SOCKS5 (RFC 1928, RFC 1929) constants
+const (
+	socksVersion = 0x05
+
+	socksMethodNoAuth       = 0x00
+	socksMethodUserPass     = 0x02
+	socksMethodNoAcceptable = 0xff
+
+	socksCmdConnect      = 0x01
+	socksCmdUdpAssociate = 0x03
+
+	socksAtypIPv4   = 0x01
+	socksAtypDomain = 0x03
+	socksAtypIPv6   = 0x04
+
+	socksReplySucceeded           = 0x00
+	socksReplyGeneralFailure      = 0x01
+	socksReplyHostUnreachable     = 0x04
+	socksReplyCommandNotSupported = 0x07
+	socksReplyAtypNotSupported    = 0x08
+)
+
+// socksRequest is a parsed SOCKS5 request
+type socksRequest struct {
+	cmd  byte
+	host string
+	port int
+}
+
+// socksHandshake negotiates the auth method and reads the request.
+// If user is not empty, username/password auth is required.
+func socksHandshake(conn io.ReadWriter, user, password string) (*socksRequest, error) {
+	// greeting: [ver][nmethods][methods...]
+	head := make([]byte, 2)
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return nil, err
+	}
+	if head[0] != socksVersion {
+		return nil, fmt.Errorf("unsupported socks version %d", head[0])
+	}
+	methods := make([]byte, head[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return nil, err
+	}
+
+	method := byte(socksMethodNoAuth)
+	if user != "" {
+		method = socksMethodUserPass
+	}
+	if !bytes.Contains(methods, []byte{method}) {
+		conn.Write([]byte{socksVersion, socksMethodNoAcceptable})
+		return nil, errors.New("no acceptable auth method")
+	}
+	if _, err := conn.Write([]byte{socksVersion, method}); err != nil {
+		return nil, err
+	}
+
+	if method == socksMethodUserPass {
+		// [ver=1][ulen][user][plen][password]
+		readString := func() (string, error) {
+			n := make([]byte, 1)
+			if _, err := io.ReadFull(conn, n); err != nil {
+				return "", err
+			}
+			s := make([]byte, n[0])
+			_, err := io.ReadFull(conn, s)
+			return string(s), err
+		}
+		ver := make([]byte, 1)
+		if _, err := io.ReadFull(conn, ver); err != nil {
+			return nil, err
+		}
+		u, err := readString()
+		if err != nil {
+			return nil, err
+		}
+		p, err := readString()
+		if err != nil {
+			return nil, err
+		}
+		if subtle.ConstantTimeCompare([]byte(u), []byte(user)) != 1 || subtle.ConstantTimeCompare([]byte(p), []byte(password)) != 1 {
+			conn.Write([]byte{0x01, 0x01})
+			return nil, errors.New("bad username or password")
+		}
+		if _, err := conn.Write([]byte{0x01, 0x00}); err != nil {
+			return nil, err
+		}
+	}
+
+	// request: [ver][cmd][rsv][atyp][addr][port:2]
+	req := make([]byte, 3)
+	if _, err := io.ReadFull(conn, req); err != nil {
+		return nil, err
+	}
+	if req[0] != socksVersion {
+		return nil, fmt.Errorf("unsupported socks version %d", req[0])
+	}
+	host, port, err := readSocksAddr(conn)
+	if err != nil {
+		if errors.Is(err, errSocksAtyp) {
+			writeSocksReply(conn, socksReplyAtypNotSupported, nil)
+		}
+		return nil, err
+	}
+	return &socksRequest{cmd: req[1], host: host, port: port}, nil
+}
+
+var errSocksAtyp = errors.New("unsupported address type")
+
+// readSocksAddr reads [atyp][addr][port:2]
+func readSocksAddr(r io.Reader) (host string, port int, err error) {
+	atyp := make([]byte, 1)
+	if _, err = io.ReadFull(r, atyp); err != nil {
+		return
+	}
+	var addr []byte
+	switch atyp[0] {
+	case socksAtypIPv4:
+		addr = make([]byte, 4)
+	case socksAtypIPv6:
+		addr = make([]byte, 16)
+	case socksAtypDomain:
+		n := make([]byte, 1)
+		if _, err = io.ReadFull(r, n); err != nil {
+			return
+		}
+		addr = make([]byte, n[0])
+	default:
+		return "", 0, errSocksAtyp
+	}
+	if _, err = io.ReadFull(r, addr); err != nil {
+		return
+	}
+	portBytes := make([]byte, 2)
+	if _, err = io.ReadFull(r, portBytes); err != nil {
+		return
+	}
+
+	if atyp[0] == socksAtypDomain {
+		host = string(addr)
+	} else {
+		host = net.IP(addr).String()
+	}
+	return host, int(binary.BigEndian.Uint16(portBytes)), nil
+}
+
+// appendSocksAddr appends [atyp][addr][port:2]
+func appendSocksAddr(b []byte, host string, port int) []byte {
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			b = append(append(b, socksAtypIPv4), ip4...)
+		} else {
+			b = append(append(b, socksAtypIPv6), ip.To16()...)
+		}
+	} else {
+		b = append(append(b, socksAtypDomain, byte(len(host))), host...)
+	}
+	return binary.BigEndian.AppendUint16(b, uint16(port))
+}
+
+// writeSocksReply writes [ver][rep][rsv][bound addr]. bind may be nil
+func writeSocksReply(w io.Writer, rep byte, bind *net.UDPAddr) error {
+	b := []byte{socksVersion, rep, 0x00}
+	if bind != nil {
+		b = appendSocksAddr(b, bind.IP.String(), bind.Port)
+	} else {
+		b = appendSocksAddr(b, "0.0.0.0", 0)
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// listenSocks runs a local SOCKS5 server. Connections are dialed by agent.
+func (f *forwarder) listenSocks(listen endpoint, user, password string) {
+	ln, err := listenLocalStream(listen)
+	if err != nil {
+		log.Fatalf("failed to listen on %s: %v", listen.address, err)
+	}
+	defer ln.Close()
+	log.Printf("socks5 proxy on %s (via agent)", listen.address)
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() { f.waitSession().handleSocksConn(conn, user, password) }()
+	}
+}
+
+func (c *clientState) handleSocksConn(conn net.Conn, user, password string) {
+	defer conn.Close()
+
+	req, err := socksHandshake(conn, user, password)
+	if err != nil {
+		log.Printf("socks5 handshake failed: %s", err.Error())
+		return
+	}
+	target := endpoint{network: "tcp", address: net.JoinHostPort(req.host, strconv.Itoa(req.port))}
+
+	switch req.cmd {
+	case socksCmdConnect:
+		lc, err := c.openChannel(conn, target)
+		if err != nil {
+			log.Printf("socks5 connect %s failed: %s", target.address, err.Error())
+			writeSocksReply(conn, socksReplyHostUnreachable, nil)
+			return
+		}
+		defer c.conns.CompareAndDelete(lc.id, lc)
+		if writeSocksReply(conn, socksReplySucceeded, nil) != nil {
+			c.ws.Write(utils.JoinBytes2(0x22, lc.idBytes))
+			return
+		}
+		c.forward(conn, lc)
+
+	case socksCmdUdpAssociate:
+		<-c.hello
+		if !c.features["udp"] || !c.features["window"] {
+			writeSocksReply(conn, socksReplyCommandNotSupported, nil)
+			return
+		}
+		c.handleSocksUdp(conn)
+
+	default:
+		writeSocksReply(conn, socksReplyCommandNotSupported, nil)
+	}
+}
+
+// handleSocksUdp relays datagrams of a UDP ASSOCIATE, until the control connection closes.
+// Each destination gets its own udp channel to agent.
+func (c *clientState) handleSocksUdp(ctrl net.Conn) {
+	// relay on the address that the client reached us. 127.0.0.1 for a unix socket
+	ip := net.IPv4(127, 0, 0, 1)
+	if addr, ok := ctrl.LocalAddr().(*net.TCPAddr); ok {
+		ip = addr.IP
+	}
+	pc, err := net.ListenUDP("udp", &net.UDPAddr{IP: ip})
+	if err != nil {
+		writeSocksReply(ctrl, socksReplyGeneralFailure, nil)
+		return
+	}
+	defer pc.Close()
+	if writeSocksReply(ctrl, socksReplySucceeded, pc.LocalAddr().(*net.UDPAddr)) != nil {
+		return
+	}
+
+	// only the host of the control connection may use the relay
+	sender := &socksUdpSender{}
+	if addr, ok := ctrl.RemoteAddr().(*net.TCPAddr); ok {
+		sender.ip = addr.IP
+	}
+
+	peers := sync.Map{} // map[string]*udpPeer, key is destination
+	defer peers.Range(func(_, v any) bool {
+		v.(*udpPeer).Close()
+		return true
+	})
+
+	// the association ends when control connection closes
+	go func() {
+		io.Copy(io.Discard, ctrl)
+		pc.Close()
+	}()
+
+	buf := make([]byte, 65536)
+	for {
+		n, from, err := pc.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+
+		// [rsv:2][frag][atyp][addr][port:2][data]
+		if n < 4 || buf[2] != 0x00 {
+			continue // fragments are not supported
+		}
+		r := bytes.NewReader(buf[3:n])
+		host, port, err := readSocksAddr(r)
+		if err != nil || r.Len() == 0 {
+			continue
+		}
+		data := buf[n-r.Len() : n]
+		if !sender.accept(from) {
+			continue
+		}
+
+		key := net.JoinHostPort(host, strconv.Itoa(port))
+		v, ok := peers.Load(key)
+		if !ok {
+			p := &udpPeer{pc: pc, addr: sender.addr, toAgent: utils.NewByteQueue(utils.DatagramQueueLimit)}
+			p.header = appendSocksAddr([]byte{0x00, 0x00, 0x00}, host, port)
+			p.onClose = func() { peers.CompareAndDelete(key, p) }
+			peers.Store(key, p)
+			go c.handleUdpPeer(p, portForward{target: endpoint{network: "udp", address: key}})
+			v = p
+		}
+		v.(*udpPeer).toAgent.Push(bytes.Clone(data)) // dropped if the queue is full
+	}
+}
+
+// the client of a UDP association. datagrams from other addresses are dropped, so the relay is not open to anyone
+type socksUdpSender struct {
+	ip   net.IP       // ip of the control connection. nil for a unix socket, whose relay listens on loopback
+	addr *net.UDPAddr // bound to the first accepted datagram
+}
+
+func (s *socksUdpSender) accept(from *net.UDPAddr) bool {
+	if s.addr != nil {
+		return from.IP.Equal(s.addr.IP) && from.Port == s.addr.Port
+	}
+	if s.ip != nil && !from.IP.Equal(s.ip) {
+		return false
+	}
+	s.addr = from
+	return true
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+)
+
+// run socksHandshake on one end of a pipe, and the client script on the other
+func runSocksHandshake(t *testing.T, user, password string, client func(conn net.Conn)) (*socksRequest, error) {
+	t.Helper()
+	a, b := net.Pipe()
+	defer a.Close()
+	go func() {
+		defer b.Close()
+		client(b)
+	}()
+	return socksHandshake(a, user, password)
+}
+
+func expectRead(t *testing.T, conn net.Conn, want []byte) {
+	t.Helper()
+	got := make([]byte, len(want))
+	if _, err := io.ReadFull(conn, got); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("expect %x, got %x (%v)", want, got, err)
+	}
+}
+
+func TestSocksHandshake(t *testing.T) {
+	// no auth, connect to domain
+	req, err := runSocksHandshake(t, "", "", func(conn net.Conn) {
+		conn.Write([]byte{0x05, 0x01, 0x00})
+		expectRead(t, conn, []byte{0x05, 0x00})
+		conn.Write(append([]byte{0x05, 0x01, 0x00, 0x03, 0x07}, "db.test\x15\x38"...))
+	})
+	if err != nil || req.cmd != socksCmdConnect || req.host != "db.test" || req.port != 5432 {
+		t.Fatalf("bad request: %+v %v", req, err)
+	}
+
+	// user/password, connect to ipv6
+	req, err = runSocksHandshake(t, "bob", "pwd", func(conn net.Conn) {
+		conn.Write([]byte{0x05, 0x02, 0x00, 0x02})
+		expectRead(t, conn, []byte{0x05, 0x02})
+		conn.Write([]byte("\x01\x03bob\x03pwd"))
+		expectRead(t, conn, []byte{0x01, 0x00})
+		conn.Write(appendSocksAddr([]byte{0x05, 0x01, 0x00}, "::1", 80))
+	})
+	if err != nil || req.host != "::1" || req.port != 80 {
+		t.Fatalf("bad request: %+v %v", req, err)
+	}
+
+	// wrong password
+	_, err = runSocksHandshake(t, "bob", "pwd", func(conn net.Conn) {
+		conn.Write([]byte{0x05, 0x01, 0x02})
+		expectRead(t, conn, []byte{0x05, 0x02})
+		conn.Write([]byte("\x01\x03bob\x03bad"))
+		expectRead(t, conn, []byte{0x01, 0x01})
+	})
+	if err == nil {
+		t.Fatal("wrong password accepted")
+	}
+
+	// auth required, but client only offers no-auth
+	_, err = runSocksHandshake(t, "bob", "pwd", func(conn net.Conn) {
+		conn.Write([]byte{0x05, 0x01, 0x00})
+		expectRead(t, conn, []byte{0x05, 0xff})
+	})
+	if err == nil {
+		t.Fatal("no-auth accepted")
+	}
+}
+
+func TestSocksUdpSender(t *testing.T) {
+	udp := func(ip string, port int) *net.UDPAddr { return &net.UDPAddr{IP: net.ParseIP(ip), Port: port} }
+
+	s := &socksUdpSender{ip: net.ParseIP("10.0.0.2")}
+	if s.accept(udp("10.0.0.3", 5000)) {
+		t.Errorf("accepted a host other than the control connection's")
+	}
+	if !s.accept(udp("::ffff:10.0.0.2", 5000)) {
+		t.Errorf("rejected the control connection's host")
+	}
+	if !s.accept(udp("10.0.0.2", 5000)) {
+		t.Errorf("rejected the bound sender")
+	}
+	if s.accept(udp("10.0.0.2", 5001)) {
+		t.Errorf("accepted another port after binding")
+	}
+
+	// unix socket: the first sender is bound
+	s = &socksUdpSender{}
+	if !s.accept(udp("127.0.0.1", 6000)) || s.accept(udp("127.0.0.1", 6001)) {
+		t.Errorf("unix socket sender not bound to the first one")
+	}
+}
@@ -0,0 +1,535 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// block size of -checksum. a changed file is compared and sent by blocks
+const syncBlockSize = 64 * 1024
+
+type syncOptions struct {
+	delete   bool // delete extraneous files on destination
+	dryRun   bool // only print what would be done
+	checksum bool // compare files with block checksums, and only send changed blocks
+}
+
+const (
+	syncDelete = iota
+	syncMkdir
+	syncCopy   // new file, or whole file is sent
+	syncUpdate // file exists on both sides. with -checksum, only changed blocks are sent
+	syncAttr   // only mode or mtime differs
+)
+
+var syncOpNames = []string{"delete", "mkdir", "copy", "update", "attr"}
+
+// syncAction is one step to make destination same as source
+type syncAction struct {
+	op   int
+	path string       // relative, slash separated
+	src  biz.FileInfo // empty for syncDelete
+	dst  biz.FileInfo // only for syncUpdate
+}
+
+// cmdSync syncs a directory tree between local and agent. remote path is prefixed with ":"
+func cmdSync(args []string) error {
+	opts := syncOptions{}
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	flags.BoolVar(&opts.delete, "delete", false, "delete extraneous files on destination")
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "only print what would be done")
+	flags.BoolVar(&opts.checksum, "checksum", false, "compare files by block checksums, and only send changed blocks")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: sync [-delete] [-dry-run] [-checksum] SRC_DIR DST_DIR, remote path is prefixed with \":\"")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errors.New("expected SRC_DIR and DST_DIR")
+	}
+	srcPath, srcRemote := splitRemote(flags.Arg(0))
+	dstPath, dstRemote := splitRemote(flags.Arg(1))
+	if srcRemote == dstRemote {
+		return errors.New("exactly one of SRC_DIR and DST_DIR must be remote (prefixed with \":\")")
+	}
+
+	ws, _, err := dialOmni()
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	features, err := agentFeatures(ws)
+	if err != nil {
+		return err
+	}
+	if !features["sync"] {
+		return errors.New("agent doesn't support sync, please upgrade it")
+	}
+
+	remote := &remoteTree{ws: ws, root: dstPath}
+	local := &localTree{root: srcPath}
+	s := &syncer{opts: opts, src: local, dst: remote, transfer: remote.push(local)}
+	if srcRemote {
+		remote.root = srcPath
+		local.root = dstPath
+		s.src, s.dst, s.transfer = remote, local, remote.pull(local)
+	}
+	return s.run()
+}
+
+// syncTree is one side of sync
+type syncTree interface {
+	// walk lists all entries. exists is false if the root doesn't exist
+	walk() (entries map[string]biz.FileInfo, exists bool, err error)
+	blockSums(p string) ([][]byte, error)
+	remove(p string) error
+	mkdir(p string) error
+	setAttr(p string, mode fs.FileMode, mtime int64) error
+}
+
+type syncer struct {
+	opts     syncOptions
+	src, dst syncTree
+
+	// transfer copies ranges of a file from src to dst, then truncates it to size
+	transfer func(p string, ranges []byteRange, size int64) error
+
+	sent    int64 // bytes sent, or to send in dry run
+	changed int
+}
+
+func (s *syncer) run() error {
+	srcEntries, exists, err := s.src.walk()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("source directory doesn't exist")
+	}
+	dstEntries, exists, err := s.dst.walk()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		fmt.Println("mkdir   ./")
+		if !s.opts.dryRun {
+			if err := s.dst.mkdir(""); err != nil {
+				return err
+			}
+		}
+	}
+
+	actions, skipped := planSync(srcEntries, dstEntries, s.opts)
+	for _, p := range skipped {
+		fmt.Fprintf(os.Stderr, "skipping %s: not a regular file or directory, or cannot be read\n", p)
+	}
+
+	started := time.Now()
+	for _, a := range actions {
+		if err := s.apply(a); err != nil {
+			return fmt.Errorf("%s %s: %w", syncOpNames[a.op], a.path, err)
+		}
+	}
+
+	summary := fmt.Sprintf("%d changed, %s sent in %s", s.changed, formatBytes(s.sent), time.Since(started).Round(time.Millisecond))
+	if s.opts.dryRun {
+		summary = fmt.Sprintf("%d to change, %s to send (dry run)", s.changed, formatBytes(s.sent))
+	}
+	fmt.Fprintln(os.Stderr, summary)
+	return nil
+}
+
+func (s *syncer) apply(a syncAction) error {
+	mode := fs.FileMode(a.src.Mode)
+	switch a.op {
+	case syncDelete:
+		s.changed++
+		fmt.Printf("delete  %s\n", a.path)
+		if s.opts.dryRun {
+			return nil
+		}
+		return s.dst.remove(a.path)
+
+	case syncMkdir:
+		s.changed++
+		fmt.Printf("mkdir   %s/\n", a.path)
+		if s.opts.dryRun {
+			return nil
+		}
+		if err := s.dst.mkdir(a.path); err != nil {
+			return err
+		}
+		return s.dst.setAttr(a.path, mode, 0)
+
+	case syncAttr:
+		s.changed++
+		fmt.Printf("attr    %s\n", a.path)
+		if s.opts.dryRun {
+			return nil
+		}
+		return s.dst.setAttr(a.path, mode, a.src.Mtime)
+	}
+
+	// copy or update a file
+	ranges := blockRanges(a.src.Size, cpChunkSize, nil)
+	if a.op == syncUpdate && s.opts.checksum {
+		srcSums, err := s.src.blockSums(a.path)
+		if err != nil {
+			return err
+		}
+		dstSums, err := s.dst.blockSums(a.path)
+		if err != nil {
+			return err
+		}
+		ranges = blockRanges(a.src.Size, syncBlockSize, func(i int) bool {
+			return i < len(srcSums) && i < len(dstSums) && bytes.Equal(srcSums[i], dstSums[i])
+		})
+
+		if len(ranges) == 0 && a.src.Size == a.dst.Size {
+			if a.src.Mtime == a.dst.Mtime && mode.Perm() == fs.FileMode(a.dst.Mode).Perm() {
+				return nil // same content
+			}
+			a.op = syncAttr
+			return s.apply(a)
+		}
+	}
+
+	n := int64(0)
+	for _, r := range ranges {
+		n += r.length
+	}
+	s.changed++
+	s.sent += n
+	if a.op == syncCopy {
+		fmt.Printf("copy    %s  %s\n", a.path, formatBytes(a.src.Size))
+	} else {
+		fmt.Printf("update  %s  %s / %s\n", a.path, formatBytes(n), formatBytes(a.src.Size))
+	}
+	if s.opts.dryRun {
+		return nil
+	}
+
+	if err := s.transfer(a.path, ranges, a.src.Size); err != nil {
+		return err
+	}
+	return s.dst.setAttr(a.path, mode, a.src.Mtime)
+}
+
+// planSync compares two trees, and returns steps to make dst same as src.
+// deletions come first. then entries in path order, so a directory is made before its content.
+// skipped are source entries that cannot be synced, like symlinks
+func planSync(src, dst map[string]biz.FileInfo, opts syncOptions) (actions []syncAction, skipped []string) {
+	if opts.delete {
+		deleted := ""
+		for _, p := range sortedPaths(dst) {
+			if deleted != "" && strings.HasPrefix(p, deleted+"/") {
+				continue // removed with its parent
+			}
+			if skipByParent(src, p) {
+				continue
+			}
+			if _, ok := src[p]; !ok {
+				actions = append(actions, syncAction{op: syncDelete, path: p})
+				deleted = p
+			}
+		}
+	}
+
+	for _, p := range sortedPaths(src) {
+		s := src[p]
+		sm := fs.FileMode(s.Mode)
+		if s.Error != "" || !(sm.IsDir() || sm.IsRegular()) {
+			skipped = append(skipped, p)
+			continue
+		}
+
+		d, exists := dst[p]
+		dm := fs.FileMode(d.Mode)
+		if exists && (dm.IsDir() != sm.IsDir() || !(dm.IsDir() || dm.IsRegular())) {
+			// type changed. replace it
+			actions = append(actions, syncAction{op: syncDelete, path: p})
+			exists = false
+		}
+
+		switch {
+		case !exists && sm.IsDir():
+			actions = append(actions, syncAction{op: syncMkdir, path: p, src: s})
+		case !exists:
+			actions = append(actions, syncAction{op: syncCopy, path: p, src: s})
+		case sm.IsRegular() && (s.Size != d.Size || s.Mtime != d.Mtime || opts.checksum):
+			actions = append(actions, syncAction{op: syncUpdate, path: p, src: s, dst: d})
+		case sm.Perm() != dm.Perm():
+			actions = append(actions, syncAction{op: syncAttr, path: p, src: s})
+		}
+	}
+	return
+}
+
+// skipByParent tells whether deletion of p shall be skipped for its parent in source:
+// the parent cannot be read, so its content is unknown. or it's a file, and p is removed when the parent is replaced
+func skipByParent(src map[string]biz.FileInfo, p string) bool {
+	for p = path.Dir(p); p != "."; p = path.Dir(p) {
+		if e, ok := src[p]; ok && (e.Error != "" || !fs.FileMode(e.Mode).IsDir()) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedPaths(entries map[string]biz.FileInfo) []string {
+	paths := make([]string, 0, len(entries))
+	for p := range entries {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// localTree is a directory on this machine
+type localTree struct {
+	root string
+}
+
+func (t *localTree) path(p string) string {
+	return filepath.Join(t.root, filepath.FromSlash(p))
+}
+
+func (t *localTree) walk() (map[string]biz.FileInfo, bool, error) {
+	if info, err := os.Stat(t.root); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	} else if !info.IsDir() {
+		return nil, false, fmt.Errorf("%s is not a directory", t.root)
+	}
+	root := t.root
+	if real, err := filepath.EvalSymlinks(root); err == nil {
+		root = real
+	}
+
+	entries := map[string]biz.FileInfo{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if p == root {
+			return err
+		}
+		rel, _ := filepath.Rel(root, p)
+		item := biz.FileInfo{Path: filepath.ToSlash(rel)}
+		if info, err := d.Info(); err != nil {
+			item.Error = err.Error()
+		} else {
+			item.Size = info.Size()
+			item.Mode = uint32(info.Mode())
+			item.Mtime = info.ModTime().Unix()
+		}
+		if err != nil {
+			// a directory that cannot be read
+			item.Error = err.Error()
+		}
+		entries[item.Path] = item
+		return nil
+	})
+	return entries, true, err
+}
+
+func (t *localTree) blockSums(p string) ([][]byte, error) {
+	file, err := os.Open(t.path(p))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sums := [][]byte{}
+	for {
+		h := sha256.New()
+		n, err := io.CopyN(h, file, syncBlockSize)
+		if n > 0 {
+			sums = append(sums, h.Sum(nil))
+		}
+		if err == io.EOF {
+			return sums, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *localTree) remove(p string) error {
+	return os.RemoveAll(t.path(p))
+}
+
+func (t *localTree) mkdir(p string) error {
+	return os.MkdirAll(t.path(p), 0755)
+}
+
+func (t *localTree) setAttr(p string, mode fs.FileMode, mtime int64) error {
+	if err := os.Chmod(t.path(p), mode.Perm()); err != nil {
+		return err
+	}
+	if mtime != 0 {
+		return os.Chtimes(t.path(p), time.Unix(mtime, 0), time.Unix(mtime, 0))
+	}
+	return nil
+}
+
+// remoteTree is a directory on agent
+type remoteTree struct {
+	ws      *utils.RWChan
+	root    string
+	counter uint32
+}
+
+func (t *remoteTree) path(p string) string {
+	return path.Join(t.root, p)
+}
+
+// call sends a request with id (0x17 ~ 0x19), and returns the response after the id
+func (t *remoteTree) call(op byte, req msgp.Marshaler) ([]byte, error) {
+	t.counter++
+	idBytes := binary.LittleEndian.AppendUint32(nil, t.counter)
+	reqBytes, err := req.MarshalMsg(nil)
+	if err != nil {
+		return nil, err
+	}
+	t.ws.Write(utils.JoinBytes2(op, idBytes, reqBytes))
+	for {
+		data, err := awaitFrame(t.ws, op)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) >= 5 && bytes.Equal(data[1:5], idBytes) {
+			return data[5:], nil
+		}
+	}
+}
+
+func (t *remoteTree) walk() (map[string]biz.FileInfo, bool, error) {
+	data, err := t.call(0x17, &biz.SyncTreeRequest{Path: t.root})
+	if err != nil {
+		return nil, false, err
+	}
+	res := biz.SyncTreeResponse{}
+	if _, err := res.UnmarshalMsg(data); err != nil {
+		return nil, false, err
+	}
+	if res.Error != "" {
+		return nil, false, errors.New(res.Error)
+	}
+	if res.NotExist {
+		return nil, false, nil
+	}
+
+	entries := make(map[string]biz.FileInfo, len(res.Entries))
+	for _, e := range res.Entries {
+		entries[e.Path] = e
+	}
+	return entries, true, nil
+}
+
+func (t *remoteTree) blockSums(p string) ([][]byte, error) {
+	data, err := t.call(0x18, &biz.BlockSumRequest{Path: t.path(p), BlockSize: syncBlockSize})
+	if err != nil {
+		return nil, err
+	}
+	res := biz.BlockSumResponse{}
+	if _, err := res.UnmarshalMsg(data); err != nil {
+		return nil, err
+	}
+	if res.Error != "" {
+		return nil, errors.New(res.Error)
+	}
+	return res.Sums, nil
+}
+
+// do sends a file operation (0x14, 0x15) and waits for the ack with path
+func (t *remoteTree) do(op byte, p string) error {
+	t.ws.Write(utils.PrependBytes([]byte{op}, []byte(p)))
+	for {
+		data, err := awaitFrame(t.ws, op)
+		if err != nil {
+			return err
+		}
+		if string(data[1:]) == p {
+			return nil
+		}
+	}
+}
+
+func (t *remoteTree) remove(p string) error {
+	return t.do(0x14, t.path(p))
+}
+
+func (t *remoteTree) mkdir(p string) error {
+	return t.do(0x15, t.path(p))
+}
+
+func (t *remoteTree) setAttr(p string, mode fs.FileMode, mtime int64) error {
+	data, err := t.call(0x19, &biz.SetAttrRequest{Path: t.path(p), Mode: uint32(mode.Perm()), Mtime: mtime})
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		return errors.New(string(data))
+	}
+	return nil
+}
+
+// push returns a transfer func that writes local files to remote
+func (t *remoteTree) push(local *localTree) func(p string, ranges []byteRange, size int64) error {
+	return func(p string, ranges []byteRange, size int64) error {
+		file, err := os.Open(local.path(p))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		if err := writeRemoteRanges(t.ws, t.path(p), file, ranges, nil); err != nil {
+			return err
+		}
+		return writeRemoteChunk(t.ws, t.path(p), size, nil)
+	}
+}
+
+// pull returns a transfer func that reads remote files to local
+func (t *remoteTree) pull(local *localTree) func(p string, ranges []byteRange, size int64) error {
+	return func(p string, ranges []byteRange, size int64) error {
+		file, err := os.OpenFile(local.path(p), os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		err = readRemoteRanges(t.ws, t.path(p), ranges, func(offset int64, data []byte) error {
+			_, err := file.WriteAt(data, offset)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if err := file.Truncate(size); err != nil {
+			return err
+		}
+		return file.Close()
+	}
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"io/fs"
+	"reflect"
+	"remote-agent/biz"
+	"testing"
+)
+
+func TestBlockRanges(t *testing.T) {
+	// whole file, split by cpChunkSize
+	got := blockRanges(cpChunkSize*2+10, cpChunkSize, nil)
+	want := []byteRange{{0, cpChunkSize}, {cpChunkSize, cpChunkSize}, {cpChunkSize * 2, 10}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("whole file: got %v, want %v", got, want)
+	}
+
+	if got := blockRanges(0, cpChunkSize, nil); len(got) != 0 {
+		t.Errorf("empty file: got %v", got)
+	}
+
+	// blocks 1, 2 and 4 changed. adjacent ones are merged
+	same := map[int]bool{0: true, 3: true}
+	got = blockRanges(100*5-50, 100, func(i int) bool { return same[i] })
+	want = []byteRange{{100, 200}, {400, 50}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changed blocks: got %v, want %v", got, want)
+	}
+}
+
+func TestPlanSync(t *testing.T) {
+	dir := uint32(fs.ModeDir | 0755)
+	src := map[string]biz.FileInfo{
+		"a.txt":        {Size: 5, Mode: 0644, Mtime: 100},
+		"b.txt":        {Size: 5, Mode: 0644, Mtime: 100},
+		"c.sh":         {Size: 5, Mode: 0755, Mtime: 100},
+		"lib":          {Mode: dir},
+		"lib/x.js":     {Size: 9, Mode: 0644, Mtime: 100},
+		"link":         {Mode: uint32(fs.ModeSymlink | 0777)},
+		"secret":       {Mode: dir, Error: "permission denied"},
+		"was-dir":      {Size: 1, Mode: 0644, Mtime: 100},
+		"zz-new/y.txt": {Size: 1, Mode: 0644, Mtime: 100},
+		"zz-new":       {Mode: dir},
+	}
+	dst := map[string]biz.FileInfo{
+		"a.txt":          {Size: 5, Mode: 0644, Mtime: 100}, // same
+		"b.txt":          {Size: 5, Mode: 0644, Mtime: 99},  // mtime differs
+		"c.sh":           {Size: 5, Mode: 0644, Mtime: 100}, // mode differs
+		"lib":            {Mode: dir},
+		"old":            {Mode: dir},
+		"old/gone.txt":   {Size: 1, Mode: 0644},
+		"secret":         {Mode: dir},
+		"secret/key":     {Size: 1, Mode: 0600},
+		"was-dir":        {Mode: dir},
+		"was-dir/inner":  {Size: 1, Mode: 0644},
+		"lib/stale.js":   {Size: 1, Mode: 0644},
+		"lib/x.js":       {Size: 9, Mode: 0644, Mtime: 100},
+		"another-old.md": {Size: 1, Mode: 0644},
+	}
+
+	type step struct {
+		op   int
+		path string
+	}
+	collect := func(actions []syncAction) (out []step) {
+		for _, a := range actions {
+			out = append(out, step{a.op, a.path})
+		}
+		return
+	}
+
+	actions, skipped := planSync(src, dst, syncOptions{})
+	want := []step{
+		{syncUpdate, "b.txt"},
+		{syncAttr, "c.sh"},
+		{syncDelete, "was-dir"},
+		{syncCopy, "was-dir"},
+		{syncMkdir, "zz-new"},
+		{syncCopy, "zz-new/y.txt"},
+	}
+	if got := collect(actions); !reflect.DeepEqual(got, want) {
+		t.Errorf("without delete: got %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(skipped, []string{"link", "secret"}) {
+		t.Errorf("skipped: got %v", skipped)
+	}
+
+	// with delete. content of unreadable "secret" is kept
+	actions, _ = planSync(src, dst, syncOptions{delete: true})
+	want = append([]step{
+		{syncDelete, "another-old.md"},
+		{syncDelete, "lib/stale.js"},
+		{syncDelete, "old"},
+	}, want...)
+	if got := collect(actions); !reflect.DeepEqual(got, want) {
+		t.Errorf("with delete: got %v, want %v", got, want)
+	}
+
+	// with checksum, files of same size and mtime are compared as well
+	actions, _ = planSync(src, dst, syncOptions{checksum: true})
+	updates := 0
+	for _, a := range actions {
+		if a.op == syncUpdate {
+			updates++
+		}
+	}
+	if updates != 4 { // a.txt, b.txt, c.sh, lib/x.js
+		t.Errorf("with checksum: expected 4 updates, got %v", collect(actions))
+	}
+}
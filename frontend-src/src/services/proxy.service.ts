@@ -6,6 +6,18 @@ export interface ProxyDef {
   replace_host: string
 }
 
+export interface ProxyStats {
+  since: string
+  requests: number
+  in_flight: number
+  status: Record<string, number> // like "2xx", "5xx"
+  bytes_in: number
+  bytes_out: number
+  latency_buckets_ms: number[] // upper bounds. the last count is for +Inf
+  latency_counts: number[]
+  latency_sum_ms: number
+}
+
 export class ProxyService {
   public apiKey: string
 
@@ -27,6 +39,16 @@ export class ProxyService {
     return await res.json()
   }
 
+  async loadProxyStats(host: string): Promise<ProxyStats> {
+    const res = await fetch(`./api/proxy/${encodeURIComponent(host)}/stats`, {
+      headers: { 'X-API-Key': this.apiKey }
+    })
+    if (!res.ok) {
+      throw new Error(await res.text().catch(() => 'Unknown error'))
+    }
+    return await res.json()
+  }
+
   async deleteProxy(host: string): Promise<any> {
     const res = await fetch(`./api/proxy/${encodeURIComponent(host)}/`, {
       method: 'DELETE',
@@ -2,7 +2,7 @@ import { defineStore } from 'pinia'
 import { effect, ref } from 'vue'
 import { useAgentStore } from './agent'
 import { useConfigStore } from './config'
-import { type ProxyDef, ProxyService } from '../services/proxy.service'
+import { type ProxyDef, type ProxyStats, ProxyService } from '../services/proxy.service'
 
 export const useProxyStore = defineStore('proxy', () => {
   const agentStore = useAgentStore()
@@ -11,9 +11,16 @@ export const useProxyStore = defineStore('proxy', () => {
   effect(() => { proxyService.apiKey = configStore.apiKey })
 
   const proxyList = ref<ProxyDef[]>([])
+  const proxyStats = ref<Record<string, ProxyStats>>({})
 
   async function refreshProxyList() {
     proxyList.value = await proxyService.loadProxyList() || []
+
+    const stats: Record<string, ProxyStats> = {}
+    await Promise.all(proxyList.value.map(async (proxy) => {
+      stats[proxy.host] = await proxyService.loadProxyStats(proxy.host).catch(() => undefined as any)
+    }))
+    proxyStats.value = stats
   }
 
   async function createProxy(proxy: ProxyDef) {
@@ -63,6 +70,7 @@ export const useProxyStore = defineStore('proxy', () => {
 
   return {
     proxyList,
+    proxyStats,
     refreshProxyList,
     createProxy,
     deleteProxy,
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"remote-agent/biz"
+	"remote-agent/server/metrics"
 	"remote-agent/utils"
 	"strconv"
 	"sync"
@@ -20,6 +21,7 @@ import (
 // use MakeAgentTunnel to make one
 type AgentTunnel struct {
 	Token string
+	Kind  string // for metrics, like "proxy". defaults to type of notify
 
 	Agent         *Agent
 	AgentInstance *AgentInstance                     // Note: can be nil, if agentId not specified
@@ -73,6 +75,9 @@ func MakeAgentTunnel(agent_name, agent_id string) (tunnel *AgentTunnel, err erro
 		}
 		notified = true
 		notify.Id = tunnel.Token
+		if tunnel.Kind == "" {
+			tunnel.Kind = notify.Type
+		}
 		if msg_data, err := notify.MarshalMsg(nil); err != nil {
 			return err
 		} else {
@@ -96,6 +101,11 @@ func MakeAgentTunnel(agent_name, agent_id string) (tunnel *AgentTunnel, err erro
 
 		tunnel.closeWs = ch.Close
 
+		metrics.TunnelsActive.Inc(tunnel.Kind)
+		defer metrics.TunnelsActive.With(tunnel.Kind).Add(-1)
+		bytes_to_agent := metrics.TunnelBytes.With(agent_name, tunnel.Kind, "to_agent")
+		bytes_from_agent := metrics.TunnelBytes.With(agent_name, tunnel.Kind, "from_agent")
+
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -105,6 +115,7 @@ func MakeAgentTunnel(agent_name, agent_id string) (tunnel *AgentTunnel, err erro
 					if !ok {
 						return
 					}
+					bytes_to_agent.Add(int64(len(data)))
 					ch.Write(data)
 				case <-ch.Ctx.Done():
 					return
@@ -116,6 +127,7 @@ func MakeAgentTunnel(agent_name, agent_id string) (tunnel *AgentTunnel, err erro
 		go func() {
 			defer wg.Done()
 			for data := range ch.Read {
+				bytes_from_agent.Add(int64(len(data)))
 				chFromAgent <- data
 			}
 			close(chFromAgent)
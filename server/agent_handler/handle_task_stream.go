@@ -5,7 +5,10 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"remote-agent/biz"
+	"remote-agent/server/events"
+	"remote-agent/server/metrics"
 	"sync/atomic"
 	"time"
 )
@@ -69,6 +72,7 @@ func HandleTaskStreamRequest(w http.ResponseWriter, r *http.Request) {
 		IsUpgradable: biz.IsUserAgentCanBeUpgraded(user_agent),
 		JoinAt:       time.Now(),
 		RemoteAddr:   remote_addr,
+		Labels:       parse_labels(r.Header.Get("X-Agent-Labels")),
 		C:            instance_chan,
 		Ctx:          ctx,
 	}
@@ -79,9 +83,25 @@ func HandleTaskStreamRequest(w http.ResponseWriter, r *http.Request) {
 	defer agent.Instances.Delete(instance_id)
 	defer close(instance_chan)
 
+	Registry.Join(&instance)
+	defer Registry.Leave(agent_name)
+	metrics.TaskStreamConnects.Inc(agent_name)
+	defer metrics.TaskStreamDisconnects.Inc(agent_name)
+
 	log.Printf("new agent: %s (instance_id: %d)", agent_name, instance_id)
 	defer log.Printf("agent leave: %s (instance_id: %d)", agent_name, instance_id)
 
+	events.Emit(events.Event{Type: events.AgentJoin, Agent: agent_name, AgentId: instance_id, Data: map[string]any{
+		"remote_addr": remote_addr,
+		"user_agent":  user_agent,
+	}})
+	defer func() {
+		events.Emit(events.Event{Type: events.AgentLeave, Agent: agent_name, AgentId: instance_id, Data: map[string]any{
+			"remote_addr": remote_addr,
+			"duration":    time.Since(instance.JoinAt).Seconds(),
+		}})
+	}()
+
 	// keep alive interval
 
 	alive_interval := time.NewTicker(time.Second * 30)
@@ -114,3 +134,19 @@ func get_remote_addr(r *http.Request) string {
 	}
 	return remote_addr
 }
+
+// labels are url-encoded, like "env=prod&region=eu"
+func parse_labels(header string) map[string]string {
+	if header == "" {
+		return nil
+	}
+	values, err := url.ParseQuery(header)
+	if err != nil {
+		return nil
+	}
+	labels := make(map[string]string, len(values))
+	for k, v := range values {
+		labels[k] = v[0]
+	}
+	return labels
+}
@@ -0,0 +1,163 @@
+package agent_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// a known agent, persisted in registry file. it stays after the agent disconnects
+type RegistryEntry struct {
+	Name       string            `json:"name"`
+	FirstSeen  time.Time         `json:"first_seen"`
+	LastSeen   time.Time         `json:"last_seen"` // last join or leave of an instance
+	RemoteAddr string            `json:"remote_addr"`
+	Version    string            `json:"version"` // like "abc123@1700000000", from user agent
+	UserAgent  string            `json:"user_agent"`
+	Labels     map[string]string `json:"labels,omitempty"` // reported by agent
+}
+
+type registry struct {
+	mu      sync.Mutex
+	path    string // empty: in memory only
+	entries map[string]*RegistryEntry
+}
+
+var Registry = &registry{entries: map[string]*RegistryEntry{}}
+
+// load registry from a JSON file. later changes are saved to it.
+// a missing file is fine. empty path keeps registry in memory only
+func LoadRegistry(path string) error {
+	Registry.mu.Lock()
+	defer Registry.mu.Unlock()
+
+	Registry.path = path
+	Registry.entries = map[string]*RegistryEntry{}
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	list := []*RegistryEntry{}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	for _, entry := range list {
+		Registry.entries[entry.Name] = entry
+	}
+	return nil
+}
+
+// version part of agent user agent, like "go-remote-agent/abc123@1700000000 (linux; amd64)"
+func VersionOfUserAgent(user_agent string) string {
+	version, ok := strings.CutPrefix(user_agent, "go-remote-agent/")
+	if !ok {
+		return ""
+	}
+	version, _, _ = strings.Cut(version, " ")
+	return version
+}
+
+// record a joined agent instance
+func (r *registry) Join(instance *AgentInstance) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[instance.Name]
+	if !ok {
+		entry = &RegistryEntry{Name: instance.Name, FirstSeen: instance.JoinAt}
+		r.entries[instance.Name] = entry
+	}
+	entry.LastSeen = instance.JoinAt
+	entry.RemoteAddr = instance.RemoteAddr
+	entry.Version = VersionOfUserAgent(instance.UserAgent)
+	entry.UserAgent = instance.UserAgent
+	entry.Labels = instance.Labels
+	r.save()
+}
+
+// record a left agent instance
+func (r *registry) Leave(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry, ok := r.entries[name]; ok {
+		entry.LastSeen = time.Now()
+		r.save()
+	}
+}
+
+// remove an agent from registry. returns false if not found
+func (r *registry) Forget(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entries[name]; !ok {
+		return false
+	}
+	delete(r.entries, name)
+	r.save()
+	return true
+}
+
+// copies of all entries, sorted by name
+func (r *registry) List() []RegistryEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list := make([]RegistryEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		list = append(list, *entry)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// write to file, via a temp file so a crash won't leave it half-written. must hold r.mu
+func (r *registry) save() {
+	if r.path == "" {
+		return
+	}
+
+	list := make([]*RegistryEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		log.Printf("failed to save registry: %v", err)
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), ".registry-*")
+	if err != nil {
+		log.Printf("failed to save registry: %v", err)
+		return
+	}
+	_, err = tmp.Write(data)
+	if err2 := tmp.Close(); err == nil {
+		err = err2
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), r.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		log.Printf("failed to save registry: %v", err)
+	}
+}
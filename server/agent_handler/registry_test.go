@@ -0,0 +1,57 @@
+package agent_handler
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRegistryPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agents.json")
+	if err := LoadRegistry(path); err != nil {
+		t.Fatal(err)
+	}
+
+	join := time.Now().Add(-time.Minute).Round(0)
+	Registry.Join(&AgentInstance{
+		Name:       "bot1",
+		UserAgent:  "go-remote-agent/abc@1700000000 (linux; amd64)",
+		JoinAt:     join,
+		RemoteAddr: "10.0.0.1:1234",
+		Labels:     map[string]string{"env": "prod"},
+	})
+	Registry.Join(&AgentInstance{Name: "bot2", JoinAt: join})
+	Registry.Leave("bot1")
+
+	// reload from file
+	if err := LoadRegistry(path); err != nil {
+		t.Fatal(err)
+	}
+	list := Registry.List()
+	if len(list) != 2 || list[0].Name != "bot1" || list[1].Name != "bot2" {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+	bot1 := list[0]
+	if !bot1.FirstSeen.Equal(join) || !bot1.LastSeen.After(join) {
+		t.Errorf("first_seen %v, last_seen %v, join %v", bot1.FirstSeen, bot1.LastSeen, join)
+	}
+	if bot1.Version != "abc@1700000000" || bot1.RemoteAddr != "10.0.0.1:1234" || bot1.Labels["env"] != "prod" {
+		t.Errorf("unexpected entry: %+v", bot1)
+	}
+
+	// first_seen is kept on rejoin
+	Registry.Join(&AgentInstance{Name: "bot1", JoinAt: time.Now()})
+	if got := Registry.List()[0]; !got.FirstSeen.Equal(join) || got.Labels != nil {
+		t.Errorf("unexpected entry after rejoin: %+v", got)
+	}
+
+	if !Registry.Forget("bot2") || Registry.Forget("bot2") {
+		t.Errorf("forget bot2 should succeed once")
+	}
+	LoadRegistry(path)
+	if list := Registry.List(); len(list) != 1 || list[0].Name != "bot1" {
+		t.Errorf("unexpected list after forget: %+v", list)
+	}
+
+	LoadRegistry("")
+}
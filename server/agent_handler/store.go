@@ -24,12 +24,13 @@ func (agent *Agent) Delete() {
 var AllAgentInstances = sync.Map{}
 
 type AgentInstance struct {
-	Id           uint64          `json:"id"`
-	Name         string          `json:"name"`
-	UserAgent    string          `json:"user_agent"`
-	IsUpgradable bool            `json:"is_upgradable"`
-	JoinAt       time.Time       `json:"join_at"`
-	RemoteAddr   string          `json:"remote_addr"`
-	C            chan<- []byte   `json:"-"` // write task to this agent
-	Ctx          context.Context `json:"-"` // if agent disconnected, this context will be Done
+	Id           uint64            `json:"id"`
+	Name         string            `json:"name"`
+	UserAgent    string            `json:"user_agent"`
+	IsUpgradable bool              `json:"is_upgradable"`
+	JoinAt       time.Time         `json:"join_at"`
+	RemoteAddr   string            `json:"remote_addr"`
+	Labels       map[string]string `json:"labels,omitempty"` // reported by agent, via X-Agent-Labels header
+	C            chan<- []byte     `json:"-"`                // write task to this agent
+	Ctx          context.Context   `json:"-"`                // if agent disconnected, this context will be Done
 }
@@ -0,0 +1,130 @@
+package client_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"remote-agent/biz"
+	"remote-agent/server/metrics"
+	"strconv"
+	"time"
+)
+
+// agents clamp them too. the server rejects bigger values, so callers know
+const (
+	max_sample_ms = 5000
+	max_top_n     = 100
+)
+
+// metrics snapshot of agent's host. `format=prometheus` for scraping, otherwise JSON of AgentMetrics
+func HandleAgentMetrics(w http.ResponseWriter, r *http.Request) {
+	if !is_request_metrics_token_good(r) && block_if_request_api_key_bad(w, r) {
+		return
+	}
+
+	req := biz.AgentMetricsRequest{}
+	for name, field := range map[string]struct {
+		value *int32
+		max   int64
+	}{
+		"top":    {&req.TopN, max_top_n},
+		"sample": {&req.SampleMs, max_sample_ms},
+	} {
+		if v := r.FormValue(name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 32)
+			if err != nil || n < 0 {
+				http.Error(w, "invalid "+name, http.StatusBadRequest)
+				return
+			}
+			if n > field.max {
+				http.Error(w, fmt.Sprintf("%s is at most %d", name, field.max), http.StatusBadRequest)
+				return
+			}
+			*field.value = int32(n)
+		}
+	}
+
+	// older agents don't reply 0x32
+	reqBytes, _ := req.MarshalMsg(nil)
+	recv, status, err := omni_request(r, "metrics", 0x32, reqBytes, 10*time.Second+time.Duration(req.SampleMs)*time.Millisecond)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	m := biz.AgentMetrics{}
+	if _, err := m.UnmarshalMsg(recv); err != nil {
+		http.Error(w, "bad response: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	if r.FormValue("format") == "prometheus" {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		write_agent_host_metrics(metrics.NewWriter(w), r.PathValue("agent_name"), &m)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(&m)
+}
+
+func write_agent_host_metrics(mw *metrics.Writer, agent string, m *biz.AgentMetrics) {
+	gauge := func(name, help string, value float64) {
+		mw.Header(name, "gauge", help)
+		mw.Sample(name, value, "agent", agent)
+	}
+
+	mw.Header("ra_agent_info", "gauge", "Agent host info, always 1")
+	mw.Sample("ra_agent_info", 1, "agent", agent, "hostname", m.Hostname, "os", m.OS, "arch", m.Arch, "version", m.Agent.Version)
+	gauge("ra_agent_uptime_seconds", "Seconds since host boot", float64(m.Uptime))
+	gauge("ra_agent_cpus", "Number of CPUs", float64(m.NumCPU))
+	gauge("ra_agent_cpu_usage_percent", "Busy time of all CPUs during the sample, 0-100", m.CPUPercent)
+	gauge("ra_agent_load1", "1-minute load average", m.Load1)
+	gauge("ra_agent_load5", "5-minute load average", m.Load5)
+	gauge("ra_agent_load15", "15-minute load average", m.Load15)
+	gauge("ra_agent_memory_total_bytes", "Total memory", float64(m.MemTotal))
+	gauge("ra_agent_memory_available_bytes", "Available memory", float64(m.MemAvailable))
+	gauge("ra_agent_swap_total_bytes", "Total swap", float64(m.SwapTotal))
+	gauge("ra_agent_swap_free_bytes", "Free swap", float64(m.SwapFree))
+
+	fs_gauge := func(name, help string, value func(fs *biz.FsStat) uint64) {
+		mw.Header(name, "gauge", help)
+		for i := range m.Filesystems {
+			fs := &m.Filesystems[i]
+			mw.Sample(name, float64(value(fs)), "agent", agent, "mountpoint", fs.MountPoint, "device", fs.Device, "fstype", fs.FsType)
+		}
+	}
+	fs_gauge("ra_agent_filesystem_size_bytes", "Filesystem size", func(fs *biz.FsStat) uint64 { return fs.Total })
+	fs_gauge("ra_agent_filesystem_free_bytes", "Filesystem free bytes, including reserved blocks", func(fs *biz.FsStat) uint64 { return fs.Free })
+	fs_gauge("ra_agent_filesystem_avail_bytes", "Filesystem free bytes for unprivileged users", func(fs *biz.FsStat) uint64 { return fs.Avail })
+
+	net_counter := func(name, help string, value func(n *biz.NetStat) uint64) {
+		mw.Header(name, "counter", help)
+		for i := range m.Network {
+			n := &m.Network[i]
+			mw.Sample(name, float64(value(n)), "agent", agent, "interface", n.Interface)
+		}
+	}
+	net_counter("ra_agent_network_receive_bytes_total", "Received bytes", func(n *biz.NetStat) uint64 { return n.RxBytes })
+	net_counter("ra_agent_network_transmit_bytes_total", "Transmitted bytes", func(n *biz.NetStat) uint64 { return n.TxBytes })
+	net_counter("ra_agent_network_receive_packets_total", "Received packets", func(n *biz.NetStat) uint64 { return n.RxPackets })
+	net_counter("ra_agent_network_transmit_packets_total", "Transmitted packets", func(n *biz.NetStat) uint64 { return n.TxPackets })
+	net_counter("ra_agent_network_receive_errors_total", "Receive errors", func(n *biz.NetStat) uint64 { return n.RxErrors })
+	net_counter("ra_agent_network_transmit_errors_total", "Transmit errors", func(n *biz.NetStat) uint64 { return n.TxErrors })
+
+	mw.Header("ra_agent_process_cpu_percent", "gauge", "CPU usage of top processes, of one CPU")
+	for _, p := range m.Processes {
+		mw.Sample("ra_agent_process_cpu_percent", p.CPUPercent, "agent", agent, "pid", strconv.Itoa(int(p.Pid)), "name", p.Name)
+	}
+	mw.Header("ra_agent_process_resident_bytes", "gauge", "Resident memory of top processes")
+	for _, p := range m.Processes {
+		mw.Sample("ra_agent_process_resident_bytes", float64(p.RSS), "agent", agent, "pid", strconv.Itoa(int(p.Pid)), "name", p.Name)
+	}
+
+	gauge("ra_agent_process_uptime_seconds", "Seconds since agent process started", float64(m.Agent.Uptime))
+	gauge("ra_agent_goroutines", "Goroutines of agent process", float64(m.Agent.Goroutines))
+	gauge("ra_agent_heap_alloc_bytes", "Allocated heap of agent process", float64(m.Agent.HeapAlloc))
+	gauge("ra_agent_sys_bytes", "Memory obtained from OS by agent process", float64(m.Agent.Sys))
+	gauge("ra_agent_collect_errors", "Parts of metrics failed to collect", float64(len(m.Errors)))
+}
@@ -0,0 +1,76 @@
+package client_handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"strconv"
+	"time"
+)
+
+// compute disk usage of a path on agent.
+// the response is newline-delimited JSON: partial DiskUsageReport while scanning, the last one has `done` set
+func HandleDiskUsage(w http.ResponseWriter, r *http.Request) {
+	if block_if_request_api_key_bad(w, r) {
+		return
+	}
+
+	req := biz.DiskUsageRequest{
+		Path: utils.Defaults(r.FormValue("path"), "/"),
+	}
+	for name, field := range map[string]*int32{
+		"depth":    &req.MaxDepth,
+		"top":      &req.TopN,
+		"interval": &req.ProgressInterval,
+	} {
+		if v := r.FormValue(name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 32)
+			if err != nil {
+				http.Error(w, "invalid "+name, http.StatusBadRequest)
+				return
+			}
+			*field = int32(n)
+		}
+	}
+	req.OneFileSystem = r.FormValue("xdev") == "1"
+
+	session, err := open_omni_session(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer session.Close()
+
+	idBytes := []byte{0x00, 0x00, 0x00, 0x00}
+	reqBytes, _ := req.MarshalMsg(nil)
+	if err := session.Send(utils.JoinBytes2(0x30, idBytes, reqBytes)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	for {
+		report := biz.DiskUsageReport{Path: req.Path, Done: true}
+
+		// agent sends partial report every ProgressInterval, the wait is very generous
+		recv, err := session.Recv(utils.JoinBytes2(0x30, idBytes), time.Minute+time.Duration(req.ProgressInterval)*time.Millisecond)
+		if err != nil {
+			report.Error = err.Error()
+		} else if _, err := report.UnmarshalMsg(recv[5:]); err != nil {
+			report.Error = "bad response: " + err.Error()
+			report.Done = true
+		}
+
+		encoder.Encode(&report)
+		w.(http.Flusher).Flush()
+		if report.Done {
+			return
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package client_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"remote-agent/server/events"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSE stream of server events. optional `types` filters event types, like "agent.*,upgrade.fail".
+// after reconnecting, recent events after Last-Event-ID are replayed
+func HandleEvents(w http.ResponseWriter, r *http.Request) {
+	if block_if_request_api_key_bad(w, r) {
+		return
+	}
+
+	var types []string
+	if t := r.FormValue("types"); t != "" {
+		types = strings.Split(t, ",")
+	}
+	last_event_id, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+
+	C, replay, unsubscribe := events.Subscribe(types, last_event_id)
+	defer unsubscribe()
+
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	write_event := func(e events.Event) {
+		data, _ := json.Marshal(e)
+		fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Id, e.Type, data)
+		w.(http.Flusher).Flush()
+	}
+
+	for _, e := range replay {
+		write_event(e)
+	}
+	w.(http.Flusher).Flush()
+
+	alive_interval := time.NewTicker(time.Second * 30)
+	defer alive_interval.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-C:
+			write_event(e)
+		case <-alive_interval.C:
+			w.Write([]byte(": ping\n\n"))
+			w.(http.Flusher).Flush()
+		}
+	}
+}
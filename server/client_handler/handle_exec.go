@@ -7,8 +7,13 @@ import (
 	"net/http"
 	"remote-agent/biz"
 	"remote-agent/server/agent_handler"
+	"remote-agent/server/events"
+	"remote-agent/server/metrics"
 	"remote-agent/utils"
+	"strconv"
 	"sync"
+	"time"
+	"unicode/utf8"
 )
 
 func HandleClientExec(w http.ResponseWriter, r *http.Request) {
@@ -83,6 +88,27 @@ func HandleClientExec(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	instance_id := uint64(0)
+	if tunnel.AgentInstance != nil {
+		instance_id = tunnel.AgentInstance.Id
+	}
+	started_at := time.Now()
+	events.Emit(events.Event{Type: events.ExecStart, Agent: agent_name, AgentId: instance_id, Data: map[string]any{"cmd": event_cmd(cmd)}})
+
+	var exit_code *int32 // nil if agent left before exit
+	defer func() {
+		if exit_code != nil {
+			metrics.ExecTotal.Inc(agent_name, strconv.Itoa(int(*exit_code)))
+		} else {
+			metrics.ExecTotal.Inc(agent_name, "none")
+		}
+		events.Emit(events.Event{Type: events.ExecFinish, Agent: agent_name, AgentId: instance_id, Data: map[string]any{
+			"cmd":       event_cmd(cmd),
+			"exit_code": exit_code,
+			"duration":  time.Since(started_at).Seconds(),
+		}})
+	}()
+
 	// make a chunked response
 
 	w.Header().Set("Transfer-Encoding", "chunked")
@@ -111,8 +137,9 @@ func HandleClientExec(w http.ResponseWriter, r *http.Request) {
 
 			switch data[0] {
 			case 0x00:
-				exit_code := int32(binary.LittleEndian.Uint32(data[1:]))
-				log.Printf("%s exit code: %d", agent_name, exit_code)
+				code := int32(binary.LittleEndian.Uint32(data[1:]))
+				exit_code = &code
+				log.Printf("%s exit code: %d", agent_name, code)
 
 			case 0x01:
 				if !full && stdout {
@@ -132,3 +159,17 @@ func HandleClientExec(w http.ResponseWriter, r *http.Request) {
 
 	wg.Wait()
 }
+
+// events go to every subscriber and webhook, while commands may carry secrets like tokens.
+// so only the beginning of cmd is kept
+func event_cmd(cmd string) string {
+	const limit = 64
+	if len(cmd) <= limit {
+		return cmd
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(cmd[cut]) {
+		cut--
+	}
+	return cmd[:cut] + "..."
+}
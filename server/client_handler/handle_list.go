@@ -1,9 +1,11 @@
 package client_handler
 
 import (
+	"cmp"
 	"encoding/json"
 	"net/http"
 	"remote-agent/server/agent_handler"
+	"slices"
 	"sync"
 )
 
@@ -29,6 +31,17 @@ func write_agent_instance_list(w http.ResponseWriter, instances *sync.Map) {
 	w.Write([]byte("]"))
 }
 
+// items of the agent list with `all=1`
+type online_instance_item struct {
+	*agent_handler.AgentInstance
+	Online bool `json:"online"` // always true
+}
+type offline_agent_item struct {
+	agent_handler.RegistryEntry
+	Online bool `json:"online"` // always false
+}
+
+// list online instances. with `all=1`, known agents which are offline are listed too
 func HandleClientListAll(w http.ResponseWriter, r *http.Request) {
 	if block_if_request_api_key_bad(w, r) {
 		return
@@ -37,7 +50,31 @@ func HandleClientListAll(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
-	write_agent_instance_list(w, &agent_handler.AllAgentInstances)
+	if r.FormValue("all") != "1" {
+		write_agent_instance_list(w, &agent_handler.AllAgentInstances)
+		return
+	}
+
+	instances := []*agent_handler.AgentInstance{}
+	agent_handler.AllAgentInstances.Range(func(key, value interface{}) bool {
+		instances = append(instances, value.(*agent_handler.AgentInstance))
+		return true
+	})
+	slices.SortFunc(instances, func(a, b *agent_handler.AgentInstance) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Id, b.Id))
+	})
+
+	list := []any{}
+	for _, instance := range instances {
+		list = append(list, online_instance_item{AgentInstance: instance, Online: true})
+	}
+	for _, entry := range agent_handler.Registry.List() {
+		if raw, ok := agent_handler.Agents.Load(entry.Name); ok && raw.(*agent_handler.Agent).Count.Load() > 0 {
+			continue
+		}
+		list = append(list, offline_agent_item{RegistryEntry: entry})
+	}
+	json.NewEncoder(w).Encode(list)
 }
 
 func HandleClientListAgent(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,150 @@
+package client_handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"remote-agent/biz"
+	"remote-agent/server/agent_handler"
+	"remote-agent/server/metrics"
+	"remote-agent/server/proxy"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// metrics_token, if set, is accepted besides API key
+func is_request_metrics_token_good(r *http.Request) bool {
+	token := biz.Config.MetricsToken
+	if token == "" {
+		return false
+	}
+	auth, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(auth), []byte(token)) == 1
+}
+
+// Prometheus metrics
+func HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	if !is_request_metrics_token_good(r) && block_if_request_api_key_bad(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	mw := metrics.NewWriter(w)
+	mw.WriteVecs()
+	write_agent_metrics(mw)
+	write_tunnel_metrics(mw)
+	write_proxy_metrics(mw)
+	write_tcp_metrics(mw)
+}
+
+func write_agent_metrics(mw *metrics.Writer) {
+	type key struct{ name, version string }
+	instances := map[key]int{}
+	agents := 0
+	agent_handler.Agents.Range(func(_, _ any) bool {
+		agents++
+		return true
+	})
+	agent_handler.AllAgentInstances.Range(func(_, value any) bool {
+		instance := value.(*agent_handler.AgentInstance)
+		instances[key{instance.Name, agent_handler.VersionOfUserAgent(instance.UserAgent)}]++
+		return true
+	})
+	keys := make([]key, 0, len(instances))
+	for k := range instances {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		}
+		return keys[i].version < keys[j].version
+	})
+
+	mw.Header("ra_agents_connected", "gauge", "Connected agents, by distinct name")
+	mw.Sample("ra_agents_connected", float64(agents))
+	mw.Header("ra_agent_instances", "gauge", "Connected agent instances")
+	for _, k := range keys {
+		mw.Sample("ra_agent_instances", float64(instances[k]), "agent", k.name, "version", k.version)
+	}
+}
+
+func write_tunnel_metrics(mw *metrics.Writer) {
+	pending := 0
+	agent_handler.AgentTunnels.Range(func(_, _ any) bool {
+		pending++
+		return true
+	})
+	mw.Header("ra_tunnels_pending", "gauge", "Tunnels waiting for agent to connect")
+	mw.Sample("ra_tunnels_pending", float64(pending))
+}
+
+func write_proxy_metrics(mw *metrics.Writer) {
+	type item struct {
+		host  string
+		stats proxy.ServiceStats
+	}
+	list := []item{}
+	proxy.ProxyServices.Range(func(_, value any) bool {
+		s := value.(*proxy.Service)
+		list = append(list, item{s.Host, s.Stats()})
+		return true
+	})
+	sort.Slice(list, func(i, j int) bool { return list[i].host < list[j].host })
+
+	mw.Header("ra_proxy_requests_total", "counter", "Proxy requests by status class")
+	for _, it := range list {
+		statuses := make([]string, 0, len(it.stats.Status))
+		for status := range it.stats.Status {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		for _, status := range statuses {
+			mw.Sample("ra_proxy_requests_total", float64(it.stats.Status[status]), "host", it.host, "status", status)
+		}
+	}
+	mw.Header("ra_proxy_requests_in_flight", "gauge", "Proxy requests in progress")
+	for _, it := range list {
+		mw.Sample("ra_proxy_requests_in_flight", float64(it.stats.InFlight), "host", it.host)
+	}
+	mw.Header("ra_proxy_bytes_total", "counter", "Proxy body bytes. in: from users, out: to users")
+	for _, it := range list {
+		mw.Sample("ra_proxy_bytes_total", float64(it.stats.BytesIn), "host", it.host, "direction", "in")
+		mw.Sample("ra_proxy_bytes_total", float64(it.stats.BytesOut), "host", it.host, "direction", "out")
+	}
+	mw.Header("ra_proxy_request_duration_seconds", "histogram", "Proxy request latency")
+	for _, it := range list {
+		cumulative := int64(0)
+		for i, count := range it.stats.LatencyCounts {
+			cumulative += count
+			le := "+Inf"
+			if i < len(it.stats.LatencyBucketsMs) {
+				le = strconv.FormatFloat(it.stats.LatencyBucketsMs[i]/1000, 'g', -1, 64)
+			}
+			mw.Sample("ra_proxy_request_duration_seconds_bucket", float64(cumulative), "host", it.host, "le", le)
+		}
+		mw.Sample("ra_proxy_request_duration_seconds_sum", it.stats.LatencySumMs/1000, "host", it.host)
+		mw.Sample("ra_proxy_request_duration_seconds_count", float64(it.stats.Requests), "host", it.host)
+	}
+}
+
+func write_tcp_metrics(mw *metrics.Writer) {
+	type item struct {
+		listen string
+		active int32
+	}
+	list := []item{}
+	proxy.TcpServices.Range(func(_, value any) bool {
+		s := value.(*proxy.TcpService)
+		list = append(list, item{s.Listen, s.Active.Load()})
+		return true
+	})
+	sort.Slice(list, func(i, j int) bool { return list[i].listen < list[j].listen })
+
+	mw.Header("ra_tcp_connections_active", "gauge", "Connections in progress of TCP services")
+	for _, it := range list {
+		mw.Sample("ra_tcp_connections_active", float64(it.active), "listen", it.listen)
+	}
+}
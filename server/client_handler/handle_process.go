@@ -0,0 +1,97 @@
+package client_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"remote-agent/biz"
+	"remote-agent/server/events"
+	"remote-agent/utils"
+	"strconv"
+	"time"
+)
+
+// list processes on agent. `tree=1` nests processes under their parents
+func HandleProcessList(w http.ResponseWriter, r *http.Request) {
+	if block_if_request_api_key_bad(w, r) {
+		return
+	}
+
+	req := biz.ProcessListRequest{Tree: r.FormValue("tree") == "1"}
+	if v := r.FormValue("sample"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid sample", http.StatusBadRequest)
+			return
+		}
+		if n > max_sample_ms {
+			http.Error(w, fmt.Sprintf("sample is at most %d", max_sample_ms), http.StatusBadRequest)
+			return
+		}
+		req.SampleMs = int32(n)
+	}
+
+	resp := biz.ProcessListResponse{}
+	reqBytes, _ := req.MarshalMsg(nil)
+	recv, status, err := omni_request(r, "process", 0x33, reqBytes, 10*time.Second+time.Duration(req.SampleMs)*time.Millisecond)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if _, err := resp.UnmarshalMsg(recv); err != nil {
+		http.Error(w, "bad response: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp.Processes)
+}
+
+// POST to send a signal to a process on agent. `signal` like "TERM" (default), "KILL" or "9"
+func HandleProcessSignal(w http.ResponseWriter, r *http.Request) {
+	if block_if_request_api_key_bad(w, r) {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pid, err := strconv.ParseInt(r.PathValue("pid"), 10, 32)
+	if err != nil || pid <= 0 {
+		http.Error(w, "invalid pid", http.StatusBadRequest)
+		return
+	}
+	req := biz.ProcessSignalRequest{Pid: int32(pid), Signal: utils.Defaults(r.FormValue("signal"), "TERM")}
+
+	resp := biz.ProcessSignalResponse{}
+	reqBytes, _ := req.MarshalMsg(nil)
+	recv, status, err := omni_request(r, "process", 0x34, reqBytes, 10*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if _, err := resp.UnmarshalMsg(recv); err != nil {
+		http.Error(w, "bad response: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	events.Emit(events.Event{Type: events.ProcessSignal, Agent: r.PathValue("agent_name"), Data: map[string]any{
+		"pid":    req.Pid,
+		"signal": req.Signal,
+		"error":  resp.Error,
+	}})
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"remote-agent/biz"
 	"remote-agent/server/proxy"
+	"strconv"
 	"strings"
 )
 
@@ -37,21 +38,47 @@ func HandleProxyListAll(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(list)
 }
 
+// short name like "foo" is expanded with proxy_server_host
+func expand_proxy_host(host string) (string, bool) {
+	if strings.Contains(host, ".") {
+		return host, true
+	}
+	hostTpl := biz.Config.ProxyServerHost
+	expanded := strings.Replace(hostTpl, "*", host, -1)
+	return expanded, expanded != hostTpl
+}
+
+// get stats of a proxy service
+func HandleProxyStats(w http.ResponseWriter, r *http.Request) {
+	if block_if_request_api_key_bad(w, r) {
+		return
+	}
+
+	host, _ := expand_proxy_host(r.PathValue("host"))
+	s, ok := proxy.ProxyServices.Load(host)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "service not found"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(s.(*proxy.Service).Stats())
+}
+
 func HandleProxyEdit(w http.ResponseWriter, r *http.Request) {
 	if block_if_request_api_key_bad(w, r) {
 		return
 	}
 
-	host := r.PathValue("host")
-	if !strings.Contains(host, ".") {
-		hostTpl := biz.Config.ProxyServerHost
-		host = strings.Replace(hostTpl, "*", host, -1)
-		if host == hostTpl {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]string{"error": "invalid host, maybe proxy_server_host is not configured"})
-			return
-		}
+	host, ok := expand_proxy_host(r.PathValue("host"))
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid host, maybe proxy_server_host is not configured"})
+		return
 	}
 
 	writeError := func(status int, err error) {
@@ -68,18 +95,46 @@ func HandleProxyEdit(w http.ResponseWriter, r *http.Request) {
 			AgentId:     r.PostFormValue("agent_id"),
 			Target:      r.PostFormValue("target"),
 			ReplaceHost: r.PostFormValue("replace_host"),
+			Balance:     r.PostFormValue("balance"),
 		}
-
-		srv.Target = strings.TrimSpace(srv.Target)
-		if srv.Target == "" {
-			writeError(http.StatusBadRequest, errors.New("target is required"))
+		if v := r.PostFormValue("pool_size"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				writeError(http.StatusBadRequest, errors.New("invalid pool_size"))
+				return
+			}
+			srv.PoolSize = n
+		}
+		srv.AccessLog = r.PostFormValue("access_log")
+		if r.PostFormValue("access_log_file") != "" {
+			// the server would create or append to any path it can write
+			writeError(http.StatusBadRequest, errors.New("access_log_file is only allowed in config file"))
 			return
 		}
-		if !strings.HasPrefix(srv.Target, "http://") && !strings.HasPrefix(srv.Target, "https://") {
-			srv.Target = "http://" + srv.Target
+		srv.Auth = r.PostFormValue("auth")
+		srv.BasicAuth = r.PostForm["basic_auth"]
+		for _, v := range r.PostForm["allow_ips"] {
+			for _, ip := range strings.Split(v, ",") {
+				if ip = strings.TrimSpace(ip); ip != "" {
+					srv.AllowIPs = append(srv.AllowIPs, ip)
+				}
+			}
+		}
+		if v := r.PostFormValue("rewrite"); v != "" {
+			if err := json.Unmarshal([]byte(v), &srv.Rewrite); err != nil {
+				writeError(http.StatusBadRequest, errors.New("invalid rewrite: "+err.Error()))
+				return
+			}
 		}
-		if srv.AgentId == "" && srv.AgentName == "" {
-			writeError(http.StatusBadRequest, errors.New("agent_id or agent_name is required"))
+		if v := r.PostFormValue("routes"); v != "" {
+			if err := json.Unmarshal([]byte(v), &srv.Routes); err != nil {
+				writeError(http.StatusBadRequest, errors.New("invalid routes: "+err.Error()))
+				return
+			}
+		}
+
+		if err := srv.Check(); err != nil {
+			writeError(http.StatusBadRequest, err)
 			return
 		}
 		if err := proxy.RegisterService(srv); err != nil {
@@ -91,6 +146,15 @@ func HandleProxyEdit(w http.ResponseWriter, r *http.Request) {
 			AgentName:   srv.AgentName,
 			Target:      srv.Target,
 			ReplaceHost: srv.ReplaceHost,
+			PoolSize:    srv.PoolSize,
+			Balance:     srv.Balance,
+			Routes:      srv.Routes,
+			Auth:        srv.Auth,
+			BasicAuth:   srv.BasicAuth,
+			AllowIPs:    srv.AllowIPs,
+			Rewrite:     srv.Rewrite,
+
+			AccessLog: srv.AccessLog,
 		})
 
 	case http.MethodDelete:
@@ -0,0 +1,60 @@
+package client_handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"remote-agent/server/agent_handler"
+)
+
+type registry_item struct {
+	agent_handler.RegistryEntry
+	Online    bool `json:"online"`
+	Instances int  `json:"instances"` // count of online instances
+}
+
+// list known agents, online or not
+func HandleRegistryList(w http.ResponseWriter, r *http.Request) {
+	if block_if_request_api_key_bad(w, r) {
+		return
+	}
+
+	entries := agent_handler.Registry.List()
+	list := make([]registry_item, 0, len(entries))
+	for _, entry := range entries {
+		item := registry_item{RegistryEntry: entry}
+		if raw, ok := agent_handler.Agents.Load(entry.Name); ok {
+			item.Instances = int(raw.(*agent_handler.Agent).Count.Load())
+			item.Online = item.Instances > 0
+		}
+		list = append(list, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(list)
+}
+
+// DELETE to forget an offline agent
+func HandleRegistryForget(w http.ResponseWriter, r *http.Request) {
+	if block_if_request_api_key_bad(w, r) {
+		return
+	}
+
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.PathValue("agent_name")
+	if _, ok := agent_handler.Agents.Load(name); ok {
+		http.Error(w, "agent is online", http.StatusConflict)
+		return
+	}
+	if !agent_handler.Registry.Forget(name) {
+		http.Error(w, "agent not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
@@ -0,0 +1,147 @@
+package client_handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"remote-agent/biz"
+	"remote-agent/server/events"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// list systemd units on agent. `type` like "timer" or "all" (default "service"), `all=1` to include inactive units
+func HandleServiceList(w http.ResponseWriter, r *http.Request) {
+	if block_if_request_api_key_bad(w, r) {
+		return
+	}
+
+	req := biz.ServiceListRequest{Type: r.FormValue("type"), All: r.FormValue("all") == "1"}
+
+	resp := biz.ServiceListResponse{}
+	reqBytes, _ := req.MarshalMsg(nil)
+	recv, status, err := omni_request(r, "service", 0x35, reqBytes, time.Minute)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if _, err := resp.UnmarshalMsg(recv); err != nil {
+		http.Error(w, "bad response: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp.Units)
+}
+
+// POST to start, stop, restart or reload a unit on agent. responds the unit state after the action
+func HandleServiceAction(w http.ResponseWriter, r *http.Request) {
+	if block_if_request_api_key_bad(w, r) {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req := biz.ServiceActionRequest{Unit: r.PathValue("unit"), Action: r.PathValue("action")}
+	if block_if_unit_name_bad(w, req.Unit) {
+		return
+	}
+	switch req.Action {
+	case "start", "stop", "restart", "reload":
+	default:
+		http.Error(w, "unknown action: "+req.Action, http.StatusBadRequest)
+		return
+	}
+
+	resp := biz.ServiceActionResponse{}
+	reqBytes, _ := req.MarshalMsg(nil)
+	recv, status, err := omni_request(r, "service", 0x36, reqBytes, 3*time.Minute)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if _, err := resp.UnmarshalMsg(recv); err != nil {
+		http.Error(w, "bad response: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	events.Emit(events.Event{Type: events.ServiceAction, Agent: r.PathValue("agent_name"), Data: map[string]any{
+		"unit":   req.Unit,
+		"action": req.Action,
+		"active": resp.Unit.Active,
+		"error":  resp.Error,
+	}})
+
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Error != "" {
+		w.WriteHeader(http.StatusBadGateway)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// recent journal lines of a unit on agent. `lines` (default 100) and `since` like "1 hour ago"
+func HandleServiceJournal(w http.ResponseWriter, r *http.Request) {
+	if block_if_request_api_key_bad(w, r) {
+		return
+	}
+
+	req := biz.ServiceJournalRequest{Unit: r.PathValue("unit"), Since: r.FormValue("since")}
+	if block_if_unit_name_bad(w, req.Unit) {
+		return
+	}
+	if v := r.FormValue("lines"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid lines", http.StatusBadRequest)
+			return
+		}
+		req.Lines = int32(n)
+	}
+
+	resp := biz.ServiceJournalResponse{}
+	reqBytes, _ := req.MarshalMsg(nil)
+	recv, status, err := omni_request(r, "service", 0x37, reqBytes, time.Minute)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if _, err := resp.UnmarshalMsg(recv); err != nil {
+		http.Error(w, "bad response: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusInternalServerError)
+		return
+	}
+
+	if r.FormValue("format") == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		for _, line := range resp.Lines {
+			w.Write([]byte(line + "\n"))
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp.Lines)
+}
+
+// agent checks unit names too. this saves a session for obvious mistakes
+func block_if_unit_name_bad(w http.ResponseWriter, unit string) bool {
+	if unit == "" || strings.HasPrefix(unit, "-") {
+		http.Error(w, "invalid unit name", http.StatusBadRequest)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package client_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"remote-agent/biz"
+	"remote-agent/server/proxy"
+	"strconv"
+	"strings"
+)
+
+type tcp_service_status struct {
+	proxy.TcpServiceInfo
+	Active int32 `json:"active"` // connections in progress
+}
+
+func HandleTcpListAll(w http.ResponseWriter, r *http.Request) {
+	if block_if_request_api_key_bad(w, r) {
+		return
+	}
+
+	list := make([]tcp_service_status, 0)
+	proxy.TcpServices.Range(func(key, value any) bool {
+		s := value.(*proxy.TcpService)
+		list = append(list, tcp_service_status{TcpServiceInfo: s.TcpServiceInfo, Active: s.Active.Load()})
+		return true
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(list)
+}
+
+// listen is a port like "15432", or an address like "127.0.0.1:15432"
+func HandleTcpEdit(w http.ResponseWriter, r *http.Request) {
+	if block_if_request_api_key_bad(w, r) {
+		return
+	}
+
+	listen := r.PathValue("listen")
+	if _, err := strconv.ParseUint(listen, 10, 16); err == nil {
+		listen = ":" + listen
+	}
+
+	writeError := func(status int, err error) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		srv := proxy.TcpServiceInfo{
+			Listen:    listen,
+			AgentName: r.PostFormValue("agent_name"),
+			AgentId:   r.PostFormValue("agent_id"),
+			Target:    r.PostFormValue("target"),
+			Balance:   r.PostFormValue("balance"),
+		}
+		if v := r.PostFormValue("pool_size"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				writeError(http.StatusBadRequest, errors.New("invalid pool_size"))
+				return
+			}
+			srv.PoolSize = n
+		}
+		for _, v := range r.PostForm["allow_ips"] {
+			for _, ip := range strings.Split(v, ",") {
+				if ip = strings.TrimSpace(ip); ip != "" {
+					srv.AllowIPs = append(srv.AllowIPs, ip)
+				}
+			}
+		}
+
+		if err := srv.Check(); err != nil {
+			writeError(http.StatusBadRequest, err)
+			return
+		}
+		if _, err := proxy.RegisterTcpService(srv); err != nil {
+			writeError(http.StatusConflict, err)
+			return
+		}
+		biz.Config.TcpServices = append(biz.Config.TcpServices, biz.SavedTcpConfig{
+			Listen:    srv.Listen,
+			AgentName: srv.AgentName,
+			Target:    srv.Target,
+			PoolSize:  srv.PoolSize,
+			Balance:   srv.Balance,
+			AllowIPs:  srv.AllowIPs,
+		})
+
+	case http.MethodDelete:
+		if err := proxy.KillTcpService(listen); err != nil {
+			writeError(http.StatusNotFound, err)
+			return
+		}
+		filtered := biz.Config.TcpServices[:0]
+		for _, s := range biz.Config.TcpServices {
+			if s.Listen != listen {
+				filtered = append(filtered, s)
+			}
+		}
+		biz.Config.TcpServices = filtered
+
+	default:
+		writeError(http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
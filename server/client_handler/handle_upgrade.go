@@ -8,6 +8,9 @@ import (
 	"os"
 	"remote-agent/biz"
 	"remote-agent/server/agent_handler"
+	"remote-agent/server/events"
+	"remote-agent/server/metrics"
+	"strings"
 )
 
 func HandleUpgradeRequest(w http.ResponseWriter, r *http.Request) {
@@ -46,8 +49,25 @@ func HandleUpgradeRequest(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Connection", "keep-alive")
 	w.WriteHeader(http.StatusOK)
 
+	// emit upgrade.finish or upgrade.fail when done
+	finished := false
+	last_error := ""
+	events.Emit(events.Event{Type: events.UpgradeStart, Agent: agent_name, AgentId: agent_instance.Id})
+	defer func() {
+		if finished {
+			metrics.UpgradeTotal.Inc(agent_name, "finish")
+			events.Emit(events.Event{Type: events.UpgradeFinish, Agent: agent_name, AgentId: agent_instance.Id})
+		} else {
+			metrics.UpgradeTotal.Inc(agent_name, "fail")
+			events.Emit(events.Event{Type: events.UpgradeFail, Agent: agent_name, AgentId: agent_instance.Id, Data: map[string]any{"error": last_error}})
+		}
+	}()
+
 	writer := w.(io.Writer)
 	write_to_http := func(data string) {
+		if strings.HasPrefix(data, "error: ") {
+			last_error = data[7:]
+		}
 		writer.Write([]byte(fmt.Sprintf("data: %s\n\n", data)))
 		w.(http.Flusher).Flush()
 	}
@@ -158,6 +178,7 @@ func HandleUpgradeRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	write_to_http("remote started new executable")
+	finished = true
 }
 
 func getSelfExecutableFile() ([]byte, error) {
@@ -0,0 +1,140 @@
+package client_handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"remote-agent/biz"
+	"remote-agent/server/agent_handler"
+	"remote-agent/utils"
+	"slices"
+	"strings"
+	"time"
+)
+
+// a short-lived omni session, for REST handlers which query something from an agent
+//
+// use open_omni_session to make one, and `defer session.Close()`
+type omni_session struct {
+	ctx    context.Context
+	tunnel *agent_handler.AgentTunnel
+}
+
+// how long to wait for agent to connect back
+const omni_connect_timeout = 30 * time.Second
+
+// make a tunnel to the agent in path value `agent_name` (and optional `agent_id` form value),
+// and notify the agent to start an omni session.
+func open_omni_session(r *http.Request) (*omni_session, error) {
+	agent_name := r.PathValue("agent_name") // required
+	agent_id := r.FormValue("agent_id")     // optional
+	tunnel, err := agent_handler.MakeAgentTunnel(agent_name, agent_id)
+	if err != nil {
+		return nil, err
+	}
+
+	tunnel.Kind = "omni"
+	if err := tunnel.NotifyAgent(biz.AgentNotify{
+		Type: "pty",
+	}); err != nil {
+		tunnel.Close()
+		return nil, err
+	}
+
+	return &omni_session{ctx: r.Context(), tunnel: tunnel}, nil
+}
+
+func (s *omni_session) Send(data []byte) error {
+	select {
+	case s.tunnel.ChToAgent <- data:
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	case <-time.After(omni_connect_timeout):
+		return errors.New("agent not responding")
+	}
+}
+
+// wait for a package which starts with `prefix`. other packages are discarded.
+func (s *omni_session) Recv(prefix []byte, timeout time.Duration) ([]byte, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case data, ok := <-s.tunnel.ChFromAgent:
+			if !ok {
+				return nil, errors.New("agent disconnected")
+			}
+			if bytes.HasPrefix(data, prefix) {
+				return data, nil
+			}
+			if len(data) > 0 && data[0] == 0xff {
+				log.Printf("[agent '%s'] message: %s", s.tunnel.Agent.Name, string(data[1:]))
+			}
+		case <-s.ctx.Done():
+			return nil, s.ctx.Err()
+		case <-timer.C:
+			return nil, errors.New("timeout")
+		}
+	}
+}
+
+// ask agent for its features (0xfe). if the feature is missing, returns an error and http status
+func (s *omni_session) RequireFeature(feature string) (int, error) {
+	if err := s.Send([]byte{0xfe}); err != nil {
+		return http.StatusBadGateway, err
+	}
+	hello, err := s.Recv([]byte{0xfe}, omni_connect_timeout)
+	if err != nil {
+		return http.StatusBadGateway, err
+	}
+	if !slices.Contains(strings.Split(string(hello[1:]), ","), feature) {
+		return http.StatusNotImplemented, errors.New("agent does not support " + feature + ", please upgrade it")
+	}
+	return http.StatusOK, nil
+}
+
+func (s *omni_session) Close() {
+	s.tunnel.Close()
+
+	// unblock the tunnel, until it's totally closed
+	go func() {
+		for {
+			select {
+			case _, ok := <-s.tunnel.ChFromAgent:
+				if !ok {
+					return
+				}
+			case <-time.After(omni_connect_timeout):
+				return // agent never connected
+			}
+		}
+	}()
+}
+
+// send a `<op> <u32 id> <msgpack>` request to agent in a new omni session,
+// and returns the msgpack of response. the agent must support `feature`
+func omni_request(r *http.Request, feature string, op byte, reqBytes []byte, timeout time.Duration) ([]byte, int, error) {
+	session, err := open_omni_session(r)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	defer session.Close()
+
+	if status, err := session.RequireFeature(feature); err != nil {
+		return nil, status, err
+	}
+
+	idBytes := []byte{0x00, 0x00, 0x00, 0x00}
+	if err := session.Send(utils.JoinBytes2(op, idBytes, reqBytes)); err != nil {
+		return nil, http.StatusBadGateway, err
+	}
+	recv, err := session.Recv(utils.JoinBytes2(op, idBytes), timeout)
+	if err != nil {
+		return nil, http.StatusGatewayTimeout, err
+	}
+	return recv[5:], http.StatusOK, nil
+}
@@ -0,0 +1,112 @@
+package events
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// event types
+const (
+	AgentJoin     = "agent.join"
+	AgentLeave    = "agent.leave"
+	UpgradeStart  = "upgrade.start"
+	UpgradeFinish = "upgrade.finish"
+	UpgradeFail   = "upgrade.fail"
+	ProxyRegister = "proxy.register"
+	ProxyKill     = "proxy.kill"
+	TcpRegister   = "tcp.register"
+	TcpKill       = "tcp.kill"
+	ExecStart     = "exec.start"
+	ExecFinish    = "exec.finish"
+	ProcessSignal = "process.signal"
+	ServiceAction = "service.action"
+)
+
+type Event struct {
+	Id      uint64         `json:"id"`
+	Type    string         `json:"type"`
+	Time    time.Time      `json:"time"`
+	Agent   string         `json:"agent,omitempty"`
+	AgentId uint64         `json:"agent_id,omitempty"` // instance id
+	Data    map[string]any `json:"data,omitempty"`
+}
+
+const historySize = 100 // recent events kept for SSE reconnects (Last-Event-ID)
+
+type subscriber struct {
+	C     chan Event
+	types []string
+}
+
+var (
+	mu          sync.Mutex
+	lastId      uint64
+	history     []Event
+	subscribers = map[*subscriber]struct{}{}
+)
+
+// publish an event to subscribers and webhooks. never blocks
+func Emit(e Event) {
+	mu.Lock()
+	lastId++
+	e.Id = lastId
+	e.Time = time.Now()
+	history = append(history, e)
+	if len(history) > historySize {
+		history = history[len(history)-historySize:]
+	}
+	for sub := range subscribers {
+		if !Match(sub.types, e.Type) {
+			continue
+		}
+		select {
+		case sub.C <- e:
+		default: // slow subscriber, drop
+		}
+	}
+	mu.Unlock()
+
+	deliverToWebhooks(e)
+}
+
+// subscribe to events of given types (see Match), with recent events after lastEventId replayed.
+// call unsubscribe when done
+func Subscribe(types []string, lastEventId uint64) (c <-chan Event, replay []Event, unsubscribe func()) {
+	sub := &subscriber{C: make(chan Event, 64), types: types}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if lastEventId > 0 {
+		for _, e := range history {
+			if e.Id > lastEventId && Match(types, e.Type) {
+				replay = append(replay, e)
+			}
+		}
+	}
+	subscribers[sub] = struct{}{}
+
+	return sub.C, replay, func() {
+		mu.Lock()
+		delete(subscribers, sub)
+		mu.Unlock()
+	}
+}
+
+// check if event type matches any pattern, like "agent.leave" or "agent.*".
+// empty patterns match all
+func Match(patterns []string, eventType string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if p == "*" || p == eventType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(p, "*"); ok && strings.HasPrefix(eventType, prefix) {
+			return true
+		}
+	}
+	return false
+}
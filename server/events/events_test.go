@@ -0,0 +1,115 @@
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"remote-agent/biz"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		patterns []string
+		typ      string
+		want     bool
+	}{
+		{nil, AgentLeave, true},
+		{[]string{"*"}, ExecStart, true},
+		{[]string{AgentLeave}, AgentLeave, true},
+		{[]string{AgentLeave}, AgentJoin, false},
+		{[]string{"agent.*"}, AgentJoin, true},
+		{[]string{"agent.*", UpgradeFail}, UpgradeFail, true},
+		{[]string{"agent.*", UpgradeFail}, UpgradeStart, false},
+	}
+	for _, c := range cases {
+		if got := Match(c.patterns, c.typ); got != c.want {
+			t.Errorf("Match(%v, %q) = %v, want %v", c.patterns, c.typ, got, c.want)
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	joinC, _, unsubscribeJoin := Subscribe([]string{AgentJoin}, 0)
+	Emit(Event{Type: AgentJoin, Agent: "bot1"})
+	join := <-joinC // events are global, so the id depends on earlier tests
+	unsubscribeJoin()
+
+	C, _, unsubscribe := Subscribe([]string{"agent.*"}, 0)
+	defer unsubscribe()
+
+	Emit(Event{Type: ExecStart, Agent: "bot1"})
+	Emit(Event{Type: AgentLeave, Agent: "bot1"})
+
+	select {
+	case e := <-C:
+		if e.Type != AgentLeave || e.Agent != "bot1" || e.Time.IsZero() {
+			t.Errorf("unexpected event: %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no event")
+	}
+
+	// replay after the join
+	_, replay, unsubscribe2 := Subscribe([]string{"agent.*"}, join.Id)
+	defer unsubscribe2()
+	if len(replay) != 1 || replay[0].Type != AgentLeave {
+		t.Errorf("unexpected replay: %+v", replay)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	webhookRetryDelay = time.Millisecond
+
+	attempts := atomic.Int32{}
+	received := make(chan Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// fail the first attempt, to test retry
+		if attempts.Add(1) == 1 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		timestamp := r.Header.Get("X-Timestamp")
+		if got, want := r.Header.Get("X-Signature-256"), Sign("s3cret", timestamp, body); got != want {
+			t.Errorf("signature %q, want %q", got, want)
+		}
+		if ts, err := strconv.ParseInt(timestamp, 10, 64); err != nil || time.Since(time.Unix(ts, 0)) > time.Minute {
+			t.Errorf("bad timestamp %q", timestamp)
+		}
+		e := Event{}
+		json.Unmarshal(body, &e)
+		if r.Header.Get("X-Event-Type") != e.Type {
+			t.Errorf("X-Event-Type %q, body type %q", r.Header.Get("X-Event-Type"), e.Type)
+		}
+		received <- e
+	}))
+	defer server.Close()
+
+	StartWebhooks([]biz.WebhookConfig{{URL: server.URL, Secret: "s3cret", Events: []string{AgentLeave}}})
+	defer func() {
+		webhooksMu.Lock()
+		webhooks = nil
+		webhooksMu.Unlock()
+	}()
+
+	Emit(Event{Type: AgentJoin, Agent: "bot-db1"}) // filtered out
+	Emit(Event{Type: AgentLeave, Agent: "bot-db1"})
+
+	select {
+	case e := <-received:
+		if e.Type != AgentLeave || e.Agent != "bot-db1" {
+			t.Errorf("unexpected event: %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("attempts = %d, want 2", n)
+	}
+}
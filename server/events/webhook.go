@@ -0,0 +1,126 @@
+package events
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"remote-agent/biz"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/avast/retry-go"
+)
+
+var (
+	webhookAttempts   uint = 5
+	webhookRetryDelay      = time.Second // doubled on each retry
+	webhookTimeout         = 10 * time.Second
+)
+
+type webhook struct {
+	biz.WebhookConfig
+	secret string
+	queue  chan Event
+	client *http.Client
+}
+
+var (
+	webhooksMu sync.Mutex
+	webhooks   []*webhook
+)
+
+// start delivering events to webhooks. each webhook has its own queue, delivered in order
+func StartWebhooks(configs []biz.WebhookConfig) {
+	webhooksMu.Lock()
+	defer webhooksMu.Unlock()
+
+	for _, config := range configs {
+		h := &webhook{
+			WebhookConfig: config,
+			secret:        config.SecretValue(),
+			queue:         make(chan Event, 256),
+			client:        &http.Client{Timeout: webhookTimeout},
+		}
+		webhooks = append(webhooks, h)
+		go h.run()
+		log.Printf("webhook: %s (events: %v)", h.URL, h.Events)
+	}
+}
+
+func deliverToWebhooks(e Event) {
+	webhooksMu.Lock()
+	defer webhooksMu.Unlock()
+
+	for _, h := range webhooks {
+		if !Match(h.Events, e.Type) {
+			continue
+		}
+		select {
+		case h.queue <- e:
+		default:
+			log.Printf("webhook %s: queue is full, dropped event %d %s", h.URL, e.Id, e.Type)
+		}
+	}
+}
+
+func (h *webhook) run() {
+	for e := range h.queue {
+		body, err := json.Marshal(e)
+		if err != nil {
+			continue
+		}
+		err = retry.Do(
+			func() error { return h.post(e, body) },
+			retry.Attempts(webhookAttempts),
+			retry.Delay(webhookRetryDelay),
+			retry.LastErrorOnly(true),
+		)
+		if err != nil {
+			log.Printf("webhook %s: failed to deliver event %d %s: %v", h.URL, e.Id, e.Type, err)
+		}
+	}
+}
+
+// Sign returns the X-Signature-256 header value, like "sha256=<hex>".
+// timestamp is the X-Timestamp header, so a captured request can't be replayed later
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (h *webhook) post(e Event, body []byte) error {
+	req, err := http.NewRequest("POST", h.URL, bytes.NewReader(body))
+	if err != nil {
+		return retry.Unrecoverable(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-remote-agent-webhook/"+biz.Version)
+	req.Header.Set("X-Event-Type", e.Type)
+	req.Header.Set("X-Event-Id", strconv.FormatUint(e.Id, 10))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10) // of this attempt
+	req.Header.Set("X-Timestamp", timestamp)
+	if h.secret != "" {
+		req.Header.Set("X-Signature-256", Sign(h.secret, timestamp, body))
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"remote-agent/server/agent_handler"
 	"remote-agent/server/assets"
 	"remote-agent/server/client_handler"
+	"remote-agent/server/events"
 	"remote-agent/server/proxy"
 	"strings"
 )
@@ -25,8 +26,22 @@ func RunServer() {
 	mux_client.HandleFunc("/api/agent/{agent_name}/exec/", client_handler.HandleClientExec)
 	mux_client.HandleFunc("/api/agent/{agent_name}/omni/", client_handler.HandleClientPty)
 	mux_client.HandleFunc("/api/agent/{agent_name}/upgrade/", client_handler.HandleUpgradeRequest)
+	mux_client.HandleFunc("/api/agent/{agent_name}/du/", client_handler.HandleDiskUsage)
+	mux_client.HandleFunc("/api/agent/{agent_name}/metrics/", client_handler.HandleAgentMetrics)
+	mux_client.HandleFunc("/api/agent/{agent_name}/ps/", client_handler.HandleProcessList)
+	mux_client.HandleFunc("/api/agent/{agent_name}/ps/{pid}/signal/", client_handler.HandleProcessSignal)
+	mux_client.HandleFunc("/api/agent/{agent_name}/services/", client_handler.HandleServiceList)
+	mux_client.HandleFunc("/api/agent/{agent_name}/services/{unit}/journal/", client_handler.HandleServiceJournal)
+	mux_client.HandleFunc("/api/agent/{agent_name}/services/{unit}/{action}/", client_handler.HandleServiceAction)
+	mux_client.HandleFunc("/metrics", client_handler.HandleMetrics)
+	mux_client.HandleFunc("/api/events/", client_handler.HandleEvents)
+	mux_client.HandleFunc("/api/registry/", client_handler.HandleRegistryList)
+	mux_client.HandleFunc("/api/registry/{agent_name}/", client_handler.HandleRegistryForget)
 	mux_client.HandleFunc("/api/proxy/", client_handler.HandleProxyListAll)
 	mux_client.HandleFunc("/api/proxy/{host}/", client_handler.HandleProxyEdit)
+	mux_client.HandleFunc("/api/proxy/{host}/stats/", client_handler.HandleProxyStats)
+	mux_client.HandleFunc("/api/tcp/", client_handler.HandleTcpListAll)
+	mux_client.HandleFunc("/api/tcp/{listen}/", client_handler.HandleTcpEdit)
 	mux_client.HandleFunc("/api/config", client_handler.HandleConfigProxies)
 	mux_client.HandleFunc("/api/saveConfig", client_handler.HandleSaveConfig)
 	mux_client.HandleFunc("/", assets.HandleWebAssets)
@@ -46,7 +61,28 @@ func RunServer() {
 		}
 	})
 
+	registry_path := biz.Config.Registry
+	if registry_path == "" {
+		registry_path = "agents.json"
+	} else if registry_path == "-" {
+		registry_path = ""
+	}
+	if err := agent_handler.LoadRegistry(registry_path); err != nil {
+		log.Fatalln("failed to load registry:", err)
+	}
+
+	events.StartWebhooks(biz.Config.Webhooks)
+
 	proxy.RegisterFromConfigFile()
+	proxy.RegisterTcpFromConfigFile()
+
+	if biz.Config.ForwardProxy != "" {
+		go func() {
+			if err := proxy.ListenForwardProxy(biz.Config.ForwardProxy); err != nil {
+				log.Fatalln("failed to listen forward proxy:", err)
+			}
+		}()
+	}
 
 	log.Println("Listening on", addr)
 	if err := http.ListenAndServe(addr, nil); err != nil {
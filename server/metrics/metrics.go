@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// counters and gauges updated by server. other metrics are collected on scrape, see client_handler
+var (
+	TaskStreamConnects    = NewCounter("ra_task_stream_connects_total", "Task stream connections of agent instances, including reconnects", "agent")
+	TaskStreamDisconnects = NewCounter("ra_task_stream_disconnects_total", "Task stream disconnections of agent instances", "agent")
+	TunnelsActive         = NewGauge("ra_tunnels_active", "Connected tunnels between server and agents", "type")
+	TunnelBytes           = NewCounter("ra_tunnel_bytes_total", "Bytes transferred over tunnels", "agent", "type", "direction")
+	ExecTotal             = NewCounter("ra_exec_total", "Finished exec requests by exit code. \"none\" if agent left before exit", "agent", "exit_code")
+	UpgradeTotal          = NewCounter("ra_upgrades_total", "Agent upgrades by result", "agent", "result")
+)
+
+// a metric with labels. values are integers
+type Vec struct {
+	name   string
+	help   string
+	kind   string // "counter" or "gauge"
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]*Value // key is label values joined by \xff
+}
+
+type Value struct {
+	atomic.Int64
+	labels []string
+}
+
+var (
+	vecsMu sync.Mutex
+	vecs   []*Vec
+)
+
+func newVec(kind, name, help string, labels []string) *Vec {
+	v := &Vec{name: name, help: help, kind: kind, labels: labels, values: map[string]*Value{}}
+	vecsMu.Lock()
+	vecs = append(vecs, v)
+	vecsMu.Unlock()
+	return v
+}
+
+func NewCounter(name, help string, labels ...string) *Vec {
+	return newVec("counter", name, help, labels)
+}
+
+func NewGauge(name, help string, labels ...string) *Vec {
+	return newVec("gauge", name, help, labels)
+}
+
+// the value of given label values. keep it to update frequently
+func (v *Vec) With(labelValues ...string) *Value {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s: got %d label values, want %d", v.name, len(labelValues), len(v.labels)))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	value, ok := v.values[key]
+	if !ok {
+		value = &Value{labels: labelValues}
+		v.values[key] = value
+	}
+	return value
+}
+
+func (v *Vec) Inc(labelValues ...string) {
+	v.With(labelValues...).Add(1)
+}
+
+func (v *Vec) write(w *Writer) {
+	v.mu.Lock()
+	keys := make([]string, 0, len(v.values))
+	for k := range v.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make([]*Value, len(keys))
+	for i, k := range keys {
+		values[i] = v.values[k]
+	}
+	v.mu.Unlock()
+
+	w.Header(v.name, v.kind, v.help)
+	for _, value := range values {
+		pairs := make([]string, 0, len(v.labels)*2)
+		for i, l := range v.labels {
+			pairs = append(pairs, l, value.labels[i])
+		}
+		w.Sample(v.name, float64(value.Load()), pairs...)
+	}
+}
+
+// writes metrics in Prometheus text format
+type Writer struct {
+	w io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) Header(name, kind, help string) {
+	fmt.Fprintf(w.w, "# HELP %s %s\n# TYPE %s %s\n", name, strings.ReplaceAll(help, "\n", " "), name, kind)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// write a sample. labels are pairs of name and value
+func (w *Writer) Sample(name string, value float64, labels ...string) {
+	b := strings.Builder{}
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(labels[i])
+			b.WriteString(`="`)
+			b.WriteString(labelEscaper.Replace(labels[i+1]))
+			b.WriteByte('"')
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	b.WriteByte('\n')
+	io.WriteString(w.w, b.String())
+}
+
+// write all counters and gauges
+func (w *Writer) WriteVecs() {
+	vecsMu.Lock()
+	list := append([]*Vec{}, vecs...)
+	vecsMu.Unlock()
+
+	for _, v := range list {
+		v.write(w)
+	}
+}
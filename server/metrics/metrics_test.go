@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteVecs(t *testing.T) {
+	vecsMu.Lock()
+	saved := vecs
+	vecs = nil
+	vecsMu.Unlock()
+	defer func() {
+		vecsMu.Lock()
+		vecs = saved
+		vecsMu.Unlock()
+	}()
+
+	c := NewCounter("test_total", "A counter", "agent", "code")
+	g := NewGauge("test_active", "A gauge")
+	c.Inc("bot2", "0")
+	c.Inc("bot1", "1")
+	c.With("bot1", "1").Add(2)
+	c.Inc(`we"ird\`, "0")
+	g.With().Add(3)
+	g.With().Add(-1)
+
+	b := &strings.Builder{}
+	NewWriter(b).WriteVecs()
+	want := `# HELP test_total A counter
+# TYPE test_total counter
+test_total{agent="bot1",code="1"} 3
+test_total{agent="bot2",code="0"} 1
+test_total{agent="we\"ird\\",code="0"} 1
+# HELP test_active A gauge
+# TYPE test_active gauge
+test_active 2
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,283 @@
+package proxy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"remote-agent/biz"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	AuthNone   = "none"
+	AuthBasic  = "basic"
+	AuthAPIKey = "api_key" // the server API key, via a login page and cookie
+)
+
+const (
+	authPathPrefix = "/.ra-auth/" // reserved paths of login page, when Auth is "api_key"
+	authCookieName = "ra_proxy_auth"
+	authCookieTTL  = 7 * 24 * time.Hour
+)
+
+// check auth options, and fill defaults
+func (info *ServiceInfo) CheckAuthOptions() error {
+	switch info.Auth {
+	case "":
+		info.Auth = AuthNone
+	case AuthNone:
+	case AuthBasic:
+		if len(info.BasicAuth) == 0 {
+			return errors.New("basic_auth is required")
+		}
+		for _, cred := range info.BasicAuth {
+			if !strings.Contains(cred, ":") {
+				return errors.New("basic_auth must be user:password")
+			}
+		}
+	case AuthAPIKey:
+		if biz.Config.APIKey == "" {
+			return errors.New("api_key is not configured on server")
+		}
+	default:
+		return errors.New("unknown auth: " + info.Auth)
+	}
+
+	_, err := parseAllowIPs(info.AllowIPs)
+	return err
+}
+
+// parse IPs and CIDRs
+func parseAllowIPs(list []string) ([]netip.Prefix, error) {
+	ans := make([]netip.Prefix, 0, len(list))
+	for _, s := range list {
+		s = strings.TrimSpace(s)
+		if strings.Contains(s, "/") {
+			prefix, err := netip.ParsePrefix(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid allow_ips: %w", err)
+			}
+			ans = append(ans, prefix.Masked())
+		} else {
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid allow_ips: %w", err)
+			}
+			ans = append(ans, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+	return ans, nil
+}
+
+// ip of the user. X-Real-IP is trusted only if the request comes from a trusted proxy
+func clientIP(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	addr = addr.Unmap()
+
+	if isFromTrustedProxy(r) {
+		if realIP, err := netip.ParseAddr(r.Header.Get("X-Real-IP")); err == nil {
+			addr = realIP.Unmap()
+		}
+	}
+	return addr, true
+}
+
+// check access of request. if not allowed, a response is written (maybe the login page) and returns true.
+// credentials used by the gate are removed from the request, so they are not sent to the target
+func (s *Service) blockIfUnauthorized(w http.ResponseWriter, r *http.Request) (blocked bool) {
+	if len(s.allowIPs) > 0 {
+		allowed := false
+		if addr, ok := clientIP(r); ok {
+			for _, prefix := range s.allowIPs {
+				if prefix.Contains(addr) {
+					allowed = true
+					break
+				}
+			}
+		}
+		if !allowed {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return true
+		}
+	}
+
+	switch s.Auth {
+	case AuthBasic:
+		user, password, ok := r.BasicAuth()
+		if ok && s.checkBasicAuth(user, password) {
+			r.Header.Del("Authorization")
+			return false
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="`+s.Host+`", charset="UTF-8"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return true
+
+	case AuthAPIKey:
+		if strings.HasPrefix(r.URL.Path, authPathPrefix) {
+			s.handleLogin(w, r)
+			return true
+		}
+		if key := r.Header.Get("X-API-Key"); key != "" && secureEqual(key, biz.Config.APIKey) {
+			r.Header.Del("X-API-Key")
+			return false
+		}
+		if cookie, err := r.Cookie(authCookieName); err == nil && s.checkAuthToken(cookie.Value) {
+			removeCookie(r, authCookieName)
+			return false
+		}
+		if r.Method != http.MethodGet || strings.ToLower(r.Header.Get("Upgrade")) == "websocket" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return true
+		}
+		s.writeLoginPage(w, r.URL.RequestURI(), "")
+		return true
+	}
+
+	return false
+}
+
+func (s *Service) checkBasicAuth(user, password string) bool {
+	ok := false
+	for _, cred := range s.BasicAuth {
+		u, p, _ := strings.Cut(cred, ":")
+		if secureEqual(user, u) && secureEqual(password, p) {
+			ok = true
+		}
+	}
+	return ok
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// token of the cookie: "<expire unix>.<hmac>". it's bound to the host, and invalidated when the API key changes
+func (s *Service) makeAuthToken(expire time.Time) string {
+	exp := strconv.FormatInt(expire.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(biz.Config.APIKey))
+	mac.Write([]byte(s.Host + "|" + exp))
+	return exp + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Service) checkAuthToken(token string) bool {
+	exp, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	expUnix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || time.Now().Unix() > expUnix {
+		return false
+	}
+	return hmac.Equal([]byte(token), []byte(s.makeAuthToken(time.Unix(expUnix, 0))))
+}
+
+// remove a cookie from request header
+func removeCookie(r *http.Request, name string) {
+	cookies := r.Cookies()
+	r.Header.Del("Cookie")
+	for _, c := range cookies {
+		if c.Name != name {
+			r.AddCookie(c)
+		}
+	}
+}
+
+// returns next if it's a path on this host, otherwise "/".
+// browsers take "//evil.com" and `/\evil.com` as other hosts
+func localRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.Contains(next, `\`) {
+		return "/"
+	}
+	u, err := url.Parse(next)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return "/"
+	}
+	return next
+}
+
+// login and logout, under authPathPrefix
+func (s *Service) handleLogin(w http.ResponseWriter, r *http.Request) {
+	next := localRedirect(r.FormValue("next"))
+	secure := publicScheme(r) == "https"
+
+	switch r.URL.Path {
+	case authPathPrefix + "login":
+		if r.Method != http.MethodPost {
+			s.writeLoginPage(w, next, "")
+			return
+		}
+		if !secureEqual(r.PostFormValue("api_key"), biz.Config.APIKey) {
+			log.Printf("[proxy '%s'] login failed from %s", s.Host, r.RemoteAddr)
+			s.writeLoginPage(w, next, "Invalid API key")
+			return
+		}
+
+		expire := time.Now().Add(authCookieTTL)
+		http.SetCookie(w, &http.Cookie{
+			Name:     authCookieName,
+			Value:    s.makeAuthToken(expire),
+			Path:     "/",
+			Expires:  expire,
+			HttpOnly: true,
+			Secure:   secure,
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, r, next, http.StatusSeeOther)
+
+	case authPathPrefix + "logout":
+		http.SetCookie(w, &http.Cookie{Name: authCookieName, Path: "/", MaxAge: -1, HttpOnly: true, Secure: secure})
+		http.Redirect(w, r, authPathPrefix+"login", http.StatusSeeOther)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+var loginPageTemplate = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Login - {{.Host}}</title>
+<style>
+body { font-family: sans-serif; display: flex; justify-content: center; margin-top: 20vh; }
+form { display: flex; flex-direction: column; gap: 8px; width: 280px; }
+.error { color: #c00; }
+</style>
+</head>
+<body>
+<form method="post" action="/.ra-auth/login">
+<h3>{{.Host}}</h3>
+{{if .Error}}<div class="error">{{.Error}}</div>{{end}}
+<input type="hidden" name="next" value="{{.Next}}">
+<input type="password" name="api_key" placeholder="API Key" autofocus required>
+<button type="submit">Login</button>
+</form>
+</body>
+</html>
+`))
+
+func (s *Service) writeLoginPage(w http.ResponseWriter, next string, errMsg string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusUnauthorized)
+	loginPageTemplate.Execute(w, map[string]string{"Host": s.Host, "Next": next, "Error": errMsg})
+}
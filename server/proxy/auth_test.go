@@ -0,0 +1,147 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"remote-agent/biz"
+	"strings"
+	"testing"
+)
+
+func makeAuthService(t *testing.T, info ServiceInfo) *Service {
+	info.Host = "auth.test"
+	if err := info.CheckAuthOptions(); err != nil {
+		t.Fatalf("bad auth options: %s", err.Error())
+	}
+	s := &Service{ServiceInfo: info}
+	s.allowIPs, _ = parseAllowIPs(info.AllowIPs)
+	return s
+}
+
+func TestAuthAllowIPs(t *testing.T) {
+	s := makeAuthService(t, ServiceInfo{AllowIPs: []string{"10.0.0.0/8", "127.0.0.1", "192.168.1.2"}})
+
+	type testCase struct {
+		remote, realIP string
+		allowed        bool
+	}
+	check := func(cases []testCase) {
+		t.Helper()
+		for _, c := range cases {
+			r := httptest.NewRequest("GET", "http://auth.test/", nil)
+			r.RemoteAddr = c.remote
+			if c.realIP != "" {
+				r.Header.Set("X-Real-IP", c.realIP)
+			}
+			blocked := s.blockIfUnauthorized(httptest.NewRecorder(), r)
+			if blocked == c.allowed {
+				t.Errorf("%s (real ip %q): allowed=%v, want %v", c.remote, c.realIP, !blocked, c.allowed)
+			}
+		}
+	}
+
+	// no trusted proxy: X-Real-IP is never used, even from loopback
+	check([]testCase{
+		{"10.1.2.3:1234", "", true},
+		{"192.168.1.2:1234", "", true},
+		{"192.168.1.3:1234", "", false},
+		{"192.168.1.3:1234", "10.0.0.1", false},
+		{"127.0.0.1:1234", "8.8.8.8", true},
+	})
+
+	biz.Config.TrustedProxies = []string{"127.0.0.1", "172.16.0.0/12"}
+	defer func() { biz.Config.TrustedProxies = nil }()
+	check([]testCase{
+		{"192.168.1.3:1234", "10.0.0.1", false}, // X-Real-IP from an untrusted peer
+		{"127.0.0.1:1234", "10.0.0.1", true},    // from trusted reverse proxy
+		{"127.0.0.1:1234", "8.8.8.8", false},
+		{"172.17.0.2:1234", "10.0.0.1", true},
+		{"[::1]:1234", "10.0.0.1", false}, // not in the list
+	})
+}
+
+func TestAuthBasic(t *testing.T) {
+	s := makeAuthService(t, ServiceInfo{Auth: AuthBasic, BasicAuth: []string{"alice:secret"}})
+
+	r := httptest.NewRequest("GET", "http://auth.test/", nil)
+	w := httptest.NewRecorder()
+	Assert(t, s.blockIfUnauthorized(w, r), "no credential blocked")
+	Assert(t, w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") != "", "basic auth challenge")
+
+	r.SetBasicAuth("alice", "wrong")
+	Assert(t, s.blockIfUnauthorized(httptest.NewRecorder(), r), "wrong password blocked")
+
+	r.SetBasicAuth("alice", "secret")
+	Assert(t, !s.blockIfUnauthorized(httptest.NewRecorder(), r), "good password allowed")
+	Assert(t, r.Header.Get("Authorization") == "", "credential not forwarded")
+
+	Assert(t, (&ServiceInfo{Auth: AuthBasic}).CheckAuthOptions() != nil, "basic auth without users rejected")
+}
+
+func TestAuthAPIKey(t *testing.T) {
+	oldKey := biz.Config.APIKey
+	defer func() { biz.Config.APIKey = oldKey }()
+
+	biz.Config.APIKey = ""
+	Assert(t, (&ServiceInfo{Auth: AuthAPIKey}).CheckAuthOptions() != nil, "api_key auth without server key rejected")
+
+	biz.Config.APIKey = "the-key"
+	s := makeAuthService(t, ServiceInfo{Auth: AuthAPIKey})
+
+	// 1. login page
+	w := httptest.NewRecorder()
+	Assert(t, s.blockIfUnauthorized(w, httptest.NewRequest("GET", "http://auth.test/dashboard?a=1", nil)), "not logged in")
+	Assert(t, w.Code == http.StatusUnauthorized, "login page status")
+	Assert(t, strings.Contains(w.Body.String(), `value="/dashboard?a=1"`), "login page with next")
+
+	// 2. login
+	login := func(key string) *httptest.ResponseRecorder {
+		form := url.Values{"api_key": {key}, "next": {"/dashboard?a=1"}}
+		r := httptest.NewRequest("POST", "http://auth.test/.ra-auth/login", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		Assert(t, s.blockIfUnauthorized(w, r), "login handled by gate")
+		return w
+	}
+	Assert(t, len(login("bad").Result().Cookies()) == 0, "bad key no cookie")
+
+	w = login("the-key")
+	Assert(t, w.Code == http.StatusSeeOther && w.Header().Get("Location") == "/dashboard?a=1", "redirect to next")
+	cookies := w.Result().Cookies()
+	Assert(t, len(cookies) == 1 && cookies[0].Name == authCookieName && cookies[0].HttpOnly, "auth cookie set")
+
+	// only local redirects after login
+	for next, want := range map[string]string{
+		"/a/b?c=1#d":        "/a/b?c=1#d",
+		"//evil.com":        "/",
+		`/\evil.com`:        "/",
+		`/a\b`:              "/",
+		"https://evil.com/": "/",
+		"evil.com":          "/",
+		"/%0d%0aLocation:x": "/%0d%0aLocation:x",
+	} {
+		Assert(t, localRedirect(next) == want, "redirect of "+next+": "+localRedirect(next))
+	}
+
+	// 3. access with cookie. the cookie is not forwarded
+	r := httptest.NewRequest("GET", "http://auth.test/dashboard", nil)
+	r.AddCookie(cookies[0])
+	r.AddCookie(&http.Cookie{Name: "app", Value: "1"})
+	Assert(t, !s.blockIfUnauthorized(httptest.NewRecorder(), r), "cookie allowed")
+	Assert(t, r.Header.Get("Cookie") == "app=1", "auth cookie removed, got "+r.Header.Get("Cookie"))
+
+	// 4. token is bound to host and key
+	other := makeAuthService(t, ServiceInfo{Auth: AuthAPIKey})
+	other.Host = "other.test"
+	Assert(t, !other.checkAuthToken(cookies[0].Value), "token of other host rejected")
+	biz.Config.APIKey = "new-key"
+	Assert(t, !s.checkAuthToken(cookies[0].Value), "token rejected after key changed")
+	biz.Config.APIKey = "the-key"
+
+	// 5. header
+	r = httptest.NewRequest("POST", "http://auth.test/api", nil)
+	Assert(t, s.blockIfUnauthorized(httptest.NewRecorder(), r), "POST without key blocked")
+	r.Header.Set("X-API-Key", "the-key")
+	Assert(t, !s.blockIfUnauthorized(httptest.NewRecorder(), r), "X-API-Key allowed")
+}
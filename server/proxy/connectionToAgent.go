@@ -25,9 +25,13 @@ type ConnectionToAgent struct {
 	Ctx context.Context
 
 	agentName   string
+	agentId     string        // instance id. empty if any instance
+	inflight    atomic.Int32  // requests in progress
 	counter     atomic.Uint32 // connection counter
 	chanToAgent chan<- []byte // send to agent
-	R           sync.Map      // map[uint32]<-chan []byte // receive from agent
+	R           sync.Map      // map[uint32]*proxyChannel
+	features    map[string]bool
+	done        chan struct{} // closed when disconnected
 
 	mu              sync.Mutex
 	cond            *sync.Cond
@@ -35,6 +39,33 @@ type ConnectionToAgent struct {
 	connectionError error
 }
 
+// a proxy channel (request) multiplexed on the connection
+type proxyChannel struct {
+	idBytes   []byte
+	fromAgent *utils.ByteQueue // packages from agent. blocks the communicate loop only if agent doesn't support flow control
+
+	// flow control. nil if agent doesn't support it
+	sendWindow *utils.SendWindow
+	recvWindow *utils.RecvWindow
+}
+
+func newProxyChannel(id uint32, window uint32) *proxyChannel {
+	p := &proxyChannel{
+		idBytes:   binary.LittleEndian.AppendUint32(nil, id),
+		fromAgent: utils.NewByteQueue(utils.StreamQueueLimit),
+	}
+	if window > 0 {
+		p.sendWindow = utils.NewSendWindow(window)
+		p.recvWindow = utils.NewRecvWindow(window)
+	}
+	return p
+}
+
+// interval of health check. if agent doesn't reply a ping before next one, the connection is closed
+const healthCheckInterval = 15 * time.Second
+
+var healthPing = []byte{0xff, 'h', 'c'}
+
 type CTAStatus int
 
 const (
@@ -45,7 +76,8 @@ const (
 
 func NewConnectionToAgent(ctx context.Context) *ConnectionToAgent {
 	c := &ConnectionToAgent{
-		Ctx: ctx,
+		Ctx:  ctx,
+		done: make(chan struct{}),
 	}
 	c.cond = sync.NewCond(&c.mu)
 
@@ -55,6 +87,7 @@ func NewConnectionToAgent(ctx context.Context) *ConnectionToAgent {
 // run this in a goroutine when connection created.
 func (c *ConnectionToAgent) ConnectAndCommunicate(agent_name string, agent_id string, onDisconnected func(error)) {
 	err := c.communicate(agent_name, agent_id)
+	close(c.done)
 	defer onDisconnected(err)
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -73,10 +106,16 @@ func (c *ConnectionToAgent) communicate(agent_name string, agent_id string) erro
 		return err
 	}
 
+	tunnel.Kind = "proxy"
 	C_from_agent := tunnel.ChFromAgent
 	C_to_agent := tunnel.ChToAgent
 	defer tunnel.Close()
 
+	var instanceDone <-chan struct{} // nil if not bound to an instance
+	if tunnel.AgentInstance != nil {
+		instanceDone = tunnel.AgentInstance.Ctx.Done()
+	}
+
 	// notify agent
 	if err := tunnel.NotifyAgent(biz.AgentNotify{
 		Type: "pty",
@@ -84,19 +123,32 @@ func (c *ConnectionToAgent) communicate(agent_name string, agent_id string) erro
 		return err
 	}
 
-	// send a ping
+	// say hello and send a ping.
+	// agent replies hello before pong, if it supports. otherwise, it's an old agent without any feature.
 	ping := []byte{0xff, 'h', 'i'}
+	C_to_agent <- []byte{0xfe}
 	C_to_agent <- ping
-	select {
-	case <-c.Ctx.Done():
-		return errors.New("connection aborted by parent context")
-	case <-time.After(time.Second * 5):
-		return errors.New("connection ping timeout")
-	case pong := <-C_from_agent:
-		if !bytes.Equal(ping, pong) {
-			return errors.New("ping failed. got " + hex.EncodeToString(pong))
+	features := map[string]bool{}
+	for pong_received := false; !pong_received; {
+		select {
+		case <-c.Ctx.Done():
+			return errors.New("connection aborted by parent context")
+		case <-time.After(time.Second * 5):
+			return errors.New("connection ping timeout")
+		case pong := <-C_from_agent:
+			if len(pong) >= 1 && pong[0] == 0xfe {
+				for _, f := range strings.Split(string(pong[1:]), ",") {
+					features[f] = true
+				}
+				continue
+			}
+			if !bytes.Equal(ping, pong) {
+				return errors.New("ping failed. got " + hex.EncodeToString(pong))
+			}
+			pong_received = true
 		}
 	}
+	c.features = features
 
 	// ready!
 	c.chanToAgent = C_to_agent
@@ -112,8 +164,26 @@ func (c *ConnectionToAgent) communicate(agent_name string, agent_id string) erro
 	watchdogTicker := time.NewTicker(killInMinutes / 2)
 	defer watchdogTicker.Stop()
 
+	healthTicker := time.NewTicker(healthCheckInterval)
+	defer healthTicker.Stop()
+	healthPending := false
+
 	for {
 		select {
+		case <-healthTicker.C:
+			if healthPending {
+				return errors.New("health check failed")
+			}
+			healthPending = true
+			select {
+			case C_to_agent <- healthPing:
+			case <-c.Ctx.Done():
+				return c.Ctx.Err()
+			}
+
+		case <-instanceDone:
+			return errors.New("agent instance disconnected")
+
 		case <-watchdogTicker.C:
 			if isAboutToClean {
 				return errors.New("watchdog timeout")
@@ -126,16 +196,26 @@ func (c *ConnectionToAgent) communicate(agent_name string, agent_id string) erro
 				return errors.New("tunnel from agent closed")
 			}
 			isAboutToClean = false // reset watchdog on any received data
-			if len(data) >= 1 && data[0] == 0xff {
+			if bytes.Equal(data, healthPing) {
+				healthPending = false
+			} else if len(data) >= 1 && data[0] == 0xff {
 				log.Printf("[agent '%s'] message: %s", agent_name, string(data[1:]))
 			} else if len(data) >= 5 {
 				idBytes := data[1:5]
 				id := binary.LittleEndian.Uint32(idBytes)
-				if ch, ok := c.R.Load(id); ok && ch != nil {
-					ch.(chan []byte) <- data
-				} else {
+				if data[0] == 0x25 {
+					// window update. the channel may be closed already
+					if ch, ok := c.R.Load(id); ok && len(data) >= 9 {
+						ch.(*proxyChannel).sendWindow.Grant(binary.LittleEndian.Uint32(data[5:9]))
+					}
+				} else if ch, ok := c.R.Load(id); !ok {
 					C_to_agent <- utils.JoinBytes2(0x22, idBytes) // close connection
 					log.Printf("[agent '%s'] bad proxy reqId 0x%x with package 0x%x", agent_name, id, data[0])
+				} else if err := c.pushFromAgent(ch.(*proxyChannel), data); err != nil {
+					// closed, or full: an agent overrunning its window
+					ch.(*proxyChannel).fromAgent.Close()
+					C_to_agent <- utils.JoinBytes2(0x22, idBytes) // close connection
+					log.Printf("[agent '%s'] proxy reqId 0x%x dropped package 0x%x: %s", agent_name, id, data[0], err.Error())
 				}
 			}
 
@@ -145,6 +225,56 @@ func (c *ConnectionToAgent) communicate(agent_name string, agent_id string) erro
 	}
 }
 
+// queue a package from agent. an agent without flow control is slowed down by waiting, like older servers did
+func (c *ConnectionToAgent) pushFromAgent(ch *proxyChannel, data []byte) error {
+	if ch.recvWindow == nil {
+		return ch.fromAgent.PushWait(c.Ctx, data)
+	}
+	return ch.fromAgent.Push(data)
+}
+
+// check if connection is ready, without waiting
+func (c *ConnectionToAgent) IsReady() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status == CTAStatusConnected
+}
+
+// check if agent supports a feature. only valid after connected
+func (c *ConnectionToAgent) HasFeature(name string) bool {
+	return c.features[name]
+}
+
+// send data to agent. returns false if disconnected
+func (c *ConnectionToAgent) send(data []byte) bool {
+	select {
+	case c.chanToAgent <- data:
+		return true
+	case <-c.done:
+		return false
+	}
+}
+
+// send a 0x21 data package to agent, waiting for the window of channel.
+// returns false if ctx is done or disconnected
+func (c *ConnectionToAgent) sendData(ctx context.Context, ch *proxyChannel, data ...[]byte) bool {
+	n := 0
+	for _, d := range data {
+		n += len(d)
+	}
+	if !ch.sendWindow.Acquire(ctx, n) {
+		return false
+	}
+	return c.send(utils.JoinBytes2(0x21, append([][]byte{ch.idBytes}, data...)...))
+}
+
+// data from agent is consumed. grant window back to agent if needed
+func (c *ConnectionToAgent) consumed(ch *proxyChannel, n int) {
+	if grant := ch.recvWindow.Consumed(n); grant > 0 {
+		c.send(utils.JoinBytes2(0x25, ch.idBytes, binary.LittleEndian.AppendUint32(nil, grant)))
+	}
+}
+
 // wait and ensure connection ready. if connection closed or failed, it will return an error.
 func (c *ConnectionToAgent) WaitForReady() error {
 	c.mu.Lock()
@@ -185,29 +315,75 @@ func stripHeaders(headers []biz.ProxyHttpHeader) []biz.ProxyHttpHeader {
 // if failed to build a connection, it will return an error, and you shall send "Bad Gateway" response to client when error presents.
 func (c *ConnectionToAgent) HandleRequest(connReq *biz.ProxyHttpRequest, w http.ResponseWriter, r *http.Request) error {
 	id := c.counter.Add(1)
-	idBytes := binary.LittleEndian.AppendUint32(nil, id)
+	c.inflight.Add(1)
+	defer c.inflight.Add(-1)
 
 	log.Printf("[agent '%s'] request %x: %s %s", c.agentName, id, connReq.Method, connReq.URL)
 
 	connReq.Headers = stripHeaders(connReq.Headers)
+	if c.HasFeature("window") {
+		connReq.Window = utils.DefaultWindowSize
+	}
+
+	ch := newProxyChannel(id, connReq.Window)
+	idBytes := ch.idBytes
+	c.R.Store(id, ch)
+	defer c.R.CompareAndDelete(id, ch)
+	defer ch.fromAgent.Close()
 
-	chanToAgent := c.chanToAgent
-	chanFromAgent := make(chan []byte, 5)
-	c.R.Store(id, chanFromAgent)
-	defer c.R.CompareAndDelete(id, chanFromAgent)
+	// stops when client or agent disconnected, or the request is done
+	ctx, stop := context.WithCancel(r.Context())
+	defer stop()
+	go func() {
+		select {
+		case <-c.done:
+			stop()
+		case <-ctx.Done():
+		}
+	}()
 
 	connReqBytes, _ := connReq.MarshalMsg(nil)
-	chanToAgent <- utils.JoinBytes2(0x23, idBytes, connReqBytes)
+	if !c.send(utils.JoinBytes2(0x23, idBytes, connReqBytes)) {
+		return errors.New("agent disconnected")
+	}
 
-	connRes := biz.ProxyHttpResponse{}
-	select { // try to establish a connection on agent
-	case <-c.Ctx.Done():
-		return c.Ctx.Err()
+	if connReq.BodyStream {
+		// agent will read body while sending request to the target,
+		// so the response may come before the body ends
+		http.NewResponseController(w).EnableFullDuplex()
 
-	case <-time.After(time.Second * 60):
-		return errors.New("timeout")
+		bodyDone := make(chan struct{})
+		go func() {
+			defer close(bodyDone)
+			c.streamRequestBody(ctx, ch, r.Body)
+		}()
 
-	case recv := <-chanFromAgent:
+		// net/http forbids reading the body after the handler returns.
+		// Close alone won't interrupt a pending Read of a server request body, so set a read deadline first
+		defer func() {
+			stop()
+			select {
+			case <-bodyDone:
+				return
+			default:
+			}
+			http.NewResponseController(w).SetReadDeadline(time.Now())
+			r.Body.Close()
+			<-bodyDone
+		}()
+	}
+
+	connRes := biz.ProxyHttpResponse{}
+	{ // try to establish a connection on agent
+		dialCtx, cancelDial := context.WithTimeout(ctx, time.Second*60)
+		recv, ok := ch.fromAgent.Pop(dialCtx)
+		cancelDial()
+		if !ok {
+			if ctx.Err() != nil {
+				return errors.New("connection aborted")
+			}
+			return errors.New("timeout")
+		}
 		if recv[0] != 0x23 {
 			return errors.New("bad response: expect 0x23 package")
 		}
@@ -227,38 +403,42 @@ func (c *ConnectionToAgent) HandleRequest(connReq *biz.ProxyHttpRequest, w http.
 			return err
 		}
 
+		// the hijacked connection is not bound to r.Context()
+		ctx, stop := context.WithCancel(c.Ctx)
+		defer stop()
+		go func() {
+			select {
+			case <-c.done:
+				stop()
+			case <-ctx.Done():
+			}
+		}()
+
 		wsConn.SetPingHandler(func(appData string) error {
-			chanToAgent <- (utils.JoinBytes2(0x21, idBytes, []byte{0x09}, []byte(appData)))
+			c.sendData(ctx, ch, []byte{0x09}, []byte(appData))
 			return nil
 		})
 		wsConn.SetPongHandler(func(appData string) error {
-			chanToAgent <- (utils.JoinBytes2(0x21, idBytes, []byte{0x0a}, []byte(appData)))
+			c.sendData(ctx, ch, []byte{0x0a}, []byte(appData))
 			return nil
 		})
 
-		wsConnClosed := make(chan struct{}, 1)
 		wg := sync.WaitGroup{}
 
 		wg.Add(1)
 		go func() { // agent->http
 			defer wg.Done()
+			defer wsConn.Close()
 
 			for {
-				select {
-				case <-wsConnClosed:
-					return
-				case <-c.Ctx.Done():
-					wsConn.Close()
+				recv, ok := ch.fromAgent.Pop(ctx)
+				if !ok || recv[0] == 0x22 {
 					return
-				case recv := <-chanFromAgent:
-					if recv[0] == 0x21 {
-						messageType := int(recv[5])
-						wsConn.WriteMessage(messageType, recv[6:])
-					}
-					if recv[0] == 0x22 {
-						wsConn.Close()
-						return
-					}
+				}
+				if recv[0] == 0x21 && len(recv) >= 6 {
+					messageType := int(recv[5])
+					wsConn.WriteMessage(messageType, recv[6:])
+					c.consumed(ch, len(recv)-5)
 				}
 			}
 		}()
@@ -266,6 +446,7 @@ func (c *ConnectionToAgent) HandleRequest(connReq *biz.ProxyHttpRequest, w http.
 		wg.Add(1)
 		go func() { // http->agent
 			defer wg.Done()
+			defer stop()
 
 			for {
 				messageType, data, err := wsConn.ReadMessage()
@@ -274,12 +455,13 @@ func (c *ConnectionToAgent) HandleRequest(connReq *biz.ProxyHttpRequest, w http.
 					break
 				}
 
-				chanToAgent <- utils.JoinBytes2(0x21, idBytes, []byte{uint8(messageType)}, data)
+				if !c.sendData(ctx, ch, []byte{uint8(messageType)}, data) {
+					return
+				}
 			}
 
 			// client disconnected. shall close the proxy too
-			chanToAgent <- utils.JoinBytes2(0x22, idBytes)
-			wsConnClosed <- struct{}{}
+			c.send(utils.JoinBytes2(0x22, idBytes))
 		}()
 
 		wg.Wait()
@@ -298,36 +480,62 @@ func (c *ConnectionToAgent) HandleRequest(connReq *biz.ProxyHttpRequest, w http.
 
 	{ // write body data
 		for {
-			select {
-			case <-r.Context().Done():
-				// http request disconnected
-				log.Printf("[agent '%s'] request %x aborted by client", c.agentName, id)
-				chanToAgent <- utils.JoinBytes2(0x22, idBytes)
+			data, ok := ch.fromAgent.Pop(ctx)
+			if !ok {
+				if r.Context().Err() != nil {
+					// http request disconnected
+					log.Printf("[agent '%s'] request %x aborted by client", c.agentName, id)
+					c.send(utils.JoinBytes2(0x22, idBytes))
+				}
+				// otherwise, agent disconnected
 				return nil
-			case <-c.Ctx.Done():
-				// agent disconnected
+			}
+			if data[0] == 0x22 {
+				// close connection
 				return nil
-			case data := <-chanFromAgent:
-				if data[0] == 0x22 {
-					// close connection
+			}
+			if data[0] == 0x21 {
+				// data
+				_, err := w.(io.Writer).Write(data[5:])
+				w.(http.Flusher).Flush()
+				if err != nil {
+					// connection closed by client?
+					log.Printf("[agent '%s'] request %x met write error: %s", c.agentName, id, err.Error())
+					c.send(utils.JoinBytes2(0x22, idBytes))
 					return nil
 				}
-				if data[0] == 0x21 {
-					// data
-					_, err := w.(io.Writer).Write(data[5:])
-					w.(http.Flusher).Flush()
-					if err != nil {
-						// connection closed by client?
-						log.Printf("[agent '%s'] request %x met write error: %s", c.agentName, id, err.Error())
-						chanToAgent <- utils.JoinBytes2(0x22, idBytes)
-						return nil
-					}
-				}
+				c.consumed(ch, len(data)-5)
 			}
 		}
 	}
 }
 
+// send request body to agent as 0x21 packages, and a 0x24 package at the end.
+// if failed to read body, send 0x22 to abort the request.
+// it stops when ctx is done (response already finished, don't bother the agent)
+func (c *ConnectionToAgent) streamRequestBody(ctx context.Context, ch *proxyChannel, body io.Reader) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := body.Read(buf)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if n > 0 && !c.sendData(ctx, ch, buf[:n]) {
+			return
+		}
+		if err == io.EOF {
+			c.send(utils.JoinBytes2(0x24, ch.idBytes))
+			return
+		}
+		if err != nil {
+			log.Printf("[agent '%s'] request %x failed to read body: %s", c.agentName, binary.LittleEndian.Uint32(ch.idBytes), err.Error())
+			c.send(utils.JoinBytes2(0x22, ch.idBytes))
+			return
+		}
+	}
+}
+
 var ws = websocket.Upgrader{
 	EnableCompression: true,
 	CheckOrigin: func(r *http.Request) bool {
@@ -0,0 +1,196 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"remote-agent/agent/agent_omni"
+	"remote-agent/biz"
+	"remote-agent/server/agent_handler"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// a ResponseWriter whose Write blocks until released, like a client which stopped reading
+type stalledWriter struct {
+	header  http.Header
+	release chan struct{}
+	written bytes.Buffer
+}
+
+func (w *stalledWriter) Header() http.Header { return w.header }
+func (w *stalledWriter) WriteHeader(int)     {}
+func (w *stalledWriter) Flush()              {}
+func (w *stalledWriter) Write(data []byte) (int, error) {
+	<-w.release
+	return w.written.Write(data)
+}
+
+// serve agent tunnels, and register an agent which runs in this process
+func startTestAgent(t *testing.T) *agent_handler.Agent {
+	tunnelSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/agent/{agent_name}/{token}", agent_handler.HandleAgentTunnelRequest)
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(tunnelSrv.Close)
+
+	biz.Config.BaseUrl = tunnelSrv.URL
+	biz.Config.Name = "test_agent"
+
+	agent := &agent_handler.Agent{Name: biz.Config.Name, Channel: make(chan []byte)}
+	agent_handler.Agents.Store(agent.Name, agent)
+	t.Cleanup(agent.Delete)
+	go runTestAgent(agent.Channel, nil)
+	return agent
+}
+
+// run omni sessions when notified
+func runTestAgent(notifications <-chan []byte, sessions *atomic.Int32) {
+	for msg := range notifications {
+		notify := &biz.AgentNotify{}
+		if _, err := notify.UnmarshalMsg(msg); err == nil {
+			if sessions != nil {
+				sessions.Add(1)
+			}
+			go agent_omni.Run(notify)
+		}
+	}
+}
+
+// start an agent in this process, and connect to it
+func connectToTestAgent(t *testing.T, ctx context.Context) *ConnectionToAgent {
+	agent := startTestAgent(t)
+
+	c := NewConnectionToAgent(ctx)
+	go c.ConnectAndCommunicate(agent.Name, "", func(error) {})
+	if err := c.WaitForReady(); err != nil {
+		t.Fatalf("failed to connect to agent: %s", err.Error())
+	}
+	return c
+}
+
+// a stalled download shall not block other requests on the same connection
+func TestStalledDownload(t *testing.T) {
+	big := bytes.Repeat([]byte("0123456789abcdef"), 4*1024*1024/16)
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/big" {
+			w.Write(big)
+			return
+		}
+		w.Write([]byte("small"))
+	}))
+	defer target.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := connectToTestAgent(t, ctx)
+	Assert(t, c.HasFeature("window"), "agent supports window")
+
+	// 1. start a big download, and never read it
+	stalled := &stalledWriter{header: http.Header{}, release: make(chan struct{})}
+	bigDone := make(chan error, 1)
+	go func() {
+		req := &biz.ProxyHttpRequest{Method: "GET", URL: target.URL + "/big"}
+		bigDone <- c.HandleRequest(req, stalled, httptest.NewRequest("GET", "/big", nil))
+	}()
+	time.Sleep(500 * time.Millisecond) // let agent fill the window
+
+	// 2. small requests still work
+	for i := 0; i < 10; i++ {
+		smallDone := make(chan struct{})
+		recorder := httptest.NewRecorder()
+		go func() {
+			defer close(smallDone)
+			req := &biz.ProxyHttpRequest{Method: "GET", URL: target.URL + "/small"}
+			if err := c.HandleRequest(req, recorder, httptest.NewRequest("GET", "/small", nil)); err != nil {
+				t.Errorf("small request failed: %s", err.Error())
+			}
+		}()
+
+		select {
+		case <-smallDone:
+			Assert(t, recorder.Body.String() == "small", "small response body")
+		case <-time.After(5 * time.Second):
+			t.Fatalf("small request %d blocked by the stalled download", i)
+		}
+	}
+
+	// 3. the stalled download resumes and completes
+	close(stalled.release)
+	select {
+	case err := <-bigDone:
+		Assert(t, err == nil, "big download no error")
+	case <-time.After(10 * time.Second):
+		t.Fatalf("big download timeout")
+	}
+	Assert(t, bytes.Equal(stalled.written.Bytes(), big), "big download body")
+}
+
+// a request body which never ends, and reports reads after the handler returned
+type endlessBody struct {
+	pr       *io.PipeReader
+	returned atomic.Bool
+	lateRead atomic.Bool
+}
+
+func (b *endlessBody) Read(p []byte) (int, error) {
+	if b.returned.Load() {
+		b.lateRead.Store(true)
+	}
+	return b.pr.Read(p)
+}
+
+func (b *endlessBody) Close() error { return b.pr.Close() }
+
+// body streaming must stop before HandleRequest returns, even if the agent fails to dial
+func TestStreamedBodyStopsOnError(t *testing.T) {
+	// a port nobody listens on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadAddr := l.Addr().String()
+	l.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := connectToTestAgent(t, ctx)
+	Assert(t, c.HasFeature("body_stream"), "agent supports body_stream")
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	body := &endlessBody{pr: pr}
+	r := httptest.NewRequest("POST", "/upload", nil)
+	r.Body = body
+	go pw.Write([]byte("partial body"))
+
+	done := make(chan error, 1)
+	go func() {
+		req := &biz.ProxyHttpRequest{Method: "POST", URL: "http://" + deadAddr + "/upload", BodyStream: true}
+		done <- c.HandleRequest(req, httptest.NewRecorder(), r)
+		body.returned.Store(true)
+	}()
+
+	select {
+	case err := <-done:
+		Assert(t, err != nil, "dial error is returned")
+	case <-time.After(10 * time.Second):
+		t.Fatalf("HandleRequest blocked by the request body")
+	}
+
+	_, err = pw.Write([]byte("more"))
+	Assert(t, err == io.ErrClosedPipe, "body is closed")
+	Assert(t, !body.lateRead.Load(), "body is not read after HandleRequest returned")
+}
+
+func Assert(t *testing.T, cond bool, msg string) {
+	t.Helper()
+	if !cond {
+		t.Fatalf("assertion failed: %s", msg)
+	}
+}
@@ -0,0 +1,186 @@
+package proxy
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"remote-agent/biz"
+	"remote-agent/server/agent_handler"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// header choosing the agent of forward proxy. overrides the username of Proxy-Authorization
+const forwardAgentHeader = "X-Agent-Name"
+
+// a forward pool is dropped after this long without requests, and its connections are closed
+var forwardPoolIdle = 5 * time.Minute
+
+type forwardPoolEntry struct {
+	*agentPool
+	cancel   context.CancelFunc
+	lastUsed atomic.Int64 // unix nano
+}
+
+var forwardPools = sync.Map{} // map[string]*forwardPoolEntry, key is agent name
+var forwardSweeper sync.Once
+
+// get the pool of agent, shared by all forward proxy requests.
+// returns nil if the agent is not online, so made-up names don't leave pools behind
+func forwardPool(agentName string) *agentPool {
+	if _, online := agent_handler.Agents.Load(agentName); !online {
+		return nil
+	}
+	forwardSweeper.Do(func() { go sweepForwardPools() })
+
+	e, ok := forwardPools.Load(agentName)
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		var loaded bool
+		e, loaded = forwardPools.LoadOrStore(agentName, &forwardPoolEntry{
+			agentPool: &agentPool{
+				ctx:       ctx,
+				host:      "forward",
+				agentName: agentName,
+				size:      1,
+				balance:   BalanceRoundRobin,
+			},
+			cancel: cancel,
+		})
+		if loaded {
+			cancel()
+		}
+	}
+	e.(*forwardPoolEntry).lastUsed.Store(time.Now().UnixNano())
+	return e.(*forwardPoolEntry).agentPool
+}
+
+func sweepForwardPools() {
+	for range time.Tick(forwardPoolIdle / 5) {
+		evictForwardPools(time.Now().Add(-forwardPoolIdle))
+	}
+}
+
+// close pools not used since `before`, unless requests are in progress
+func evictForwardPools(before time.Time) {
+	forwardPools.Range(func(key, value any) bool {
+		e := value.(*forwardPoolEntry)
+		if e.lastUsed.Load() >= before.UnixNano() {
+			return true
+		}
+		e.mu.Lock()
+		busy := slices.ContainsFunc(e.conns, func(c *ConnectionToAgent) bool { return c.inflight.Load() > 0 })
+		e.mu.Unlock()
+		if !busy && forwardPools.CompareAndDelete(key, e) {
+			e.cancel()
+		}
+		return true
+	})
+}
+
+// serve forward proxy on a dedicated address, like "127.0.0.1:3128"
+func ListenForwardProxy(addr string) error {
+	log.Println("Forward proxy listening on", addr)
+	return http.ListenAndServe(addr, http.HandlerFunc(HandleForwardProxy))
+}
+
+// parse Proxy-Authorization, which is "Basic base64(agent:api_key)" or "Bearer api_key".
+// returns agent name (maybe empty), and if the API key is good
+func forwardProxyAuth(r *http.Request) (agentName string, ok bool) {
+	auth := r.Header.Get("Proxy-Authorization")
+	key := ""
+	if strings.HasPrefix(auth, "Bearer ") {
+		key = auth[7:]
+	} else {
+		fake := &http.Request{Header: http.Header{"Authorization": {auth}}}
+		agentName, key, _ = fake.BasicAuth()
+	}
+	if name := r.Header.Get(forwardAgentHeader); name != "" {
+		agentName = name
+	}
+
+	if biz.Config.APIKey == "" {
+		return agentName, true
+	}
+	return agentName, secureEqual(key, biz.Config.APIKey)
+}
+
+// HTTP forward proxy. CONNECT is tunnelled through a tcp channel to agent,
+// and absolute-URI requests (like "GET http://foo/bar") are sent as http requests to agent.
+func HandleForwardProxy(w http.ResponseWriter, r *http.Request) {
+	agentName, ok := forwardProxyAuth(r)
+	if !ok {
+		w.Header().Set("Proxy-Authenticate", `Basic realm="go-remote-agent"`)
+		http.Error(w, "proxy authentication required", http.StatusProxyAuthRequired)
+		return
+	}
+	if agentName == "" {
+		http.Error(w, "agent name is required, as proxy username or "+forwardAgentHeader+" header", http.StatusBadRequest)
+		return
+	}
+	if r.Method != http.MethodConnect && !r.URL.IsAbs() {
+		http.Error(w, "this is a forward proxy, absolute URI is required", http.StatusBadRequest)
+		return
+	}
+
+	pool := forwardPool(agentName)
+	if pool == nil {
+		http.Error(w, "agent not online: "+agentName, http.StatusBadGateway)
+		return
+	}
+	c, err := pool.pickConnection()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	if r.Method == http.MethodConnect {
+		handleForwardConnect(c, w, r)
+		return
+	}
+
+	header := r.Header.Clone()
+	header.Del("Proxy-Authorization")
+	header.Del("Proxy-Connection")
+	header.Del(forwardAgentHeader)
+	bizRequest, err := newBizRequest(c, r, r.URL.String(), header)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := c.HandleRequest(bizRequest, w, r); err != nil {
+		log.Printf("[forward '%s'] error %s %s: %s", agentName, bizRequest.Method, bizRequest.URL, err.Error())
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	}
+}
+
+func handleForwardConnect(c *ConnectionToAgent, w http.ResponseWriter, r *http.Request) {
+	stream, err := c.OpenStream(r.Context(), "tcp", r.Host)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer stream.Close()
+
+	conn, buf, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		return
+	}
+	// client may send data right after the request, like TLS client hello
+	if n := buf.Reader.Buffered(); n > 0 {
+		data, _ := buf.Reader.Peek(n)
+		if _, err := stream.Write(data); err != nil {
+			return
+		}
+	}
+	pipeConn(c.Ctx, conn, stream)
+}
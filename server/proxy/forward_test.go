@@ -0,0 +1,85 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"remote-agent/biz"
+	"testing"
+	"time"
+)
+
+func TestForwardProxy(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Assert(t, r.Header.Get("Proxy-Authorization") == "", "proxy credentials not forwarded")
+		w.Write([]byte("plain " + r.URL.Path))
+	}))
+	defer target.Close()
+	tlsTarget := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tunnel " + r.URL.Path))
+	}))
+	defer tlsTarget.Close()
+
+	agent := startTestAgent(t)
+	oldKey := biz.Config.APIKey
+	biz.Config.APIKey = "secret"
+	defer func() { biz.Config.APIKey = oldKey }()
+
+	proxySrv := httptest.NewServer(http.HandlerFunc(HandleForwardProxy))
+	defer proxySrv.Close()
+
+	get := func(proxyUser *url.Userinfo, targetURL string) (int, string) {
+		proxyURL, _ := url.Parse(proxySrv.URL)
+		proxyURL.User = proxyUser
+		client := &http.Client{Transport: &http.Transport{
+			Proxy:           http.ProxyURL(proxyURL),
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}
+		defer client.CloseIdleConnections() // ends CONNECT tunnels
+		res, err := client.Get(targetURL)
+		if err != nil {
+			return 0, err.Error()
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return res.StatusCode, string(body)
+	}
+
+	// absolute-URI request
+	status, body := get(url.UserPassword(agent.Name, "secret"), target.URL+"/foo")
+	Assert(t, status == 200 && body == "plain /foo", "plain request: "+body)
+
+	// CONNECT tunnel
+	status, body = get(url.UserPassword(agent.Name, "secret"), tlsTarget.URL+"/bar")
+	Assert(t, status == 200 && body == "tunnel /bar", "connect tunnel: "+body)
+
+	// bad key
+	status, _ = get(url.UserPassword(agent.Name, "wrong"), target.URL+"/foo")
+	Assert(t, status == http.StatusProxyAuthRequired, "bad key rejected")
+	status, _ = get(nil, target.URL+"/foo")
+	Assert(t, status == http.StatusProxyAuthRequired, "no key rejected")
+
+	// unknown agent
+	status, _ = get(url.UserPassword("no_such_agent", "secret"), target.URL+"/foo")
+	Assert(t, status == http.StatusBadGateway, "unknown agent")
+	_, ok := forwardPools.Load("no_such_agent")
+	Assert(t, !ok, "no pool for unknown agent")
+
+	// idle pools are evicted
+	_, ok = forwardPools.Load(agent.Name)
+	Assert(t, ok, "pool of agent")
+	evictForwardPools(time.Now().Add(-time.Minute))
+	_, ok = forwardPools.Load(agent.Name)
+	Assert(t, ok, "recently used pool kept")
+	for i := 0; i < 100 && ok; i++ { // wait for requests to finish on server side
+		evictForwardPools(time.Now().Add(time.Minute))
+		if _, ok = forwardPools.Load(agent.Name); ok {
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+	Assert(t, !ok, "idle pool evicted")
+	status, body = get(url.UserPassword(agent.Name, "secret"), target.URL+"/foo")
+	Assert(t, status == 200 && body == "plain /foo", "pool made again: "+body)
+}
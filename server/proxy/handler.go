@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"remote-agent/biz"
+	"remote-agent/server/events"
 	"sync"
 )
 
@@ -23,12 +24,26 @@ func HandleProxyRequest(w http.ResponseWriter, r *http.Request) {
 }
 
 func RegisterFromConfigFile() {
+	if _, err := parseAllowIPs(biz.Config.TrustedProxies); err != nil {
+		log.Println("bad trusted_proxies:", err)
+		panic(err)
+	}
 	for _, service := range biz.Config.ProxyServices {
 		s := ServiceInfo{
 			Host:        service.Host,
 			AgentName:   service.AgentName,
 			Target:      service.Target,
 			ReplaceHost: service.ReplaceHost,
+			PoolSize:    service.PoolSize,
+			Balance:     service.Balance,
+			Routes:      service.Routes,
+			Auth:        service.Auth,
+			BasicAuth:   service.BasicAuth,
+			AllowIPs:    service.AllowIPs,
+			Rewrite:     service.Rewrite,
+
+			AccessLog:     service.AccessLog,
+			AccessLogFile: service.AccessLogFile,
 		}
 		if err := RegisterService(s); err != nil {
 			log.Println("failed to register service:", s, err)
@@ -38,20 +53,41 @@ func RegisterFromConfigFile() {
 }
 
 func RegisterService(info ServiceInfo) error {
+	if err := info.Check(); err != nil {
+		return err
+	}
+
 	s := Service{
 		ServiceInfo: info,
+		pools:       map[string]*agentPool{},
+		stats:       newServiceStats(),
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 	s.ctx = ctx
 	s.cancel = cancel
+	s.compileRoutes()
+	s.allowIPs, _ = parseAllowIPs(s.AllowIPs)
+
+	if _, existed := ProxyServices.Load(s.Host); existed {
+		cancel()
+		return errors.New("proxy service host already existed")
+	}
+	accessLog, err := newAccessLogger(s.AccessLog, s.AccessLogFile)
+	if err != nil {
+		cancel()
+		return err
+	}
+	s.accessLog = accessLog
 
 	_, existed := ProxyServices.LoadOrStore(s.Host, &s)
 	if existed {
 		cancel()
+		accessLog.Close()
 		return errors.New("proxy service host already existed")
 	}
 
-	log.Printf("register proxy service: %s --[%s]--> %s", s.Host, s.AgentName, s.Target)
+	log.Printf("register proxy service: %s --[%s x%d]--> %s (%d routes, auth: %s)", s.Host, s.AgentName, s.PoolSize, s.Target, len(s.Routes), s.Auth)
+	events.Emit(events.Event{Type: events.ProxyRegister, Agent: s.AgentName, Data: map[string]any{"host": s.Host, "target": s.Target}})
 	return nil
 }
 
@@ -62,6 +98,7 @@ func KillService(host string) error {
 	}
 
 	log.Println("kill proxy service:", host)
+	events.Emit(events.Event{Type: events.ProxyKill, Agent: s.(*Service).AgentName, Data: map[string]any{"host": host}})
 	s.(*Service).Dispose()
 	return nil
 }
@@ -0,0 +1,167 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"remote-agent/server/agent_handler"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	BalanceRoundRobin    = "round_robin"
+	BalanceLeastInflight = "least_inflight"
+)
+
+const maxPoolSize = 32
+
+// check pool options, and fill defaults
+func (info *ServiceInfo) CheckPoolOptions() error {
+	return checkPoolOptions(&info.PoolSize, &info.Balance)
+}
+
+func checkPoolOptions(size *int, balance *string) error {
+	if *size == 0 {
+		*size = 1
+	}
+	if *size < 0 || *size > maxPoolSize {
+		return fmt.Errorf("pool_size must be 1 ~ %d", maxPoolSize)
+	}
+
+	switch *balance {
+	case "":
+		*balance = BalanceRoundRobin
+	case BalanceRoundRobin, BalanceLeastInflight:
+	default:
+		return errors.New("unknown balance: " + *balance)
+	}
+	return nil
+}
+
+// a pool of connections to one agent (or one instance of it)
+type agentPool struct {
+	ctx       context.Context
+	host      string // service host (or listen address of tcp service), for logs
+	agentName string
+	agentId   string // optional
+	size      int
+	balance   string
+
+	mu    sync.Mutex
+	conns []*ConnectionToAgent // filled on demand, and disconnected ones are removed
+	rr    atomic.Uint32        // round robin counter
+}
+
+// get the pool of agent, or make one. pools are shared by routes to the same agent
+func (s *Service) agentPool(agentName, agentId string) *agentPool {
+	key := agentName + "/" + agentId
+	if p, ok := s.pools[key]; ok {
+		return p
+	}
+
+	p := &agentPool{
+		ctx:       s.ctx,
+		host:      s.Host,
+		agentName: agentName,
+		agentId:   agentId,
+		size:      s.PoolSize,
+		balance:   s.Balance,
+	}
+	s.pools[key] = p
+	return p
+}
+
+// fill the pool, then pick a ready connection by p.balance.
+// if no connection is ready, wait for them.
+func (p *agentPool) pickConnection() (*ConnectionToAgent, error) {
+	p.mu.Lock()
+	p.fill()
+	conns := slices.Clone(p.conns)
+	p.mu.Unlock()
+
+	ready := make([]*ConnectionToAgent, 0, len(conns))
+	for _, c := range conns {
+		if c.IsReady() {
+			ready = append(ready, c)
+		}
+	}
+
+	if len(ready) == 0 {
+		var err error
+		for _, c := range conns {
+			if err = c.WaitForReady(); err == nil {
+				return c, nil
+			}
+		}
+		return nil, err
+	}
+
+	if p.balance == BalanceLeastInflight {
+		best := ready[0]
+		for _, c := range ready[1:] {
+			if c.inflight.Load() < best.inflight.Load() {
+				best = c
+			}
+		}
+		return best, nil
+	}
+	return ready[int(p.rr.Add(1)-1)%len(ready)], nil
+}
+
+// (internal) create connections until the pool is full. p.mu must be locked
+func (p *agentPool) fill() {
+	for len(p.conns) < p.size {
+		agentId := p.agentId
+		if agentId == "" {
+			agentId = p.pickInstance()
+		}
+
+		c := NewConnectionToAgent(p.ctx)
+		c.agentId = agentId
+		p.conns = append(p.conns, c)
+		log.Printf("[proxy '%s'] agent connection created (agent: %s, instance: %s)", p.host, p.agentName, agentId)
+
+		go c.ConnectAndCommunicate(p.agentName, agentId, func(connErr error) {
+			log.Printf("[proxy '%s'] agent connection closed (agent: %s, instance: %s): %s", p.host, p.agentName, agentId, connErr.Error())
+
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.conns = slices.DeleteFunc(p.conns, func(x *ConnectionToAgent) bool { return x == c })
+		})
+	}
+}
+
+// (internal) pick the online instance with fewest connections in the pool, so connections are spread across replicas.
+// returns "" if no instance is online, then any instance may pick up the connection.
+// p.mu must be locked
+func (p *agentPool) pickInstance() string {
+	agent_raw, ok := agent_handler.Agents.Load(p.agentName)
+	if !ok {
+		return ""
+	}
+
+	ids := []uint64{}
+	agent_raw.(*agent_handler.Agent).Instances.Range(func(key, value any) bool {
+		ids = append(ids, key.(uint64))
+		return true
+	})
+	slices.Sort(ids)
+
+	count := map[string]int{}
+	for _, c := range p.conns {
+		count[c.agentId]++
+	}
+
+	best := ""
+	for _, id := range ids {
+		agentId := strconv.FormatUint(id, 10)
+		if best == "" || count[agentId] < count[best] {
+			best = agentId
+		}
+	}
+	return best
+}
@@ -0,0 +1,169 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"remote-agent/server/agent_handler"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testInstance struct {
+	sessions   atomic.Int32 // omni sessions started on this instance
+	disconnect context.CancelFunc
+}
+
+// add an instance to agent, like an agent process connected
+func addTestInstance(t *testing.T, agent *agent_handler.Agent, id uint64) *testInstance {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan []byte, 5)
+	agent.Instances.Store(id, &agent_handler.AgentInstance{Id: id, Name: agent.Name, C: ch, Ctx: ctx})
+
+	inst := &testInstance{}
+	inst.disconnect = func() {
+		agent.Instances.Delete(id)
+		cancel()
+	}
+	t.Cleanup(inst.disconnect)
+	go runTestAgent(ch, &inst.sessions)
+	return inst
+}
+
+func startTestService(t *testing.T, info ServiceInfo) *Service {
+	if err := RegisterService(info); err != nil {
+		t.Fatalf("failed to register service: %s", err.Error())
+	}
+	t.Cleanup(func() { KillService(info.Host) })
+	s, _ := ProxyServices.Load(info.Host)
+	return s.(*Service)
+}
+
+// connections in pool of the default target
+func (s *Service) poolSnapshot() []*ConnectionToAgent {
+	p := s.agentPool(s.AgentName, s.AgentId)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*ConnectionToAgent{}, p.conns...)
+}
+
+// wait until pool has n ready connections
+func waitPoolReady(t *testing.T, s *Service, n int) []*ConnectionToAgent {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		conns := s.poolSnapshot()
+		ready := 0
+		for _, c := range conns {
+			if c.IsReady() {
+				ready++
+			}
+		}
+		if len(conns) == n && ready == n {
+			return conns
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("pool not ready: %d connections", len(s.poolSnapshot()))
+	return nil
+}
+
+func doTestRequest(t *testing.T, s *Service, path string) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	s.HandleRequest(recorder, httptest.NewRequest("GET", "http://"+s.Host+path, nil))
+	Assert(t, recorder.Code == http.StatusOK, "status 200, got "+strconv.Itoa(recorder.Code)+" "+recorder.Body.String())
+}
+
+// connections are spread across instances, and instances leaving are replaced
+func TestServicePool(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer target.Close()
+
+	agent := startTestAgent(t)
+	inst1 := addTestInstance(t, agent, 1)
+	inst2 := addTestInstance(t, agent, 2)
+
+	s := startTestService(t, ServiceInfo{Host: "pool.test", AgentName: agent.Name, Target: target.URL, PoolSize: 2})
+	Assert(t, s.Balance == BalanceRoundRobin, "default balance is round robin")
+
+	// 1. one connection on each instance
+	doTestRequest(t, s, "/")
+	conns := waitPoolReady(t, s, 2)
+	Assert(t, inst1.sessions.Load() == 1 && inst2.sessions.Load() == 1, "connections spread across instances")
+	Assert(t, conns[0].agentId != conns[1].agentId, "connections on different instances")
+
+	// 2. round robin
+	before := [2]uint32{conns[0].counter.Load(), conns[1].counter.Load()}
+	for i := 0; i < 4; i++ {
+		doTestRequest(t, s, "/")
+	}
+	Assert(t, conns[0].counter.Load()-before[0] == 2, "round robin to connection 0")
+	Assert(t, conns[1].counter.Load()-before[1] == 2, "round robin to connection 1")
+
+	// 3. instance 1 leaves. its connection is removed, and the pool is refilled with instance 2
+	inst1.disconnect()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.poolSnapshot()) != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	Assert(t, len(s.poolSnapshot()) == 1, "connection of left instance removed")
+
+	doTestRequest(t, s, "/")
+	conns = waitPoolReady(t, s, 2)
+	Assert(t, conns[0].agentId == "2" && conns[1].agentId == "2", "pool refilled with instance 2")
+	Assert(t, inst2.sessions.Load() == 2, "instance 2 has 2 sessions")
+}
+
+// least_inflight avoids the connection which is busy
+func TestServicePoolLeastInflight(t *testing.T) {
+	release := make(chan struct{})
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer target.Close()
+
+	agent := startTestAgent(t)
+	addTestInstance(t, agent, 1)
+
+	s := startTestService(t, ServiceInfo{Host: "least.test", AgentName: agent.Name, Target: target.URL, PoolSize: 2, Balance: BalanceLeastInflight})
+	doTestRequest(t, s, "/")
+	conns := waitPoolReady(t, s, 2)
+
+	slowDone := make(chan struct{})
+	go func() {
+		defer close(slowDone)
+		s.HandleRequest(httptest.NewRecorder(), httptest.NewRequest("GET", "http://"+s.Host+"/slow", nil))
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for conns[0].inflight.Load()+conns[1].inflight.Load() != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	busy, idle := conns[0], conns[1]
+	if idle.inflight.Load() == 1 {
+		busy, idle = idle, busy
+	}
+
+	before := idle.counter.Load()
+	for i := 0; i < 3; i++ {
+		doTestRequest(t, s, "/")
+	}
+	Assert(t, idle.counter.Load()-before == 3, "requests go to the idle connection")
+	Assert(t, busy.inflight.Load() == 1, "busy connection still busy")
+
+	close(release)
+	<-slowDone
+}
+
+func TestServicePoolOptions(t *testing.T) {
+	for _, info := range []ServiceInfo{{PoolSize: -1}, {PoolSize: maxPoolSize + 1}, {Balance: "random"}} {
+		Assert(t, info.CheckPoolOptions() != nil, "invalid pool options rejected")
+	}
+}
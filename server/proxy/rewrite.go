@@ -0,0 +1,256 @@
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"remote-agent/biz"
+	"strconv"
+	"strings"
+)
+
+const defaultBodyLimit = 8 << 20
+
+// check rewrite rules, and fill defaults
+func (info *ServiceInfo) CheckRewrite() error {
+	for _, b := range info.Rewrite.Body {
+		if b.From == "" {
+			return errors.New("rewrite body: from is required")
+		}
+	}
+	if info.Rewrite.BodyLimit < 0 {
+		return errors.New("rewrite body_limit must not be negative")
+	}
+	if info.Rewrite.BodyLimit == 0 {
+		info.Rewrite.BodyLimit = defaultBodyLimit
+	}
+	return nil
+}
+
+func applyHeaderRules(h http.Header, rules biz.HeaderRules) {
+	for _, name := range rules.Remove {
+		h.Del(name)
+	}
+	for name, value := range rules.Set {
+		h.Set(name, value)
+	}
+	for name, value := range rules.Add {
+		h.Add(name, value)
+	}
+}
+
+// whether the request comes from a reverse proxy in `trusted_proxies`, whose X-Forwarded-* and X-Real-IP headers are trusted.
+// no proxy is trusted by default, not even a local one, as any local process could forge them
+func isFromTrustedProxy(r *http.Request) bool {
+	if len(biz.Config.TrustedProxies) == 0 {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	prefixes, _ := parseAllowIPs(biz.Config.TrustedProxies) // checked on start
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// "http" or "https", as the user sees
+func publicScheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+	if isFromTrustedProxy(r) && r.Header.Get("X-Forwarded-Proto") == "https" {
+		return "https"
+	}
+	return "http"
+}
+
+// headers to send to target
+func (s *Service) requestHeaders(r *http.Request) http.Header {
+	h := r.Header.Clone()
+	if s.Rewrite.ForwardedHeaders {
+		if !isFromTrustedProxy(r) {
+			// may be forged by user
+			h.Del("X-Forwarded-For")
+			h.Del("X-Forwarded-Host")
+			h.Del("X-Forwarded-Proto")
+		}
+		if h.Get("X-Forwarded-For") == "" {
+			if ip, ok := clientIP(r); ok {
+				h.Set("X-Forwarded-For", ip.String())
+			}
+		}
+		if h.Get("X-Forwarded-Host") == "" {
+			h.Set("X-Forwarded-Host", r.Host)
+		}
+		if h.Get("X-Forwarded-Proto") == "" {
+			h.Set("X-Forwarded-Proto", publicScheme(r))
+		}
+	}
+	if len(s.Rewrite.Body) > 0 {
+		h.Del("Accept-Encoding") // compressed body can't be substituted
+	}
+	applyHeaderRules(h, s.Rewrite.RequestHeaders)
+	return h
+}
+
+func (s *Service) hasResponseRewrite() bool {
+	rw := &s.Rewrite
+	return rw.Location || rw.CookieDomain || len(rw.Body) > 0 ||
+		len(rw.ResponseHeaders.Remove) > 0 || len(rw.ResponseHeaders.Set) > 0 || len(rw.ResponseHeaders.Add) > 0
+}
+
+// a ResponseWriter which rewrites response headers and body.
+// call finish() after the response is done
+type rewriteWriter struct {
+	http.ResponseWriter
+	s           *Service
+	r           *http.Request
+	targetHosts []string // hostnames of the target, to rewrite in Location
+
+	wroteHeader bool
+	buffering   bool // body is buffered for substitution
+	status      int
+	buf         bytes.Buffer
+}
+
+func (s *Service) newRewriteWriter(w http.ResponseWriter, r *http.Request, route *route) *rewriteWriter {
+	rw := &rewriteWriter{ResponseWriter: w, s: s, r: r}
+	if u, err := url.Parse(route.Target); err == nil {
+		rw.targetHosts = append(rw.targetHosts, strings.ToLower(u.Hostname()))
+	}
+	if s.ReplaceHost != "" {
+		rw.targetHosts = append(rw.targetHosts, strings.ToLower(hostnameOf(s.ReplaceHost)))
+	}
+	return rw
+}
+
+// host without port. IPv6 brackets are removed, like url.URL.Hostname()
+func hostnameOf(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+}
+
+var cookieDomainRegex = regexp.MustCompile(`(?i)(;\s*domain=)[^;]*`)
+
+func (w *rewriteWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	h := w.Header()
+	rules := &w.s.Rewrite
+	publicHost := w.r.Host
+	publicHostname := hostnameOf(publicHost)
+
+	if rules.Location {
+		for _, name := range []string{"Location", "Content-Location"} {
+			u, err := url.Parse(h.Get(name))
+			if err != nil || u.Host == "" {
+				continue // relative url is fine
+			}
+			for _, host := range w.targetHosts {
+				if strings.ToLower(u.Hostname()) == host {
+					u.Scheme = publicScheme(w.r)
+					u.Host = publicHost
+					h.Set(name, u.String())
+					break
+				}
+			}
+		}
+	}
+	if rules.CookieDomain {
+		cookies := h.Values("Set-Cookie")
+		for i, c := range cookies {
+			cookies[i] = cookieDomainRegex.ReplaceAllString(c, "${1}"+publicHostname)
+		}
+	}
+	applyHeaderRules(h, rules.ResponseHeaders)
+
+	if len(rules.Body) > 0 && h.Get("Content-Encoding") == "" && isTextContent(h.Get("Content-Type")) &&
+		status != http.StatusNoContent && status != http.StatusNotModified && w.r.Method != http.MethodHead {
+		w.buffering = true
+		w.status = status
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *rewriteWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buffering {
+		if int64(w.buf.Len()+len(data)) <= w.s.Rewrite.BodyLimit {
+			return w.buf.Write(data)
+		}
+
+		// too big. send as-is
+		w.buffering = false
+		w.ResponseWriter.WriteHeader(w.status)
+		if _, err := w.ResponseWriter.Write(w.buf.Bytes()); err != nil {
+			return 0, err
+		}
+		w.buf = bytes.Buffer{}
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *rewriteWriter) Flush() {
+	if w.buffering {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *rewriteWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// send the buffered body, with substitution
+func (w *rewriteWriter) finish() {
+	if !w.buffering {
+		return
+	}
+	w.buffering = false
+
+	pairs := make([]string, 0, len(w.s.Rewrite.Body)*2)
+	for _, b := range w.s.Rewrite.Body {
+		pairs = append(pairs, b.From, b.To)
+	}
+	body := strings.NewReplacer(pairs...).Replace(w.buf.String())
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write([]byte(body))
+}
+
+func isTextContent(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	if strings.HasPrefix(contentType, "text/") {
+		return true
+	}
+	for _, t := range []string{"json", "javascript", "xml"} {
+		if strings.Contains(contentType, t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"remote-agent/biz"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestServiceRewrite(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			w.Header().Set("Location", "http://internal.local:3000/home")
+			w.Header().Add("Set-Cookie", "sid=1; Domain=internal.local; Path=/; HttpOnly")
+			w.Header().Set("X-Powered-By", "php")
+			w.WriteHeader(http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(strings.Join([]string{
+			"<a href=\"http://internal.local:3000/\">",
+			"xff=" + r.Header.Get("X-Forwarded-For"),
+			"xfh=" + r.Header.Get("X-Forwarded-Host"),
+			"xfp=" + r.Header.Get("X-Forwarded-Proto"),
+			"secret=" + r.Header.Get("X-Secret"),
+		}, "\n")))
+	}))
+	defer target.Close()
+
+	agent := startTestAgent(t)
+	s := startTestService(t, ServiceInfo{
+		Host:        "rewrite.test",
+		AgentName:   agent.Name,
+		Target:      target.URL,
+		ReplaceHost: "internal.local:3000",
+		Rewrite: biz.ProxyRewrite{
+			RequestHeaders:   biz.HeaderRules{Remove: []string{"X-Secret"}},
+			ResponseHeaders:  biz.HeaderRules{Remove: []string{"X-Powered-By"}, Set: map[string]string{"X-Frame-Options": "DENY"}},
+			ForwardedHeaders: true,
+			Location:         true,
+			CookieDomain:     true,
+			Body:             []biz.BodyReplace{{From: "http://internal.local:3000/", To: "https://rewrite.test/"}},
+		},
+	})
+
+	// 1. headers of response
+	recorder := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://rewrite.test/login", nil)
+	r.RemoteAddr = "127.0.0.1:5555"
+	r.Header.Set("X-Forwarded-Proto", "https") // from trusted reverse proxy
+	biz.Config.TrustedProxies = []string{"127.0.0.1"}
+	defer func() { biz.Config.TrustedProxies = nil }()
+	s.HandleRequest(recorder, r)
+
+	h := recorder.Header()
+	Assert(t, recorder.Code == http.StatusFound, "status 302, got "+recorder.Body.String())
+	Assert(t, h.Get("Location") == "https://rewrite.test/home", "location rewritten, got "+h.Get("Location"))
+	Assert(t, h.Get("Set-Cookie") == "sid=1; Domain=rewrite.test; Path=/; HttpOnly", "cookie domain rewritten, got "+h.Get("Set-Cookie"))
+	Assert(t, h.Get("X-Powered-By") == "", "response header removed")
+	Assert(t, h.Get("X-Frame-Options") == "DENY", "response header set")
+
+	// 2. request headers and body
+	recorder = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "http://rewrite.test/", nil)
+	r.RemoteAddr = "1.2.3.4:5555"
+	r.Header.Set("X-Forwarded-For", "6.6.6.6") // forged
+	r.Header.Set("X-Secret", "1")
+	s.HandleRequest(recorder, r)
+
+	body := recorder.Body.String()
+	want := "<a href=\"https://rewrite.test/\">\nxff=1.2.3.4\nxfh=rewrite.test\nxfp=http\nsecret="
+	Assert(t, body == want, "body rewritten, got "+strings.ReplaceAll(body, "\n", " | "))
+	Assert(t, recorder.Header().Get("Content-Length") == strconv.Itoa(len(want)), "content-length updated, got "+recorder.Header().Get("Content-Length"))
+
+	// 3. public host is an IPv6 address
+	recorder = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "http://[::1]:8443/login", nil)
+	s.HandleRequest(recorder, r)
+
+	h = recorder.Header()
+	Assert(t, h.Get("Location") == "http://[::1]:8443/home", "location rewritten, got "+h.Get("Location"))
+	Assert(t, h.Get("Set-Cookie") == "sid=1; Domain=::1; Path=/; HttpOnly", "cookie domain rewritten, got "+h.Get("Set-Cookie"))
+}
+
+func TestHostnameOf(t *testing.T) {
+	for host, want := range map[string]string{
+		"rewrite.test":      "rewrite.test",
+		"rewrite.test:8080": "rewrite.test",
+		"[::1]:8080":        "::1",
+		"[::1]":             "::1",
+		"::1":               "::1",
+	} {
+		Assert(t, hostnameOf(host) == want, host+": got "+hostnameOf(host))
+	}
+}
+
+// redirects are followed by agent, unless the service passes them
+func TestServiceRedirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+		w.Write([]byte("path=" + r.URL.Path))
+	}))
+	defer target.Close()
+
+	agent := startTestAgent(t)
+	for _, pass := range []bool{false, true} {
+		host := "follow.test"
+		if pass {
+			host = "pass.test"
+		}
+		s := startTestService(t, ServiceInfo{
+			Host:      host,
+			AgentName: agent.Name,
+			Target:    target.URL,
+			Rewrite:   biz.ProxyRewrite{PassRedirects: pass},
+		})
+
+		recorder := httptest.NewRecorder()
+		s.HandleRequest(recorder, httptest.NewRequest("GET", "http://"+host+"/old", nil))
+		if pass {
+			Assert(t, recorder.Code == http.StatusFound, "redirect passed, got "+strconv.Itoa(recorder.Code))
+			Assert(t, recorder.Header().Get("Location") == "/new", "location kept, got "+recorder.Header().Get("Location"))
+		} else {
+			Assert(t, recorder.Code == http.StatusOK, "redirect followed, got "+strconv.Itoa(recorder.Code))
+			Assert(t, recorder.Body.String() == "path=/new", "body of new path, got "+recorder.Body.String())
+		}
+	}
+}
+
+// body bigger than limit is sent as-is
+func TestRewriteBodyLimit(t *testing.T) {
+	s := &Service{ServiceInfo: ServiceInfo{Rewrite: biz.ProxyRewrite{Body: []biz.BodyReplace{{From: "a", To: "b"}}, BodyLimit: 4}}}
+	for _, c := range []struct{ body, want string }{
+		{"aaa", "bbb"},
+		{"aaaaaa", "aaaaaa"},
+	} {
+		recorder := httptest.NewRecorder()
+		w := s.newRewriteWriter(recorder, httptest.NewRequest("GET", "/", nil), &route{})
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(c.body[:2]))
+		w.Write([]byte(c.body[2:]))
+		w.finish()
+		Assert(t, recorder.Body.String() == c.want, "body "+c.body+" got "+recorder.Body.String())
+	}
+}
@@ -0,0 +1,147 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"remote-agent/biz"
+	"slices"
+	"strings"
+)
+
+// a compiled biz.ProxyRoute
+type route struct {
+	biz.ProxyRoute
+	re   *regexp.Regexp // nil if matching by Path
+	pool *agentPool
+}
+
+// add "http://" if target has no scheme
+func normalizeTarget(target string) string {
+	target = strings.TrimSpace(target)
+	if target != "" && !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+		target = "http://" + target
+	}
+	return target
+}
+
+// check routes and target, and fill defaults
+func (info *ServiceInfo) CheckRoutes() error {
+	info.Target = normalizeTarget(info.Target)
+	if info.Target == "" && len(info.Routes) == 0 {
+		return errors.New("target or routes is required")
+	}
+	if info.Target != "" && info.AgentName == "" && info.AgentId == "" {
+		return errors.New("agent_id or agent_name is required")
+	}
+
+	for i := range info.Routes {
+		r := &info.Routes[i]
+		if (r.Path == "") == (r.PathRegex == "") {
+			return fmt.Errorf("route %d: one of path and path_regex is required", i)
+		}
+		if r.PathRegex != "" {
+			if _, err := regexp.Compile(r.PathRegex); err != nil {
+				return fmt.Errorf("route %d: %w", i, err)
+			}
+			if r.StripPrefix {
+				return fmt.Errorf("route %d: strip_prefix only works with path", i)
+			}
+		}
+
+		r.Target = normalizeTarget(r.Target)
+		if r.Target == "" {
+			return fmt.Errorf("route %d: target is required", i)
+		}
+		if r.AgentName == "" && r.AgentId == "" {
+			r.AgentName, r.AgentId = info.AgentName, info.AgentId
+		}
+		if r.AgentName == "" && r.AgentId == "" {
+			return fmt.Errorf("route %d: agent_id or agent_name is required", i)
+		}
+		for j := range r.Methods {
+			r.Methods[j] = strings.ToUpper(r.Methods[j])
+		}
+	}
+	return nil
+}
+
+// (internal) compile s.Routes after checked
+func (s *Service) compileRoutes() {
+	s.routes = nil
+	for _, r := range s.Routes {
+		rt := &route{ProxyRoute: r, pool: s.agentPool(r.AgentName, r.AgentId)}
+		if r.PathRegex != "" {
+			rt.re = regexp.MustCompile(r.PathRegex)
+		}
+		s.routes = append(s.routes, rt)
+	}
+
+	if s.Target != "" {
+		s.routes = append(s.routes, &route{
+			ProxyRoute: biz.ProxyRoute{Path: "/", Target: s.Target},
+			pool:       s.agentPool(s.AgentName, s.AgentId),
+		})
+	}
+}
+
+// find the first matched route, and the (rewritten) path to append to its Target.
+// returns nil if no route matched
+func (s *Service) matchRoute(r *http.Request) (*route, string) {
+	path := r.URL.EscapedPath()
+	for _, rt := range s.routes {
+		if len(rt.Methods) > 0 && !slices.Contains(rt.Methods, r.Method) {
+			continue
+		}
+		if !rt.matchHeaders(r.Header) {
+			continue
+		}
+
+		if rt.re != nil {
+			loc := rt.re.FindStringSubmatchIndex(path)
+			if loc == nil {
+				continue
+			}
+			if rt.Rewrite != "" {
+				path = path[:loc[0]] + string(rt.re.ExpandString(nil, rt.Rewrite, path, loc)) + path[loc[1]:]
+			}
+		} else {
+			if !matchPathPrefix(path, rt.Path) {
+				continue
+			}
+			if rt.Rewrite != "" {
+				path = rt.Rewrite + path[len(rt.Path):]
+			} else if rt.StripPrefix {
+				path = path[len(rt.Path):]
+			}
+		}
+
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		return rt, path
+	}
+	return nil, ""
+}
+
+func (rt *route) matchHeaders(header http.Header) bool {
+	for name, want := range rt.Headers {
+		if want == "" {
+			if len(header.Values(name)) == 0 {
+				return false
+			}
+		} else if header.Get(name) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// "/api" matches "/api" and "/api/users", but not "/apix". "/api/" only matches "/api/" and below
+func matchPathPrefix(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	return len(path) == len(prefix) || strings.HasSuffix(prefix, "/") || path[len(prefix)] == '/'
+}
@@ -0,0 +1,115 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"remote-agent/biz"
+	"testing"
+)
+
+func makeRoutedService(t *testing.T, info ServiceInfo) *Service {
+	if err := info.CheckRoutes(); err != nil {
+		t.Fatalf("bad routes: %s", err.Error())
+	}
+	s := &Service{ServiceInfo: info, pools: map[string]*agentPool{}, ctx: context.Background()}
+	s.compileRoutes()
+	return s
+}
+
+func TestMatchRoute(t *testing.T) {
+	s := makeRoutedService(t, ServiceInfo{
+		AgentName: "bot",
+		Target:    "127.0.0.1:3000",
+		Routes: []biz.ProxyRoute{
+			{Path: "/api", StripPrefix: true, Target: "http://127.0.0.1:8080", Methods: []string{"get", "post"}},
+			{Path: "/static/", Rewrite: "/assets/", Target: "http://127.0.0.1:8081", AgentName: "cdn"},
+			{PathRegex: `^/v(\d+)/`, Rewrite: "/api/$1/", Target: "http://127.0.0.1:8082"},
+			{Path: "/admin", Headers: map[string]string{"X-Admin": ""}, Target: "http://127.0.0.1:8083"},
+		},
+	})
+
+	for _, c := range []struct {
+		method, path string
+		header       http.Header
+		target, want string
+	}{
+		{"GET", "/api/users", nil, "http://127.0.0.1:8080", "/users"},
+		{"POST", "/api", nil, "http://127.0.0.1:8080", "/"},
+		{"DELETE", "/api/users", nil, "http://127.0.0.1:3000", "/api/users"},
+		{"GET", "/apix", nil, "http://127.0.0.1:3000", "/apix"},
+		{"GET", "/static/a.js", nil, "http://127.0.0.1:8081", "/assets/a.js"},
+		{"GET", "/v2/users", nil, "http://127.0.0.1:8082", "/api/2/users"},
+		{"GET", "/admin/", nil, "http://127.0.0.1:3000", "/admin/"},
+		{"GET", "/admin/", http.Header{"X-Admin": {"1"}}, "http://127.0.0.1:8083", "/admin/"},
+	} {
+		r := httptest.NewRequest(c.method, c.path, nil)
+		for k, v := range c.header {
+			r.Header[k] = v
+		}
+		rt, path := s.matchRoute(r)
+		if rt == nil || rt.Target != c.target || path != c.want {
+			t.Errorf("%s %s: got %v %q, want %s %q", c.method, c.path, rt, path, c.target, c.want)
+		}
+	}
+
+	Assert(t, len(s.pools) == 2, "routes to the same agent share a pool")
+
+	// without default target
+	s = makeRoutedService(t, ServiceInfo{Routes: []biz.ProxyRoute{{Path: "/api/", Target: "http://127.0.0.1:8080", AgentName: "bot"}}})
+	rt, _ := s.matchRoute(httptest.NewRequest("GET", "/", nil))
+	Assert(t, rt == nil, "no route matched")
+}
+
+func TestCheckRoutes(t *testing.T) {
+	for _, info := range []ServiceInfo{
+		{AgentName: "bot"},
+		{Target: "http://127.0.0.1"},
+		{Routes: []biz.ProxyRoute{{Path: "/", Target: "http://127.0.0.1"}}},
+		{AgentName: "bot", Routes: []biz.ProxyRoute{{Target: "http://127.0.0.1"}}},
+		{AgentName: "bot", Routes: []biz.ProxyRoute{{Path: "/", PathRegex: "/", Target: "http://127.0.0.1"}}},
+		{AgentName: "bot", Routes: []biz.ProxyRoute{{PathRegex: "(", Target: "http://127.0.0.1"}}},
+		{AgentName: "bot", Routes: []biz.ProxyRoute{{PathRegex: "^/a", StripPrefix: true, Target: "http://127.0.0.1"}}},
+		{AgentName: "bot", Routes: []biz.ProxyRoute{{Path: "/"}}},
+	} {
+		Assert(t, info.CheckRoutes() != nil, "invalid routes rejected")
+	}
+
+	// agent_id alone is enough, inherited from service or set by route
+	info := ServiceInfo{AgentId: "42", Routes: []biz.ProxyRoute{
+		{Path: "/a", Target: "http://127.0.0.1"},
+		{Path: "/b", Target: "http://127.0.0.1", AgentId: "7"},
+	}}
+	Assert(t, info.CheckRoutes() == nil, "routes with agent_id accepted")
+	Assert(t, info.Routes[0].AgentId == "42", "route inherits agent_id")
+	Assert(t, info.Routes[1].AgentId == "7", "route keeps its agent_id")
+}
+
+// requests are proxied to the target of matched route
+func TestServiceRoutes(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("api " + r.URL.Path))
+	}))
+	defer api.Close()
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("web " + r.URL.Path))
+	}))
+	defer web.Close()
+
+	agent := startTestAgent(t)
+	s := startTestService(t, ServiceInfo{
+		Host:      "routes.test",
+		AgentName: agent.Name,
+		Target:    web.URL,
+		Routes:    []biz.ProxyRoute{{Path: "/api/", StripPrefix: true, Target: api.URL}},
+	})
+
+	for path, want := range map[string]string{
+		"/api/users": "api /users",
+		"/index":     "web /index",
+	} {
+		recorder := httptest.NewRecorder()
+		s.HandleRequest(recorder, httptest.NewRequest("GET", "http://routes.test"+path, nil))
+		Assert(t, recorder.Body.String() == want, path+" got "+recorder.Body.String())
+	}
+}
@@ -5,11 +5,10 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"remote-agent/biz"
 	"strings"
-	"sync"
-	"sync/atomic"
 )
 
 type ServiceInfo struct {
@@ -17,8 +16,38 @@ type ServiceInfo struct {
 	AgentName string `json:"agent_name"`
 	AgentId   string `json:"agent_id"`
 
-	Target      string `json:"target"`
+	Target      string `json:"target"` // default target, when no route matched. optional if Routes presents
 	ReplaceHost string `json:"replace_host"`
+
+	PoolSize int    `json:"pool_size"` // connections to agent instances. defaults to 1
+	Balance  string `json:"balance"`   // how to pick a connection for each request: "round_robin" (default) or "least_inflight"
+
+	Routes []biz.ProxyRoute `json:"routes"`
+
+	Auth      string   `json:"auth"`      // "none" (default), "basic" or "api_key"
+	BasicAuth []string `json:"-"`         // for "basic" auth. like "user:password"
+	AllowIPs  []string `json:"allow_ips"` // optional. IPs or CIDRs allowed to access, checked before Auth
+
+	Rewrite biz.ProxyRewrite `json:"rewrite"`
+
+	AccessLog     string `json:"access_log"`      // "off" (default), "combined" or "json"
+	AccessLogFile string `json:"access_log_file"` // optional. defaults to server log
+}
+
+// check all options, and fill defaults. RegisterService calls it too
+func (info *ServiceInfo) Check() error {
+	for _, check := range []func() error{
+		info.CheckRoutes,
+		info.CheckPoolOptions,
+		info.CheckAuthOptions,
+		info.CheckRewrite,
+		info.CheckAccessLog,
+	} {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type Service struct {
@@ -26,117 +55,58 @@ type Service struct {
 
 	ctx    context.Context
 	cancel context.CancelFunc
-	connMu sync.Mutex
-	conn   atomic.Pointer[ConnectionToAgent]
+	pools  map[string]*agentPool // key: agentName/agentId. only written when registering
+	routes []*route              // compiled Routes, and the default Target at last
+
+	allowIPs []netip.Prefix // parsed AllowIPs
+
+	stats     *serviceStats
+	accessLog *accessLogger // nil if off
 }
 
-func (s *Service) ensureConnected() (c *ConnectionToAgent, err error) {
-	// Fast path: connection already established and ready.
-	if c = s.conn.Load(); c != nil {
-		if err = c.WaitForReady(); err == nil {
-			return c, nil
-		}
-		s.conn.CompareAndSwap(c, nil)
-	}
+func (s *Service) HandleRequest(w http.ResponseWriter, r *http.Request) {
+	aw := s.newAccessWriter(w, r)
+	defer aw.finish()
+	w = aw
 
-	// Slow path: create a new connection under lock to prevent duplicate goroutines.
-	s.connMu.Lock()
-	defer s.connMu.Unlock()
+	if s.blockIfUnauthorized(w, r) {
+		return
+	}
 
-	// Re-check after acquiring the lock.
-	if c = s.conn.Load(); c != nil {
-		if err = c.WaitForReady(); err == nil {
-			return c, nil
-		}
-		s.conn.CompareAndSwap(c, nil)
+	route, path := s.matchRoute(r)
+	if route == nil {
+		http.Error(w, "no route matched", http.StatusNotFound)
+		return
 	}
 
-	c = NewConnectionToAgent(s.ctx)
-	s.conn.Store(c)
-	log.Printf("[proxy '%s'] agent connection created", s.Host)
-	go c.ConnectAndCommunicate(s.AgentName, s.AgentId, func(connErr error) {
-		log.Printf("[proxy '%s'] agent connection closed: %s", s.Host, connErr.Error())
-		s.conn.CompareAndSwap(c, nil)
-	})
-
-	if err = c.WaitForReady(); err != nil {
-		s.conn.CompareAndSwap(c, nil)
-		return nil, err
+	if s.hasResponseRewrite() && strings.ToLower(r.Header.Get("Upgrade")) != "websocket" {
+		rw := s.newRewriteWriter(w, r, route)
+		defer rw.finish()
+		w = rw
 	}
-	return c, nil
-}
 
-func (s *Service) HandleRequest(w http.ResponseWriter, r *http.Request) {
-	c, err := s.ensureConnected()
+	c, err := route.pool.pickConnection()
 	if err != nil {
 		w.Header().Add("Content-Type", "text/plain")
 		w.WriteHeader(http.StatusBadGateway)
 		w.Write([]byte(err.Error()))
 		return
 	}
+	aw.instance = c.agentId
 
-	bizRequest, err := func() (*biz.ProxyHttpRequest, error) {
-		targetURL := s.Target + r.URL.EscapedPath()
-		if r.URL.RawQuery != "" {
-			targetURL += "?" + r.URL.RawQuery
-		}
-
-		isWebSocket := strings.ToLower(r.Header.Get("Upgrade")) == "websocket"
-		if isWebSocket {
-			// Replace scheme via url.Parse to avoid corrupting hostnames containing "http".
-			parsed, parseErr := url.Parse(targetURL)
-			if parseErr != nil {
-				return nil, parseErr
-			}
-			switch parsed.Scheme {
-			case "http":
-				parsed.Scheme = "ws"
-			case "https":
-				parsed.Scheme = "wss"
-			}
-			targetURL = parsed.String()
-		}
-
-		headers := biz.FromHttpRequestHeaders(r.Header)
-
-		var body []byte
-		if !isWebSocket && r.Body != nil {
-			if r.ContentLength > 0 {
-				bytesRead := int64(0)
-				body = make([]byte, r.ContentLength)
-				for {
-					n, err := r.Body.Read(body[bytesRead:])
-					bytesRead += int64(n)
-					if bytesRead == r.ContentLength {
-						break
-					}
-					if err != nil {
-						return nil, err
-					}
-				}
-			} else {
-				body, err = io.ReadAll(r.Body)
-				if err != nil {
-					return nil, err
-				}
-			}
-		}
-
-		bizRequest := &biz.ProxyHttpRequest{
-			Method:  r.Method,
-			URL:     targetURL,
-			Headers: headers,
-			Host:    s.ReplaceHost,
-			Body:    body,
-		}
-		return bizRequest, nil
-	}()
+	targetURL := route.Target + path
+	if r.URL.RawQuery != "" {
+		targetURL += "?" + r.URL.RawQuery
+	}
+	bizRequest, err := newBizRequest(c, r, targetURL, s.requestHeaders(r))
 	if err != nil {
 		w.Header().Add("Content-Type", "text/plain")
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte(err.Error()))
 		return
 	}
+	bizRequest.Host = s.ReplaceHost
+	bizRequest.NoFollowRedirects = s.Rewrite.PassRedirects || s.Rewrite.Location
 
 	if err := c.HandleRequest(bizRequest, w, r); err != nil {
 		log.Printf("[proxy '%s'] error %s %s: %s", s.Host, bizRequest.Method, bizRequest.URL, err.Error())
@@ -147,7 +117,70 @@ func (s *Service) HandleRequest(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// get stats of service
+func (s *Service) Stats() ServiceStats {
+	return s.stats.Snapshot()
+}
+
 // close the connections to agent
 func (s *Service) Dispose() {
 	s.cancel()
+	s.accessLog.Close()
+}
+
+// make the request sent to agent. targetURL is http(s), and turned into ws(s) for websocket
+func newBizRequest(c *ConnectionToAgent, r *http.Request, targetURL string, header http.Header) (*biz.ProxyHttpRequest, error) {
+	isWebSocket := strings.ToLower(r.Header.Get("Upgrade")) == "websocket"
+	if isWebSocket {
+		// Replace scheme via url.Parse to avoid corrupting hostnames containing "http".
+		parsed, parseErr := url.Parse(targetURL)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		switch parsed.Scheme {
+		case "http":
+			parsed.Scheme = "ws"
+		case "https":
+			parsed.Scheme = "wss"
+		}
+		targetURL = parsed.String()
+	}
+
+	headers := biz.FromHttpRequestHeaders(header)
+
+	var body []byte
+	var err error
+	bodyStream := !isWebSocket && r.ContentLength != 0 && c.HasFeature("body_stream")
+	if !isWebSocket && !bodyStream && r.Body != nil {
+		// old agents can't stream request body. read it into memory
+		if r.ContentLength > 0 {
+			bytesRead := int64(0)
+			body = make([]byte, r.ContentLength)
+			for {
+				n, err := r.Body.Read(body[bytesRead:])
+				bytesRead += int64(n)
+				if bytesRead == r.ContentLength {
+					break
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			body, err = io.ReadAll(r.Body)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	bizRequest := &biz.ProxyHttpRequest{
+		Method:  r.Method,
+		URL:     targetURL,
+		Headers: headers,
+		Body:    body,
+
+		BodyStream: bodyStream,
+	}
+	return bizRequest, nil
 }
@@ -0,0 +1,279 @@
+package proxy
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	AccessLogOff      = "off"
+	AccessLogCombined = "combined"
+	AccessLogJSON     = "json"
+)
+
+// upper bounds of latency histogram buckets, in milliseconds. the last bucket is +Inf
+var latencyBucketsMs = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// check access log options, and fill defaults
+func (info *ServiceInfo) CheckAccessLog() error {
+	switch info.AccessLog {
+	case "":
+		info.AccessLog = AccessLogOff
+	case AccessLogOff, AccessLogCombined, AccessLogJSON:
+	default:
+		return errors.New("unknown access_log: " + info.AccessLog)
+	}
+	if info.AccessLogFile != "" && info.AccessLog == AccessLogOff {
+		return errors.New("access_log_file requires access_log format")
+	}
+	return nil
+}
+
+// counters of a service, since registered
+type ServiceStats struct {
+	Since    time.Time        `json:"since"`
+	Requests int64            `json:"requests"`
+	InFlight int64            `json:"in_flight"`
+	Status   map[string]int64 `json:"status"` // like "2xx", "5xx"
+	BytesIn  int64            `json:"bytes_in"`
+	BytesOut int64            `json:"bytes_out"`
+
+	LatencyBucketsMs []float64 `json:"latency_buckets_ms"` // upper bounds. the last count is for +Inf
+	LatencyCounts    []int64   `json:"latency_counts"`     // requests in each bucket, not cumulative
+	LatencySumMs     float64   `json:"latency_sum_ms"`
+}
+
+type serviceStats struct {
+	mu       sync.Mutex
+	stats    ServiceStats
+	inFlight atomic.Int64
+}
+
+func newServiceStats() *serviceStats {
+	return &serviceStats{stats: ServiceStats{
+		Since:            time.Now(),
+		Status:           map[string]int64{},
+		LatencyBucketsMs: latencyBucketsMs,
+		LatencyCounts:    make([]int64, len(latencyBucketsMs)+1),
+	}}
+}
+
+func (st *serviceStats) record(status int, bytesIn, bytesOut int64, duration time.Duration) {
+	ms := float64(duration) / float64(time.Millisecond)
+	bucket := len(latencyBucketsMs)
+	for i, bound := range latencyBucketsMs {
+		if ms <= bound {
+			bucket = i
+			break
+		}
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.stats.Requests++
+	st.stats.Status[fmt.Sprintf("%dxx", status/100)]++
+	st.stats.BytesIn += bytesIn
+	st.stats.BytesOut += bytesOut
+	st.stats.LatencyCounts[bucket]++
+	st.stats.LatencySumMs += ms
+}
+
+// a copy of current stats
+func (st *serviceStats) Snapshot() ServiceStats {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	ans := st.stats
+	ans.InFlight = st.inFlight.Load()
+	ans.Status = make(map[string]int64, len(st.stats.Status))
+	for k, v := range st.stats.Status {
+		ans.Status[k] = v
+	}
+	ans.LatencyCounts = append([]int64{}, st.stats.LatencyCounts...)
+	return ans
+}
+
+// an entry of access log
+type AccessLogEntry struct {
+	Time       time.Time `json:"time"`
+	Host       string    `json:"host"`
+	ClientIP   string    `json:"client_ip"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Proto      string    `json:"proto"`
+	Status     int       `json:"status"`
+	BytesIn    int64     `json:"bytes_in"`
+	BytesOut   int64     `json:"bytes_out"`
+	DurationMs float64   `json:"duration_ms"`
+	Referer    string    `json:"referer,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	Instance   string    `json:"instance,omitempty"` // agent instance id, if known
+}
+
+// writes access logs of a service in its format
+type accessLogger struct {
+	mu     sync.Mutex
+	format string
+	out    io.Writer
+	file   *os.File // nil if writing to log output
+}
+
+func newAccessLogger(format, file string) (*accessLogger, error) {
+	if format == AccessLogOff {
+		return nil, nil
+	}
+	l := &accessLogger{format: format, out: log.Writer()}
+	if file != "" {
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+		l.out, l.file = f, f
+	}
+	return l, nil
+}
+
+func (l *accessLogger) write(e *AccessLogEntry) {
+	var line []byte
+	if l.format == AccessLogJSON {
+		line, _ = json.Marshal(e)
+	} else {
+		// NCSA combined, with duration and instance appended
+		line = fmt.Appendf(nil, `%s - - [%s] "%s %s %s" %d %d "%s" "%s" %.3f %s`,
+			e.ClientIP, e.Time.Format("02/Jan/2006:15:04:05 -0700"), e.Method, e.Path, e.Proto,
+			e.Status, e.BytesOut, orDash(e.Referer), orDash(e.UserAgent), e.DurationMs/1000, orDash(e.Instance))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(append(line, '\n'))
+}
+
+func (l *accessLogger) Close() {
+	if l != nil && l.file != nil {
+		l.file.Close()
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
+
+// a ResponseWriter which records status and bytes, for access logs and stats.
+// call finish() when the request is done
+type accessWriter struct {
+	http.ResponseWriter
+	s        *Service
+	r        *http.Request
+	start    time.Time
+	body     *countingReader
+	status   int
+	bytes    int64
+	instance string // agent instance id
+}
+
+type countingReader struct {
+	io.ReadCloser
+	n atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+func (s *Service) newAccessWriter(w http.ResponseWriter, r *http.Request) *accessWriter {
+	s.stats.inFlight.Add(1)
+	aw := &accessWriter{ResponseWriter: w, s: s, r: r, start: time.Now()}
+	if r.Body != nil {
+		aw.body = &countingReader{ReadCloser: r.Body}
+		r.Body = aw.body
+	}
+	return aw
+}
+
+func (w *accessWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(data)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *accessWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *accessWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, brw, err
+}
+
+func (w *accessWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *accessWriter) finish() {
+	s := w.s
+	s.stats.inFlight.Add(-1)
+
+	duration := time.Since(w.start)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	bytesIn := int64(0)
+	if w.body != nil {
+		bytesIn = w.body.n.Load()
+	}
+	s.stats.record(w.status, bytesIn, w.bytes, duration)
+
+	if s.accessLog == nil {
+		return
+	}
+	e := &AccessLogEntry{
+		Time:       w.start,
+		Host:       s.Host,
+		Method:     w.r.Method,
+		Path:       w.r.URL.RequestURI(),
+		Proto:      w.r.Proto,
+		Status:     w.status,
+		BytesIn:    bytesIn,
+		BytesOut:   w.bytes,
+		DurationMs: float64(duration) / float64(time.Millisecond),
+		Referer:    w.r.Referer(),
+		UserAgent:  w.r.UserAgent(),
+		Instance:   w.instance,
+	}
+	if ip, ok := clientIP(w.r); ok {
+		e.ClientIP = ip.String()
+	}
+	s.accessLog.write(e)
+}
@@ -0,0 +1,76 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServiceStats(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("hello"))
+	}))
+	defer target.Close()
+
+	logFile := filepath.Join(t.TempDir(), "access.log")
+	agent := startTestAgent(t)
+	s := startTestService(t, ServiceInfo{
+		Host:          "stats.test",
+		AgentName:     agent.Name,
+		Target:        target.URL,
+		AccessLog:     AccessLogJSON,
+		AccessLogFile: logFile,
+	})
+
+	for _, path := range []string{"/", "/", "/missing"} {
+		r := httptest.NewRequest("POST", "http://stats.test"+path, strings.NewReader("abc"))
+		r.RemoteAddr = "1.2.3.4:5555"
+		s.HandleRequest(httptest.NewRecorder(), r)
+	}
+
+	stats := s.Stats()
+	Assert(t, stats.Requests == 3, "requests counted")
+	Assert(t, stats.InFlight == 0, "no request in flight")
+	Assert(t, stats.Status["2xx"] == 2 && stats.Status["4xx"] == 1, "status counted")
+	Assert(t, stats.BytesIn == 9, "bytes in counted")
+	Assert(t, stats.BytesOut == 10+int64(len("404 page not found\n")), "bytes out counted")
+	total := int64(0)
+	for _, n := range stats.LatencyCounts {
+		total += n
+	}
+	Assert(t, total == 3 && len(stats.LatencyCounts) == len(stats.LatencyBucketsMs)+1, "latency histogram")
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("failed to read access log: %s", err.Error())
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	Assert(t, len(lines) == 3, "3 access log lines")
+
+	e := AccessLogEntry{}
+	if err := json.Unmarshal([]byte(lines[2]), &e); err != nil {
+		t.Fatalf("bad access log: %s", lines[2])
+	}
+	Assert(t, e.Method == "POST" && e.Path == "/missing" && e.Status == 404, "access log request")
+	Assert(t, e.ClientIP == "1.2.3.4" && e.BytesIn == 3 && e.Host == "stats.test", "access log details")
+	Assert(t, e.Instance == "", "no instance when agent has none")
+}
+
+func TestAccessLogCombined(t *testing.T) {
+	out := &strings.Builder{}
+	l := &accessLogger{format: AccessLogCombined, out: out}
+	l.write(&AccessLogEntry{
+		ClientIP: "1.2.3.4", Method: "GET", Path: "/a?b=1", Proto: "HTTP/1.1", Status: 200, BytesOut: 5,
+		UserAgent: `curl "x"`, DurationMs: 12, Instance: "7",
+	})
+	want := `1.2.3.4 - - [01/Jan/0001:00:00:00 +0000] "GET /a?b=1 HTTP/1.1" 200 5 "-" "curl \"x\"" 0.012 7` + "\n"
+	Assert(t, out.String() == want, "combined log, got "+out.String())
+}
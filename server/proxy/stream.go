@@ -0,0 +1,251 @@
+package proxy
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"remote-agent/biz"
+	"remote-agent/utils"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// how long to wait for agent to dial the target
+const streamDialTimeout = 30 * time.Second
+
+// a stream channel to agent, works like a connection dialed by agent
+type agentStream struct {
+	c       *ConnectionToAgent
+	ch      *proxyChannel
+	id      uint32
+	network string
+	address string
+
+	ctx    context.Context // done when closed or agent disconnected
+	cancel context.CancelFunc
+
+	pending []byte // rest of last package from agent
+	eof     bool   // agent closed the channel
+
+	mu            sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+	writeClosed   bool
+	closeOnce     sync.Once
+}
+
+// ask agent to dial address, and make a stream channel.
+// network is "tcp", or others if agent supports "window" feature (0x26)
+func (c *ConnectionToAgent) OpenStream(ctx context.Context, network, address string) (net.Conn, error) {
+	id := c.counter.Add(1)
+
+	var window uint32
+	if c.HasFeature("window") {
+		window = utils.DefaultWindowSize
+	}
+	ch := newProxyChannel(id, window)
+	c.R.Store(id, ch)
+
+	fail := func(err error) (net.Conn, error) {
+		c.R.CompareAndDelete(id, ch)
+		ch.fromAgent.Close()
+		return nil, err
+	}
+
+	if window > 0 {
+		req := biz.ProxyOpenRequest{Network: network, Address: address, Window: window}
+		reqBytes, _ := req.MarshalMsg(nil)
+		if !c.send(utils.JoinBytes2(0x26, ch.idBytes, reqBytes)) {
+			return fail(errors.New("agent disconnected"))
+		}
+	} else {
+		if network != "tcp" {
+			return fail(errors.New("agent doesn't support network: " + network))
+		}
+		host, portStr, err := net.SplitHostPort(address)
+		if err != nil {
+			return fail(err)
+		}
+		port, err := strconv.ParseUint(portStr, 10, 16)
+		if err != nil {
+			return fail(errors.New("invalid port: " + portStr))
+		}
+		if !c.send(utils.JoinBytes2(0x20, ch.idBytes, binary.LittleEndian.AppendUint16(nil, uint16(port)), []byte(host))) {
+			return fail(errors.New("agent disconnected"))
+		}
+	}
+
+	// wait for dial result: [0x20][id:4][errCode:1][message]
+	dialCtx, cancelDial := context.WithTimeout(ctx, streamDialTimeout)
+	defer cancelDial()
+	go func() {
+		select {
+		case <-c.done:
+			cancelDial()
+		case <-dialCtx.Done():
+		}
+	}()
+	recv, ok := ch.fromAgent.Pop(dialCtx)
+	if !ok {
+		c.send(utils.JoinBytes2(0x22, ch.idBytes))
+		return fail(errors.New("dial timeout"))
+	}
+	if recv[0] != 0x20 || len(recv) < 6 {
+		c.send(utils.JoinBytes2(0x22, ch.idBytes))
+		return fail(errors.New("bad response: expect 0x20 package"))
+	}
+	if recv[5] != 0x00 {
+		return fail(errors.New("dial error: " + string(recv[6:])))
+	}
+
+	s := &agentStream{c: c, ch: ch, id: id, network: network, address: address}
+	s.ctx, s.cancel = context.WithCancel(c.Ctx)
+	go func() {
+		select {
+		case <-c.done:
+			s.cancel()
+		case <-s.ctx.Done():
+		}
+	}()
+	c.inflight.Add(1)
+	return s, nil
+}
+
+func (s *agentStream) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		if s.eof {
+			return 0, io.EOF
+		}
+
+		s.mu.Lock()
+		deadline := s.readDeadline
+		s.mu.Unlock()
+		ctx, cancel := s.ctx, context.CancelFunc(func() {})
+		if !deadline.IsZero() {
+			ctx, cancel = context.WithDeadline(s.ctx, deadline)
+		}
+
+		data, ok := s.ch.fromAgent.Pop(ctx)
+		cancel()
+		if !ok {
+			if s.ctx.Err() == nil && ctx.Err() != nil {
+				return 0, os.ErrDeadlineExceeded
+			}
+			if s.ctx.Err() != nil {
+				return 0, net.ErrClosed
+			}
+			s.eof = true
+			continue
+		}
+		switch data[0] {
+		case 0x21:
+			s.pending = data[5:]
+			s.c.consumed(s.ch, len(s.pending))
+		case 0x22:
+			s.eof = true
+		}
+	}
+
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+func (s *agentStream) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	deadline, writeClosed := s.writeDeadline, s.writeClosed
+	s.mu.Unlock()
+	if writeClosed {
+		return 0, net.ErrClosed
+	}
+	ctx, cancel := s.ctx, context.CancelFunc(func() {})
+	if !deadline.IsZero() {
+		ctx, cancel = context.WithDeadline(s.ctx, deadline)
+	}
+	defer cancel()
+
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), 32*1024)]
+		if ctx.Err() != nil || !s.c.sendData(ctx, s.ch, chunk) {
+			if s.ctx.Err() == nil && ctx.Err() != nil {
+				return written, os.ErrDeadlineExceeded
+			}
+			return written, net.ErrClosed
+		}
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+// tell agent no more data will be written, and keep reading.
+// needs "half_close" feature of agent. udp has no half-close
+func (s *agentStream) CloseWrite() error {
+	if s.network == "udp" || !s.c.HasFeature("half_close") {
+		return errors.ErrUnsupported
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.writeClosed {
+		return nil
+	}
+	s.writeClosed = true
+	if s.ctx.Err() != nil || !s.c.send(utils.JoinBytes2(0x24, s.ch.idBytes)) {
+		return net.ErrClosed
+	}
+	return nil
+}
+
+func (s *agentStream) Close() error {
+	s.closeOnce.Do(func() {
+		s.c.inflight.Add(-1)
+		s.c.R.CompareAndDelete(s.id, s.ch)
+		s.ch.fromAgent.Close()
+		if s.ctx.Err() == nil {
+			s.c.send(utils.JoinBytes2(0x22, s.ch.idBytes))
+		}
+		s.cancel()
+	})
+	return nil
+}
+
+type streamAddr struct {
+	network string
+	address string
+}
+
+func (a streamAddr) Network() string { return a.network }
+func (a streamAddr) String() string  { return a.address }
+
+func (s *agentStream) LocalAddr() net.Addr {
+	return streamAddr{network: "agent", address: s.c.agentName}
+}
+
+func (s *agentStream) RemoteAddr() net.Addr {
+	return streamAddr{network: s.network, address: s.address}
+}
+
+func (s *agentStream) SetDeadline(t time.Time) error {
+	s.SetReadDeadline(t)
+	return s.SetWriteDeadline(t)
+}
+
+func (s *agentStream) SetReadDeadline(t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readDeadline = t
+	return nil
+}
+
+// a write waits for the window, so it may block until agent consumes data
+func (s *agentStream) SetWriteDeadline(t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writeDeadline = t
+	return nil
+}
@@ -0,0 +1,227 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/netip"
+	"remote-agent/biz"
+	"remote-agent/server/events"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+var TcpServices = sync.Map{} // map[string]*TcpService, key is Listen
+
+type TcpServiceInfo struct {
+	Listen    string `json:"listen"` // like ":15432" or "127.0.0.1:15432". a bare port means all interfaces
+	AgentName string `json:"agent_name"`
+	AgentId   string `json:"agent_id"`
+	Target    string `json:"target"` // dialed by agent, like "127.0.0.1:5432"
+
+	PoolSize int    `json:"pool_size"` // connections to agent instances. defaults to 1
+	Balance  string `json:"balance"`   // "round_robin" (default) or "least_inflight"
+
+	AllowIPs []string `json:"allow_ips"` // optional. IPs or CIDRs allowed to connect
+}
+
+// a server port forwarded to an agent
+type TcpService struct {
+	TcpServiceInfo
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	listener net.Listener
+	pool     *agentPool
+	allowIPs []netip.Prefix
+
+	Active atomic.Int32 // connections in progress
+}
+
+// check options, and fill defaults
+func (info *TcpServiceInfo) Check() error {
+	if info.Listen == "" {
+		return errors.New("listen is required")
+	}
+	if _, err := strconv.ParseUint(info.Listen, 10, 16); err == nil {
+		info.Listen = ":" + info.Listen
+	}
+	if _, _, err := net.SplitHostPort(info.Listen); err != nil {
+		return errors.New("invalid listen: " + err.Error())
+	}
+
+	if info.AgentName == "" && info.AgentId == "" {
+		return errors.New("agent_id or agent_name is required")
+	}
+	if _, port, err := net.SplitHostPort(info.Target); err != nil || port == "" {
+		return errors.New("invalid target, expect host:port")
+	}
+	if _, err := parseAllowIPs(info.AllowIPs); err != nil {
+		return err
+	}
+	return checkPoolOptions(&info.PoolSize, &info.Balance)
+}
+
+func RegisterTcpFromConfigFile() {
+	for _, service := range biz.Config.TcpServices {
+		s := TcpServiceInfo{
+			Listen:    service.Listen,
+			AgentName: service.AgentName,
+			Target:    service.Target,
+			PoolSize:  service.PoolSize,
+			Balance:   service.Balance,
+			AllowIPs:  service.AllowIPs,
+		}
+		if _, err := RegisterTcpService(s); err != nil {
+			log.Println("failed to register tcp service:", s, err)
+			panic(err)
+		}
+	}
+}
+
+// start listening, and forward connections to agent
+func RegisterTcpService(info TcpServiceInfo) (*TcpService, error) {
+	if err := info.Check(); err != nil {
+		return nil, err
+	}
+	if _, existed := TcpServices.Load(info.Listen); existed {
+		return nil, errors.New("tcp service already existed")
+	}
+
+	listener, err := net.Listen("tcp", info.Listen)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &TcpService{TcpServiceInfo: info, listener: listener}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.allowIPs, _ = parseAllowIPs(s.AllowIPs)
+	s.pool = &agentPool{
+		ctx:       s.ctx,
+		host:      "tcp " + s.Listen,
+		agentName: s.AgentName,
+		agentId:   s.AgentId,
+		size:      s.PoolSize,
+		balance:   s.Balance,
+	}
+
+	if _, existed := TcpServices.LoadOrStore(s.Listen, s); existed {
+		s.Dispose()
+		return nil, errors.New("tcp service already existed")
+	}
+
+	log.Printf("register tcp service: %s --[%s x%d]--> %s", s.Listen, s.AgentName, s.PoolSize, s.Target)
+	events.Emit(events.Event{Type: events.TcpRegister, Agent: s.AgentName, Data: map[string]any{"listen": s.Listen, "target": s.Target}})
+	go s.serve()
+	return s, nil
+}
+
+func KillTcpService(listen string) error {
+	if _, err := strconv.ParseUint(listen, 10, 16); err == nil {
+		listen = ":" + listen
+	}
+	s, ok := TcpServices.LoadAndDelete(listen)
+	if !ok {
+		return errors.New("service not found")
+	}
+
+	log.Println("kill tcp service:", listen)
+	events.Emit(events.Event{Type: events.TcpKill, Agent: s.(*TcpService).AgentName, Data: map[string]any{"listen": listen}})
+	s.(*TcpService).Dispose()
+	return nil
+}
+
+// the address actually listening on. useful when Listen port is 0
+func (s *TcpService) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// stop listening, and close all connections
+func (s *TcpService) Dispose() {
+	s.cancel()
+	s.listener.Close()
+}
+
+func (s *TcpService) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if s.ctx.Err() == nil {
+				log.Printf("[tcp '%s'] accept error: %s", s.Listen, err.Error())
+			}
+			return
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *TcpService) isAllowed(conn net.Conn) bool {
+	if len(s.allowIPs) == 0 {
+		return true
+	}
+	addrPort, err := netip.ParseAddrPort(conn.RemoteAddr().String())
+	if err != nil {
+		return false
+	}
+	addr := addrPort.Addr().Unmap()
+	for _, prefix := range s.allowIPs {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *TcpService) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	if !s.isAllowed(conn) {
+		log.Printf("[tcp '%s'] rejected %s", s.Listen, conn.RemoteAddr().String())
+		return
+	}
+
+	s.Active.Add(1)
+	defer s.Active.Add(-1)
+
+	c, err := s.pool.pickConnection()
+	if err != nil {
+		log.Printf("[tcp '%s'] no agent connection: %s", s.Listen, err.Error())
+		return
+	}
+	stream, err := c.OpenStream(s.ctx, "tcp", s.Target)
+	if err != nil {
+		log.Printf("[tcp '%s'] failed to open %s: %s", s.Listen, s.Target, err.Error())
+		return
+	}
+	defer stream.Close()
+
+	pipeConn(s.ctx, conn, stream)
+}
+
+// copy data between two connections, until both directions end, or ctx is done
+func pipeConn(ctx context.Context, a, b net.Conn) {
+	done := make(chan struct{}, 2)
+	halfClose := func(dst, src net.Conn) {
+		io.Copy(dst, src)
+		// pass the half-close on, so the response is not lost. close dst if it can't
+		if cw, ok := dst.(interface{ CloseWrite() error }); !ok || cw.CloseWrite() != nil {
+			dst.Close()
+		}
+		done <- struct{}{}
+	}
+	go halfClose(a, b)
+	go halfClose(b, a)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			a.Close()
+			b.Close()
+			return
+		}
+	}
+}
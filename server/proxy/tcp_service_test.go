@@ -0,0 +1,133 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// a tcp server which echoes everything back
+func startEchoServer(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestOpenStream(t *testing.T) {
+	echoAddr := startEchoServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := connectToTestAgent(t, ctx)
+
+	stream, err := c.OpenStream(ctx, "tcp", echoAddr)
+	if err != nil {
+		t.Fatalf("failed to open stream: %s", err.Error())
+	}
+	defer stream.Close()
+
+	// bigger than the window, so grants must flow back
+	big := bytes.Repeat([]byte("0123456789abcdef"), 2*1024*1024/16)
+	go stream.Write(big)
+	received := make([]byte, len(big))
+	stream.SetReadDeadline(time.Now().Add(10 * time.Second))
+	_, err = io.ReadFull(stream, received)
+	Assert(t, err == nil, "read echo")
+	Assert(t, bytes.Equal(received, big), "echo matches")
+
+	// read deadline
+	stream.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, err = stream.Read(make([]byte, 1))
+	Assert(t, err != nil && err.(net.Error).Timeout(), "read deadline exceeded")
+
+	// write deadline
+	stream.SetWriteDeadline(time.Now().Add(-time.Second))
+	_, err = stream.Write([]byte("late"))
+	Assert(t, err != nil && err.(net.Error).Timeout(), "write deadline exceeded")
+
+	// dial error
+	_, err = c.OpenStream(ctx, "tcp", "127.0.0.1:1")
+	Assert(t, err != nil, "dial error reported")
+}
+
+func TestTcpService(t *testing.T) {
+	echoAddr := startEchoServer(t)
+	agent := startTestAgent(t)
+
+	s, err := RegisterTcpService(TcpServiceInfo{Listen: "127.0.0.1:0", AgentName: agent.Name, Target: echoAddr})
+	if err != nil {
+		t.Fatalf("failed to register: %s", err.Error())
+	}
+	defer KillTcpService(s.Listen)
+
+	for i := 0; i < 3; i++ {
+		conn, err := net.Dial("tcp", s.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+		conn.Write([]byte("hello"))
+		buf := make([]byte, 5)
+		_, err = io.ReadFull(conn, buf)
+		Assert(t, err == nil && string(buf) == "hello", "echo through tcp service")
+		conn.Close()
+	}
+
+	// half-close: echo server replies everything, then closes
+	conn, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	conn.Write([]byte("bye"))
+	conn.(*net.TCPConn).CloseWrite()
+	received, err := io.ReadAll(conn)
+	Assert(t, err == nil && string(received) == "bye", "response after half-close, got "+string(received))
+	conn.Close()
+
+	// options
+	Assert(t, (&TcpServiceInfo{Listen: "15432", AgentName: "a", Target: "db"}).Check() != nil, "target needs port")
+	info := TcpServiceInfo{Listen: "15432", AgentName: "a", Target: "db:5432"}
+	Assert(t, info.Check() == nil && info.Listen == ":15432" && info.PoolSize == 1, "listen port expanded")
+	Assert(t, (&TcpServiceInfo{Listen: "15432", AgentId: "3", Target: "db:5432"}).Check() == nil, "agent_id without agent_name")
+	Assert(t, (&TcpServiceInfo{Listen: "15432", Target: "db:5432"}).Check() != nil, "agent_name or agent_id required")
+}
+
+func TestTcpServiceAllowIPs(t *testing.T) {
+	echoAddr := startEchoServer(t)
+	agent := startTestAgent(t)
+
+	s, err := RegisterTcpService(TcpServiceInfo{Listen: "127.0.0.1:0", AgentName: agent.Name, Target: echoAddr, AllowIPs: []string{"10.0.0.0/8"}})
+	if err != nil {
+		t.Fatalf("failed to register: %s", err.Error())
+	}
+	defer KillTcpService(s.Listen)
+
+	conn, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	Assert(t, err == io.EOF, "connection rejected")
+}
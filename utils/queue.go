@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+const (
+	// byte limit of a stream channel's queue. a flow-controlled peer stays within its window,
+	// so only a misbehaving one can reach it. peers without flow control are slowed down by PushWait instead
+	StreamQueueLimit = 16 * DefaultWindowSize
+
+	// byte limit of datagrams queued for a UDP peer. datagrams beyond it are dropped
+	DatagramQueueLimit = 1024 * 1024
+)
+
+var (
+	ErrQueueClosed = errors.New("queue is closed")
+	ErrQueueFull   = errors.New("queue is full")
+)
+
+// ByteQueue is a FIFO of byte slices, bounded by total bytes.
+//
+// Push never blocks, so it is safe to call from a read loop which must not be stalled by a slow consumer.
+// PushWait blocks while the queue is full, for peers which can't be told to slow down otherwise.
+// It is designed for one consumer.
+type ByteQueue struct {
+	mu     sync.Mutex
+	items  [][]byte
+	size   int // total bytes of items
+	limit  int
+	closed bool
+	notify chan struct{} // has a value when items or closed changed
+	space  chan struct{} // closed when an item is popped or queue closed. nil if nobody waits
+}
+
+// make a queue which holds at most `limit` bytes. an item is always accepted by an empty queue
+func NewByteQueue(limit int) *ByteQueue {
+	return &ByteQueue{limit: limit, notify: make(chan struct{}, 1)}
+}
+
+// append data to queue. never blocks.
+// returns ErrQueueClosed if queue is closed, or ErrQueueFull if data exceeds the limit. in both cases data is dropped
+func (q *ByteQueue) Push(data []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+	if len(q.items) > 0 && q.size+len(data) > q.limit {
+		return ErrQueueFull
+	}
+	q.items = append(q.items, data)
+	q.size += len(data)
+	q.wake()
+	return nil
+}
+
+// append data to queue, waiting while it is full, so a slow consumer slows down the caller like a blocking channel.
+// returns ErrQueueClosed if queue is closed, or ctx.Err() if ctx is done first. in both cases data is dropped
+func (q *ByteQueue) PushWait(ctx context.Context, data []byte) error {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return ErrQueueClosed
+		}
+		if len(q.items) == 0 || q.size+len(data) <= q.limit {
+			q.items = append(q.items, data)
+			q.size += len(data)
+			q.wake()
+			q.mu.Unlock()
+			return nil
+		}
+		if q.space == nil {
+			q.space = make(chan struct{})
+		}
+		space := q.space
+		q.mu.Unlock()
+
+		select {
+		case <-space:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// close the queue. items already pushed can still be popped. safe to call multiple times
+func (q *ByteQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.wake()
+	q.wakeSpace()
+}
+
+// wait and take the first item. returns false if queue is closed and drained, or ctx is done
+func (q *ByteQueue) Pop(ctx context.Context) (data []byte, ok bool) {
+	for {
+		q.mu.Lock()
+		if len(q.items) > 0 {
+			data = q.items[0]
+			q.items[0] = nil
+			q.items = q.items[1:]
+			q.size -= len(data)
+			q.wakeSpace()
+			q.mu.Unlock()
+			return data, true
+		}
+		closed := q.closed
+		q.mu.Unlock()
+
+		if closed {
+			return nil, false
+		}
+
+		select {
+		case <-q.notify:
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+func (q *ByteQueue) wake() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// (internal) q.mu must be locked
+func (q *ByteQueue) wakeSpace() {
+	if q.space != nil {
+		close(q.space)
+		q.space = nil
+	}
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+// default window size of a flow-controlled channel, for each direction
+const DefaultWindowSize = 256 * 1024
+
+// SendWindow limits bytes in flight on one direction of a channel.
+// The peer grants credits back (0x25 package) after consuming data.
+//
+// A nil *SendWindow means no flow control.
+type SendWindow struct {
+	mu      sync.Mutex
+	avail   int64
+	changed chan struct{} // closed and replaced when avail increases
+}
+
+func NewSendWindow(size uint32) *SendWindow {
+	return &SendWindow{avail: int64(size), changed: make(chan struct{})}
+}
+
+// wait until window is open, then take n bytes from it.
+//
+// a chunk larger than the available window is allowed, so the window may become negative.
+// returns false if ctx is done.
+func (w *SendWindow) Acquire(ctx context.Context, n int) bool {
+	if w == nil {
+		return ctx.Err() == nil
+	}
+	for {
+		w.mu.Lock()
+		if w.avail > 0 {
+			w.avail -= int64(n)
+			w.mu.Unlock()
+			return true
+		}
+		changed := w.changed
+		w.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// peer consumed n bytes
+func (w *SendWindow) Grant(n uint32) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.avail += int64(n)
+	close(w.changed)
+	w.changed = make(chan struct{})
+}
+
+// RecvWindow counts consumed bytes of one direction of a channel,
+// and decides when to grant credits back to the sender.
+//
+// A nil *RecvWindow means no flow control.
+type RecvWindow struct {
+	mu       sync.Mutex
+	size     uint32
+	consumed uint32
+}
+
+func NewRecvWindow(size uint32) *RecvWindow {
+	return &RecvWindow{size: size}
+}
+
+// n bytes were consumed. if the returned grant is not zero, send it to the sender.
+//
+// credits are batched, to avoid sending a window update for every tiny chunk.
+func (w *RecvWindow) Consumed(n int) (grant uint32) {
+	if w == nil {
+		return 0
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.consumed += uint32(n)
+	if w.consumed >= w.size/4 {
+		grant, w.consumed = w.consumed, 0
+	}
+	return
+}